@@ -277,4 +277,27 @@ window.AdminUtils = {
             });
         }, 3000);
     }
-};
\ No newline at end of file
+};
+
+// 按钮和表单通过 data-action、data-submit 调用页面中定义的函数，data-arg 为参数，
+// 代替 onclick、onsubmit 等内联事件属性（启用 CSP 后浏览器不执行内联事件）
+$(document).on('click', '[data-action]', function (e) {
+    e.preventDefault();
+    var fn = window[$(this).data('action')];
+    if (typeof fn === 'function') {
+        fn.call(this, $(this).data('arg'));
+    }
+});
+
+$(document).on('submit', 'form[data-submit]', function (e) {
+    e.preventDefault();
+    var fn = window[$(this).data('submit')];
+    if (typeof fn === 'function') {
+        fn.call(this);
+    }
+});
+
+// 阻止 href="javascript:void(0);" 的默认跳转，启用 CSP 后这类地址会被拦截并产生违规报告
+$(document).on('click', 'a[href^="javascript:"]', function (e) {
+    e.preventDefault();
+});
\ No newline at end of file
@@ -0,0 +1,19 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// ArchiveIndex Markdown 导入导出页面
+func ArchiveIndex(c *gin.Context) {
+	c.HTML(http.StatusOK, "admin/archive.html", gin.H{
+		"user":     c.MustGet(common.ContextUserKey),
+		"comments": models.MustListUnreadComment(),
+		"cfg":      config.GetConfiguration(),
+	})
+}
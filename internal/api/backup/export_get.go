@@ -0,0 +1,22 @@
+package backup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+)
+
+// ExportGet 下载全站 Markdown 归档
+func ExportGet(c *gin.Context) {
+	log.Debug("ExportGet")
+	fileName := fmt.Sprintf("Bmtdblog_markdown_%s.zip", common.GetCurrentTime().Format("20060102150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	if err := ExportMarkdown(c.Writer); err != nil {
+		log.Error("ExportMarkdown error", "err", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
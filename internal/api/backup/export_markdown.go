@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// ExportMarkdown 将所有博文和页面导出为带 front matter 的 Markdown 文件并打包为 zip
+// 博文位于 posts/ 目录，页面位于 pages/ 目录，文件名为 slug
+func ExportMarkdown(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	posts, err := models.ListAllPost("")
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		meta := &markdownFrontMatter{
+			Title:     post.Title,
+			Slug:      post.Slug,
+			Type:      archiveTypePost,
+			Created:   timeValue(post.CreatedAt),
+			Updated:   timeValue(post.UpdatedAt),
+			Published: post.IsPublished,
+			Views:     post.View,
+		}
+		if tags, err := models.ListTagByPostId(post.ID); err == nil {
+			for _, tag := range tags {
+				meta.Tags = append(meta.Tags, tag.Name)
+			}
+		}
+		if err := writeMarkdownEntry(archive, "posts", post.ID, meta, post.Body); err != nil {
+			return err
+		}
+	}
+
+	pages, err := models.ListAllPage()
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		meta := &markdownFrontMatter{
+			Title:     page.Title,
+			Slug:      page.Slug,
+			Type:      archiveTypePage,
+			Created:   timeValue(page.CreatedAt),
+			Updated:   timeValue(page.UpdatedAt),
+			Published: page.IsPublished,
+			Views:     page.View,
+		}
+		if err := writeMarkdownEntry(archive, "pages", page.ID, meta, page.Body); err != nil {
+			return err
+		}
+	}
+
+	log.Info("markdown export finished", "posts", len(posts), "pages", len(pages))
+	return archive.Close()
+}
+
+// ExportMarkdownFile 导出 Markdown 归档到本地文件，供命令行使用
+func ExportMarkdownFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = ExportMarkdown(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeMarkdownEntry(archive *zip.Writer, dir string, id uint, meta *markdownFrontMatter, body string) error {
+	data, err := encodeMarkdown(meta, body)
+	if err != nil {
+		return err
+	}
+	name := meta.Slug
+	if name == "" {
+		name = fmt.Sprintf("%s-%d", meta.Type, id)
+	}
+	header := &zip.FileHeader{
+		Name:     fmt.Sprintf("%s/%s.md", dir, name),
+		Method:   zip.Deflate,
+		Modified: meta.Updated,
+	}
+	entry, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(data)
+	return err
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,194 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 单个 Markdown 文件的大小上限
+const maxMarkdownFileSize = 10 << 20
+
+// ImportResult 导入结果统计
+type ImportResult struct {
+	Posts   int      `json:"posts"`   // 新建的博文数
+	Pages   int      `json:"pages"`   // 新建的页面数
+	Skipped int      `json:"skipped"` // slug 已存在而跳过的数量
+	Errors  []string `json:"errors"`  // 解析或保存失败的文件
+}
+
+// ImportMarkdownPath 从 zip 文件或 Hexo/Hugo/Jekyll 的文章目录导入，供命令行使用
+func ImportMarkdownPath(filename string) (*ImportResult, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ImportMarkdownDir(filename)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ImportMarkdownZip(data)
+}
+
+// ImportMarkdownZip 从 zip 归档导入，归档内所有 .md/.markdown 文件都会被处理
+func ImportMarkdownZip(data []byte) (*ImportResult, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid zip file")
+	}
+	result := &ImportResult{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !isMarkdownFile(file.Name) {
+			continue
+		}
+		if file.UncompressedSize64 > maxMarkdownFileSize {
+			result.Errors = append(result.Errors, file.Name+": file too large")
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			result.Errors = append(result.Errors, file.Name+": "+err.Error())
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(reader, maxMarkdownFileSize))
+		reader.Close()
+		if err != nil {
+			result.Errors = append(result.Errors, file.Name+": "+err.Error())
+			continue
+		}
+		importMarkdownFile(result, file.Name, content)
+	}
+	logImportResult(result)
+	return result, nil
+}
+
+// ImportMarkdownDir 从本地目录导入，如 Hexo/Jekyll 的 _posts 或 Hugo 的 content/posts
+func ImportMarkdownDir(dir string) (*ImportResult, error) {
+	result := &ImportResult{}
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isMarkdownFile(name) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			result.Errors = append(result.Errors, rel+": "+err.Error())
+			return nil
+		}
+		importMarkdownFile(result, filepath.ToSlash(rel), content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logImportResult(result)
+	return result, nil
+}
+
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// importMarkdownFile 解析并保存单个文件，结果累计到 result
+func importMarkdownFile(result *ImportResult, name string, content []byte) {
+	doc, err := decodeMarkdown(name, content)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return
+	}
+	if doc.Type == archiveTypePage {
+		err = importPage(result, doc)
+	} else {
+		err = importPost(result, doc)
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, name+": "+err.Error())
+	}
+}
+
+func importPost(result *ImportResult, doc *markdownDocument) error {
+	// slug 已存在说明已导入过，跳过以便重复导入
+	if slug := models.Slugify(doc.Slug); slug != "" {
+		if _, err := models.GetPostIdBySlug(slug); err == nil {
+			result.Skipped++
+			return nil
+		}
+	}
+	post := &models.Post{
+		Title:       doc.Title,
+		Slug:        doc.Slug,
+		Body:        doc.Body,
+		View:        doc.Views,
+		IsPublished: doc.Published,
+		CreatedAt:   doc.Created,
+		UpdatedAt:   doc.Updated,
+	}
+	if err := post.Insert(); err != nil {
+		return err
+	}
+	tagIds := make([]uint, 0, len(doc.Tags))
+	for _, name := range doc.Tags {
+		tag := &models.Tag{Name: name}
+		if err := tag.Insert(); err != nil {
+			log.Warn("import tag failed", "tag", name, "err", err)
+			continue
+		}
+		tagIds = append(tagIds, tag.ID)
+	}
+	if len(tagIds) > 0 {
+		if err := models.SetPostTags(post.ID, tagIds); err != nil {
+			log.Warn("import post tags failed", "post_id", post.ID, "err", err)
+		}
+	}
+	result.Posts++
+	return nil
+}
+
+func importPage(result *ImportResult, doc *markdownDocument) error {
+	if slug := models.Slugify(doc.Slug); slug != "" {
+		if _, err := models.GetPageIdBySlug(slug); err == nil {
+			result.Skipped++
+			return nil
+		}
+	}
+	page := &models.Page{
+		Title:       doc.Title,
+		Slug:        doc.Slug,
+		Body:        doc.Body,
+		View:        doc.Views,
+		IsPublished: doc.Published,
+		CreatedAt:   doc.Created,
+		UpdatedAt:   doc.Updated,
+	}
+	if err := page.Insert(); err != nil {
+		return err
+	}
+	result.Pages++
+	return nil
+}
+
+func logImportResult(result *ImportResult) {
+	log.Info("markdown import finished", "posts", result.Posts, "pages", result.Pages, "skipped", result.Skipped, "errors", len(result.Errors))
+	for _, message := range result.Errors {
+		log.Warn("markdown import error", "error", message)
+	}
+}
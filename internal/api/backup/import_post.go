@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"go.uber.org/zap"
+)
+
+// 上传归档的大小上限
+const maxImportArchiveSize = 100 << 20
+
+// ImportPost 上传 Markdown 归档（本站导出的 zip 或打包的 Hexo/Hugo/Jekyll 文章目录）并导入
+func ImportPost(c *gin.Context) {
+	var (
+		err error
+		res = gin.H{}
+	)
+	defer common.WriteJSON(c, res)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	defer file.Close()
+	log.Debug("ImportPost", zap.String("fileName", header.Filename), zap.Int64("size", header.Size))
+	if header.Size > maxImportArchiveSize {
+		res["message"] = "file too large."
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxImportArchiveSize))
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	result, err := ImportMarkdownZip(data)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["data"] = result
+	res["succeed"] = true
+}
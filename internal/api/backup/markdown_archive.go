@@ -0,0 +1,215 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"go.yaml.in/yaml/v3"
+)
+
+// 归档内容类型
+const (
+	archiveTypePost = "post"
+	archiveTypePage = "page"
+)
+
+// 支持的日期格式（Hexo/Hugo/Jekyll 常见写法）
+var frontMatterTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Jekyll 文件名格式：YYYY-MM-DD-slug.md
+var jekyllFileName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// markdownFrontMatter 导出时写入的 YAML front matter
+type markdownFrontMatter struct {
+	Title     string    `yaml:"title"`
+	Slug      string    `yaml:"slug,omitempty"`
+	Type      string    `yaml:"type"`
+	Tags      []string  `yaml:"tags,omitempty"`
+	Created   time.Time `yaml:"created"`
+	Updated   time.Time `yaml:"updated"`
+	Published bool      `yaml:"published"`
+	Views     int       `yaml:"views"`
+}
+
+// markdownDocument 解析后的 Markdown 文档
+type markdownDocument struct {
+	Type      string
+	Title     string
+	Slug      string
+	Tags      []string
+	Created   *time.Time
+	Updated   *time.Time
+	Published bool
+	Views     int
+	Body      string
+}
+
+// encodeMarkdown 生成带 YAML front matter 的 Markdown 内容
+func encodeMarkdown(meta *markdownFrontMatter, body string) ([]byte, error) {
+	header, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(header)
+	buf.WriteString("---\n\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeMarkdown 解析 Markdown 文件，兼容 YAML(---) 和 TOML(+++) 两种 front matter
+// name 为文件在归档或目录中的相对路径，用于推断 slug、日期和类型
+func decodeMarkdown(name string, data []byte) (*markdownDocument, error) {
+	content := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+	meta := make(map[string]interface{})
+	body := content
+
+	for _, delimiter := range []string{"---", "+++"} {
+		if !strings.HasPrefix(content, delimiter+"\n") {
+			continue
+		}
+		rest := content[len(delimiter)+1:]
+		end := strings.Index(rest, "\n"+delimiter)
+		if end < 0 {
+			return nil, errors.Errorf("%s: front matter not closed", name)
+		}
+		header := rest[:end]
+		body = strings.TrimLeft(rest[end+len(delimiter)+1:], "\n")
+		var err error
+		if delimiter == "---" {
+			err = yaml.Unmarshal([]byte(header), &meta)
+		} else {
+			err = toml.Unmarshal([]byte(header), &meta)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: invalid front matter", name)
+		}
+		break
+	}
+
+	doc := &markdownDocument{
+		Type:      archiveTypePost,
+		Body:      body,
+		Published: true,
+	}
+
+	// 从文件名推断 slug 和日期
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if base == "index" || base == "_index" {
+		// Hugo page bundle：目录名即 slug
+		base = path.Base(path.Dir(name))
+	}
+	if matches := jekyllFileName.FindStringSubmatch(base); matches != nil {
+		if t, err := parseFrontMatterTime(matches[1]); err == nil {
+			doc.Created = &t
+		}
+		base = matches[2]
+	}
+	doc.Slug = base
+	if strings.HasPrefix(name, "pages/") || strings.Contains(name, "/pages/") {
+		doc.Type = archiveTypePage
+	}
+
+	if value, ok := meta["type"].(string); ok && value == archiveTypePage {
+		doc.Type = archiveTypePage
+	}
+	if value, ok := meta["title"]; ok {
+		doc.Title = fmt.Sprint(value)
+	}
+	if value, ok := meta["slug"]; ok && fmt.Sprint(value) != "" {
+		doc.Slug = fmt.Sprint(value)
+	}
+	// 分类也作为标签导入
+	doc.Tags = append(frontMatterList(meta["tags"]), frontMatterList(meta["categories"])...)
+	for _, key := range []string{"created", "date"} {
+		if t, err := parseFrontMatterTime(meta[key]); err == nil {
+			doc.Created = &t
+			break
+		}
+	}
+	for _, key := range []string{"updated", "lastmod", "modified"} {
+		if t, err := parseFrontMatterTime(meta[key]); err == nil {
+			doc.Updated = &t
+			break
+		}
+	}
+	if value, ok := meta["published"].(bool); ok {
+		doc.Published = value
+	}
+	if value, ok := meta["draft"].(bool); ok && value {
+		doc.Published = false
+	}
+	switch value := meta["views"].(type) {
+	case int:
+		doc.Views = value
+	case int64:
+		doc.Views = int(value)
+	case float64:
+		doc.Views = int(value)
+	}
+
+	if doc.Title == "" {
+		doc.Title = base
+	}
+	return doc, nil
+}
+
+// frontMatterList 将列表或逗号/空格分隔的字符串转为字符串列表
+func frontMatterList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			// Hexo 的多级分类写成嵌套列表
+			items = append(items, frontMatterList(item)...)
+		}
+	case string:
+		separator := " "
+		if strings.Contains(v, ",") {
+			separator = ","
+		}
+		items = strings.Split(v, separator)
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseFrontMatterTime 解析 front matter 中的时间，无时区的时间按本地时区处理
+func parseFrontMatterTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, errors.New("empty time")
+	case time.Time:
+		return v, nil
+	}
+	text := strings.TrimSpace(fmt.Sprint(value))
+	for _, layout := range frontMatterTimeLayouts {
+		if t, err := time.ParseInLocation(layout, text, common.GetCurrentTime().Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("unsupported time format: %s", text)
+}
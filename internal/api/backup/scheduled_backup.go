@@ -0,0 +1,24 @@
+package backup
+
+import (
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
+)
+
+// ScheduledBackup 定时任务：备份数据库，未启用备份时跳过，失败时发送通知
+func ScheduledBackup() {
+	conf := config.GetConfiguration()
+	if !conf.Backup.Enabled || !conf.Qiniu.Enabled {
+		return
+	}
+	if err := Backup(); err != nil {
+		log.DaemonError("scheduler", "backup", "定时备份失败", "err", err)
+		notify.Notify(notify.EventBackupFailed, &notify.Message{
+			Title: "定时备份失败",
+			Text:  err.Error(),
+		})
+		return
+	}
+	log.DaemonInfo("scheduler", "backup", "定时备份完成")
+}
@@ -0,0 +1,48 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CommentView /api/v1 评论结构
+type CommentView struct {
+	ID        uint       `json:"id" example:"1"`
+	PostID    uint       `json:"post_id" example:"1"`
+	PostTitle string     `json:"post_title,omitempty"`
+	ParentID  uint       `json:"parent_id"`
+	UserID    uint       `json:"user_id"`
+	NickName  string     `json:"nick_name,omitempty"`
+	Content   string     `json:"content"`
+	Status    string     `json:"status" example:"approved"`
+	ReadState bool       `json:"read_state"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// CommentInput 管理员发表评论的请求体
+type CommentInput struct {
+	PostID   uint   `json:"post_id" binding:"required" example:"1"`
+	ParentID uint   `json:"parent_id" example:"0"` // 回复的评论ID，0 表示顶层评论
+	Content  string `json:"content" binding:"required" example:"thanks"`
+}
+
+// CommentStatusInput 修改评论审核状态的请求体
+type CommentStatusInput struct {
+	Status string `json:"status" binding:"required" enums:"pending,approved,spam,rejected" example:"approved"`
+}
+
+func newCommentView(comment *models.Comment) CommentView {
+	return CommentView{
+		ID:        comment.ID,
+		PostID:    comment.PostID,
+		PostTitle: comment.PostTitle,
+		ParentID:  comment.ParentID,
+		UserID:    comment.UserID,
+		NickName:  comment.NickName,
+		Content:   comment.Content,
+		Status:    comment.Status,
+		ReadState: comment.ReadState,
+		CreatedAt: comment.CreatedAt,
+	}
+}
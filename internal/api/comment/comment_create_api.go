@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// CommentCreateAPI 以当前管理员身份发表评论或回复，评论直接通过审核
+// @Summary 发表评论
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param comment body CommentInput true "评论"
+// @Success 201 {object} common.APIResponse{data=CommentView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/comments [post]
+func CommentCreateAPI(c *gin.Context) {
+	var input CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	post, err := models.GetPostById(input.PostID)
+	if err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	if input.ParentID > 0 {
+		parent, err := models.GetCommentById(input.ParentID)
+		if err != nil || parent.PostID != post.ID {
+			common.APIFail(c, http.StatusNotFound, common.APICodeNotFound, "reply target not found")
+			return
+		}
+	}
+	log.Debug("CommentCreateAPI", zap.Uint("userId", user.ID), zap.Uint("postId", post.ID), zap.Uint("parentId", input.ParentID))
+	comment := &models.Comment{
+		PostID:   post.ID,
+		ParentID: input.ParentID,
+		Content:  input.Content,
+		UserID:   user.ID,
+		Status:   models.CommentStatusApproved,
+	}
+	if err = comment.Insert(); err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	notifyReply(comment, post)
+	common.APICreated(c, newCommentView(comment))
+}
@@ -1,7 +1,6 @@
 package comment
 
 import (
-	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
@@ -17,8 +16,7 @@ func CommentDelete(c *gin.Context) {
 	)
 	defer common.WriteJSON(c, res)
 
-	s := sessions.Default(c)
-	userId := s.Get(common.SessionKey).(uint)
+	userId := c.MustGet(common.ContextUserKey).(*models.User).ID
 
 	cid, err = common.ParamUint(c, "id")
 	if err != nil {
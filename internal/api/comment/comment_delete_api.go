@@ -0,0 +1,37 @@
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// CommentDeleteAPI 删除评论，其回复挂到上一级评论下
+// @Summary 删除评论
+// @Tags comments
+// @Produce json
+// @Param id path int true "评论ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/comments/{id} [delete]
+func CommentDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	if _, err = models.GetCommentById(id); err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	log.Debug("CommentDeleteAPI", zap.Uint("id", id))
+	if err = models.DeleteComments([]uint{id}); err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	common.APIOK(c, nil)
+}
@@ -0,0 +1,31 @@
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CommentGetAPI 查询单条评论
+// @Summary 评论详情
+// @Tags comments
+// @Produce json
+// @Param id path int true "评论ID"
+// @Success 200 {object} common.APIResponse{data=CommentView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/comments/{id} [get]
+func CommentGetAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	comment, err := models.GetCommentById(id)
+	if err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	common.APIOK(c, newCommentView(comment))
+}
@@ -0,0 +1,29 @@
+package comment
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CommentIndex 评论审核管理页面
+func CommentIndex(c *gin.Context) {
+	status := c.DefaultQuery("status", models.CommentStatusPending)
+	if status == "all" {
+		status = ""
+	} else if !models.IsValidCommentStatus(status) {
+		status = models.CommentStatusPending
+	}
+	list, _ := models.ListCommentByStatus(status)
+	c.HTML(http.StatusOK, "admin/comment.html", gin.H{
+		"list":     list,
+		"status":   status,
+		"counts":   models.CountCommentByStatus(),
+		"user":     c.MustGet(common.ContextUserKey),
+		"comments": models.MustListUnreadComment(),
+		"cfg":      config.GetConfiguration(),
+	})
+}
@@ -0,0 +1,49 @@
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CommentListAPI 分页查询评论
+// @Summary 评论列表
+// @Tags comments
+// @Produce json
+// @Param page query int false "页码，从1开始"
+// @Param page_size query int false "每页数量，最大100"
+// @Param status query string false "审核状态" Enums(pending, approved, spam, rejected)
+// @Param post_id query int false "博文ID"
+// @Success 200 {object} common.APIResponse{data=common.APIList{items=[]CommentView}}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/comments [get]
+func CommentListAPI(c *gin.Context) {
+	var (
+		postID uint
+		err    error
+	)
+	status := c.Query("status")
+	if status != "" && !models.IsValidCommentStatus(status) {
+		common.APIBadRequest(c, "invalid status")
+		return
+	}
+	if value := c.Query("post_id"); value != "" {
+		if postID, err = common.ParseUint(value); err != nil {
+			common.APIBadRequest(c, "invalid post_id")
+			return
+		}
+	}
+	page, pageSize := common.APIPagination(c)
+	comments, total, err := models.ListCommentByFilter(status, postID, page, pageSize)
+	if err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	items := make([]CommentView, 0, len(comments))
+	for _, comment := range comments {
+		items = append(items, newCommentView(comment))
+	}
+	common.APIOK(c, common.APIList{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
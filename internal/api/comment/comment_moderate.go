@@ -0,0 +1,105 @@
+package comment
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// 批量操作中的删除动作
+const actionDelete = "delete"
+
+// CommentModerate 审核单条评论
+func CommentModerate(c *gin.Context) {
+	var (
+		id  uint
+		err error
+		res = gin.H{}
+	)
+	defer common.WriteJSON(c, res)
+	id, err = common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	action := c.PostForm("status")
+	log.Debug("CommentModerate", zap.Uint("id", id), zap.String("status", action))
+	if err = moderateComments([]uint{id}, action); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
+
+// CommentModerateBatch 批量审核评论，ids 为逗号分隔的评论ID
+func CommentModerateBatch(c *gin.Context) {
+	var (
+		err error
+		res = gin.H{}
+		ids []uint
+	)
+	defer common.WriteJSON(c, res)
+	action := c.PostForm("status")
+	for _, value := range strings.Split(c.PostForm("ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := common.ParseUint(value)
+		if err != nil {
+			res["message"] = err.Error()
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		res["message"] = "no comment selected."
+		return
+	}
+	log.Debug("CommentModerateBatch", zap.Any("ids", ids), zap.String("status", action))
+	if err = moderateComments(ids, action); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
+
+// moderateComments 修改评论审核状态或删除评论，新通过的回复会通知被回复者
+func moderateComments(ids []uint, action string) error {
+	if action == actionDelete {
+		return models.DeleteComments(ids)
+	}
+	if !models.IsValidCommentStatus(action) {
+		return errors.New("invalid status.")
+	}
+
+	// 记录之前未通过的评论，审核通过后发送回复通知
+	var newlyApproved []*models.Comment
+	if action == models.CommentStatusApproved {
+		for _, id := range ids {
+			comment, err := models.GetCommentById(id)
+			if err == nil && comment.Status != models.CommentStatusApproved {
+				newlyApproved = append(newlyApproved, comment)
+			}
+		}
+	}
+	if err := models.UpdateCommentStatus(ids, action); err != nil {
+		return err
+	}
+	// 审核结果是垃圾评论分类器的训练样本
+	if action == models.CommentStatusSpam || action == models.CommentStatusApproved {
+		RetrainSpamFilter()
+	}
+	for _, comment := range newlyApproved {
+		post, err := models.GetPostById(comment.PostID)
+		if err != nil {
+			continue
+		}
+		notifyReply(comment, post)
+	}
+	return nil
+}
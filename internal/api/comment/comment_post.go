@@ -10,6 +10,7 @@ import (
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
 	"go.uber.org/zap"
 )
 
@@ -22,9 +23,9 @@ func CommentPost(c *gin.Context) {
 	)
 	defer common.WriteJSON(c, res)
 	s := sessions.Default(c)
-	userId := s.Get(common.SessionKey).(uint)
+	userId := c.MustGet(common.ContextUserKey).(*models.User).ID
 	verifyCode := c.PostForm("verifyCode")
-	captchaId := s.Get(common.SessionCaptcha).(string)
+	captchaId, _ := s.Get(common.SessionCaptcha).(string)
 	s.Delete(common.SessionCaptcha)
 	if !captcha.VerifyString(captchaId, verifyCode) {
 		res["message"] = "error verifyCode"
@@ -36,27 +37,76 @@ func CommentPost(c *gin.Context) {
 		res["message"] = "content cannot be empty."
 		return
 	}
-	pid, err := common.ParamUint(c, "postId")
+	pid, err := common.PostFormUint(c, "postId")
 	if err != nil {
 		res["message"] = err.Error()
 		return
 	}
-	log.Debug("CommentPost", zap.Uint("userId", userId), zap.Uint("postId", pid), zap.String("content", content))
+	var parentId uint
+	if c.PostForm("parentId") != "" {
+		parentId, err = common.PostFormUint(c, "parentId")
+		if err != nil {
+			res["message"] = err.Error()
+			return
+		}
+	}
+	log.Debug("CommentPost", zap.Uint("userId", userId), zap.Uint("postId", pid), zap.Uint("parentId", parentId), zap.String("content", content))
 	post, err = models.GetPostByIdWithCache(pid)
 	if err != nil {
 		res["message"] = err.Error()
 		return
 	}
+	if parentId > 0 {
+		parent, err := models.GetCommentById(parentId)
+		if err != nil || parent.PostID != pid || parent.Status != models.CommentStatusApproved {
+			res["message"] = "reply target not found."
+			return
+		}
+	}
+
+	// 有评论管理权限的用户评论直接通过，其他评论由垃圾评论检查决定发布、审核或拒绝
+	status := models.CommentStatusApproved
+	if user, ok := c.MustGet(common.ContextUserKey).(*models.User); !ok || !user.Can(models.PermManageContent) {
+		verdict, _ := checkSpam(&SpamInput{
+			UserID:  userId,
+			IP:      c.ClientIP(),
+			PostID:  pid,
+			Content: content,
+		})
+		switch {
+		case verdict == SpamVerdictReject:
+			res["message"] = "comment rejected as spam."
+			return
+		case verdict == SpamVerdictQueue || cfg.Comment.Moderation:
+			status = models.CommentStatusPending
+		}
+	}
 	comment := &models.Comment{
-		PostID:  pid,
-		Content: content,
-		UserID:  userId,
+		PostID:   pid,
+		ParentID: parentId,
+		Content:  content,
+		UserID:   userId,
+		Status:   status,
 	}
 	err = comment.Insert()
 	if err != nil {
 		res["message"] = err.Error()
 		return
 	}
-	common.NotifyEmail(fmt.Sprintf("[%s]您有一条新评论", cfg.Title), fmt.Sprintf("<a href=\"%s/post/%d\" target=\"_blank\">%s</a>:%s", cfg.Domain, post.ID, post.Title, content))
+	if status == models.CommentStatusApproved {
+		notify.Notify(notify.EventComment, &notify.Message{
+			Title: "您有一条新评论",
+			Text:  fmt.Sprintf("%s:%s", post.Title, content),
+			Link:  cfg.Domain + post.URL(),
+		})
+		notifyReply(comment, post)
+	} else {
+		notify.Notify(notify.EventComment, &notify.Message{
+			Title: "您有一条新评论待审核",
+			Text:  fmt.Sprintf("%s:%s", post.Title, content),
+			Link:  fmt.Sprintf("%s/admin/comment?status=%s", cfg.Domain, models.CommentStatusPending),
+		})
+	}
+	res["status"] = status
 	res["succeed"] = true
 }
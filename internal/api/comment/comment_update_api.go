@@ -0,0 +1,53 @@
+package comment
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// CommentUpdateAPI 修改评论审核状态
+// @Summary 审核评论
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "评论ID"
+// @Param comment body CommentStatusInput true "审核状态"
+// @Success 200 {object} common.APIResponse{data=CommentView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/comments/{id} [put]
+func CommentUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input CommentStatusInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	if !models.IsValidCommentStatus(input.Status) {
+		common.APIBadRequest(c, "invalid status")
+		return
+	}
+	if _, err = models.GetCommentById(id); err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	log.Debug("CommentUpdateAPI", zap.Uint("id", id), zap.String("status", input.Status))
+	if err = moderateComments([]uint{id}, input.Status); err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	comment, err := models.GetCommentById(id)
+	if err != nil {
+		common.APIDBError(c, err, "comment")
+		return
+	}
+	common.APIOK(c, newCommentView(comment))
+}
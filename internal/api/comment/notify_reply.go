@@ -0,0 +1,49 @@
+package comment
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// notifyReply 回复通过审核后，通过邮件队列通知被回复的评论者
+func notifyReply(comment *models.Comment, post *models.Post) {
+	if comment.ParentID == 0 {
+		return
+	}
+	parent, err := models.GetCommentById(comment.ParentID)
+	if err != nil {
+		log.Warn("notifyReply: parent comment not found", "comment_id", comment.ID, "parent_id", comment.ParentID, "err", err)
+		return
+	}
+	// 自己回复自己不通知
+	if parent.UserID == comment.UserID {
+		return
+	}
+	parentUser, err := models.GetUser(parent.UserID)
+	// 未验证的邮箱不发送通知
+	if err != nil || !parentUser.EmailVerified() {
+		return
+	}
+	replier := "有人"
+	if user, err := models.GetUser(comment.UserID); err == nil {
+		if user.NickName != "" {
+			replier = user.NickName
+		} else if user.GithubLoginId != "" {
+			replier = user.GithubLoginId
+		}
+	}
+
+	cfg := config.GetConfiguration()
+	link := fmt.Sprintf("%s%s#comment-%d", cfg.Domain, post.URL(), comment.ID)
+	subject := fmt.Sprintf("[%s]%s回复了您的评论", cfg.Title, replier)
+	body := fmt.Sprintf("<p>%s 在 <a href=\"%s\" target=\"_blank\">%s</a> 中回复了您：</p><blockquote>%s</blockquote><p>您的评论：</p><blockquote>%s</blockquote>",
+		html.EscapeString(replier), link, html.EscapeString(post.Title), html.EscapeString(comment.Content), html.EscapeString(parent.Content))
+	if err := dao.PushEmailTask(parentUser.Email, subject, body); err != nil {
+		log.Error("notifyReply: push email task failed", "comment_id", comment.ID, "to", parentUser.Email, "err", err)
+	}
+}
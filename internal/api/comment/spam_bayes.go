@@ -0,0 +1,156 @@
+package comment
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+const (
+	// 每类最多使用的训练样本数
+	bayesTrainLimit = 2000
+	// 每类样本少于该数量时不参与判定
+	bayesMinSamples = 5
+)
+
+var defaultBayesChecker = &bayesChecker{}
+
+// bayesChecker 朴素贝叶斯分类器，使用管理员标记为垃圾/通过的评论训练
+type bayesChecker struct {
+	mu         sync.Mutex
+	trained    bool
+	spamDocs   int
+	hamDocs    int
+	spamTokens map[string]int
+	hamTokens  map[string]int
+	spamTotal  int
+	hamTotal   int
+	vocabulary int
+}
+
+// RetrainSpamFilter 管理员审核评论后标记分类器需要重新训练
+func RetrainSpamFilter() {
+	defaultBayesChecker.mu.Lock()
+	defaultBayesChecker.trained = false
+	defaultBayesChecker.mu.Unlock()
+}
+
+func (checker *bayesChecker) Name() string {
+	return "bayes"
+}
+
+func (checker *bayesChecker) Check(input *SpamInput) SpamResult {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	if !checker.trained {
+		if err := checker.train(); err != nil {
+			log.Warn("train spam filter failed", "err", err)
+			return SpamResult{}
+		}
+	}
+	if checker.spamDocs < bayesMinSamples || checker.hamDocs < bayesMinSamples {
+		return SpamResult{}
+	}
+
+	// 对数空间计算，拉普拉斯平滑
+	total := float64(checker.spamDocs + checker.hamDocs)
+	spamLog := math.Log(float64(checker.spamDocs) / total)
+	hamLog := math.Log(float64(checker.hamDocs) / total)
+	vocabulary := float64(checker.vocabulary)
+	for _, token := range tokenize(input.Content) {
+		spamLog += math.Log((float64(checker.spamTokens[token]) + 1) / (float64(checker.spamTotal) + vocabulary))
+		hamLog += math.Log((float64(checker.hamTokens[token]) + 1) / (float64(checker.hamTotal) + vocabulary))
+	}
+	probability := 1 / (1 + math.Exp(hamLog-spamLog))
+	if probability <= 0.5 {
+		return SpamResult{}
+	}
+	// 将 0.5~1 的概率映射为 0~1 的分数
+	return SpamResult{Score: (probability - 0.5) * 2, Reason: fmt.Sprintf("p=%.2f", probability)}
+}
+
+// train 从数据库加载样本重新训练，调用方需持有锁
+func (checker *bayesChecker) train() error {
+	spam, err := models.ListCommentContentByStatus(models.CommentStatusSpam, bayesTrainLimit)
+	if err != nil {
+		return err
+	}
+	ham, err := models.ListCommentContentByStatus(models.CommentStatusApproved, bayesTrainLimit)
+	if err != nil {
+		return err
+	}
+
+	checker.spamTokens, checker.spamTotal = countTokens(spam)
+	checker.hamTokens, checker.hamTotal = countTokens(ham)
+	checker.spamDocs = len(spam)
+	checker.hamDocs = len(ham)
+
+	vocabulary := make(map[string]struct{}, len(checker.spamTokens)+len(checker.hamTokens))
+	for token := range checker.spamTokens {
+		vocabulary[token] = struct{}{}
+	}
+	for token := range checker.hamTokens {
+		vocabulary[token] = struct{}{}
+	}
+	checker.vocabulary = len(vocabulary)
+	checker.trained = true
+	log.Debug("spam filter trained", "spam", checker.spamDocs, "ham", checker.hamDocs, "vocabulary", checker.vocabulary)
+	return nil
+}
+
+func countTokens(docs []string) (map[string]int, int) {
+	counts := make(map[string]int)
+	total := 0
+	for _, doc := range docs {
+		for _, token := range tokenize(doc) {
+			counts[token]++
+			total++
+		}
+	}
+	return counts, total
+}
+
+// tokenize 分词：拉丁字母和数字按单词切分，汉字等 CJK 字符按相邻二元组切分
+func tokenize(text string) []string {
+	var (
+		tokens []string
+		word   strings.Builder
+		cjk    []rune
+	)
+	flushWord := func() {
+		if word.Len() > 1 {
+			tokens = append(tokens, word.String())
+		}
+		word.Reset()
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word.WriteRune(r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
@@ -0,0 +1,103 @@
+package comment
+
+import (
+	"sync"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"go.uber.org/zap"
+)
+
+// 垃圾评论判定结果
+const (
+	SpamVerdictPublish = "publish" // 直接发布
+	SpamVerdictQueue   = "queue"   // 进入人工审核队列
+	SpamVerdictReject  = "reject"  // 直接拒绝
+)
+
+// 默认阈值，配置未设置时使用
+const (
+	defaultQueueThreshold  = 0.5
+	defaultRejectThreshold = 1.0
+)
+
+// SpamInput 待检查的评论信息
+type SpamInput struct {
+	UserID  uint
+	IP      string
+	PostID  uint
+	Content string
+}
+
+// SpamResult 单个检查器的结果，Score 越高越可能是垃圾评论，0 表示未发现问题
+type SpamResult struct {
+	Score  float64
+	Reason string
+}
+
+// SpamChecker 垃圾评论检查器
+type SpamChecker interface {
+	Name() string
+	Check(input *SpamInput) SpamResult
+}
+
+var (
+	spamCheckers   []SpamChecker
+	spamCheckersMu sync.RWMutex
+)
+
+func init() {
+	RegisterSpamChecker(&heuristicChecker{})
+	RegisterSpamChecker(&rateLimitChecker{})
+	RegisterSpamChecker(defaultBayesChecker)
+}
+
+// RegisterSpamChecker 注册垃圾评论检查器
+func RegisterSpamChecker(checker SpamChecker) {
+	spamCheckersMu.Lock()
+	defer spamCheckersMu.Unlock()
+	spamCheckers = append(spamCheckers, checker)
+}
+
+// checkSpam 依次运行所有检查器并汇总分数，根据阈值给出最终判定
+// 判定结果记录到日志，日志自动带上当前请求的 trace_id
+func checkSpam(input *SpamInput) (string, float64) {
+	spamCheckersMu.RLock()
+	checkers := spamCheckers
+	spamCheckersMu.RUnlock()
+
+	total := 0.0
+	fields := []interface{}{
+		zap.Uint("userId", input.UserID),
+		zap.String("ip", input.IP),
+		zap.Uint("postId", input.PostID),
+	}
+	for _, checker := range checkers {
+		result := checker.Check(input)
+		total += result.Score
+		if result.Score > 0 {
+			fields = append(fields, zap.String(checker.Name(), result.Reason))
+		}
+	}
+
+	cfg := config.GetConfiguration().Comment
+	queueThreshold := cfg.QueueThreshold
+	if queueThreshold <= 0 {
+		queueThreshold = defaultQueueThreshold
+	}
+	rejectThreshold := cfg.RejectThreshold
+	if rejectThreshold <= 0 {
+		rejectThreshold = defaultRejectThreshold
+	}
+
+	verdict := SpamVerdictPublish
+	switch {
+	case total >= rejectThreshold:
+		verdict = SpamVerdictReject
+	case total >= queueThreshold:
+		verdict = SpamVerdictQueue
+	}
+	fields = append(fields, zap.Float64("score", total), zap.String("verdict", verdict))
+	log.Info("comment spam check", fields...)
+	return verdict, total
+}
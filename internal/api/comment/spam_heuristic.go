@@ -0,0 +1,49 @@
+package comment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// 默认允许的链接数
+const defaultMaxLinks = 2
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)`)
+
+// heuristicChecker 基于链接数量和屏蔽关键词的规则检查
+type heuristicChecker struct{}
+
+func (checker *heuristicChecker) Name() string {
+	return "heuristic"
+}
+
+func (checker *heuristicChecker) Check(input *SpamInput) SpamResult {
+	cfg := config.GetConfiguration().Comment
+	maxLinks := cfg.MaxLinks
+	if maxLinks <= 0 {
+		maxLinks = defaultMaxLinks
+	}
+
+	var (
+		score   float64
+		reasons []string
+	)
+	// 超出部分每个链接 0.3 分
+	if links := len(linkPattern.FindAllString(input.Content, -1)); links > maxLinks {
+		score += 0.3 * float64(links-maxLinks)
+		reasons = append(reasons, fmt.Sprintf("%d links", links))
+	}
+	// 命中一个屏蔽关键词 0.6 分
+	content := strings.ToLower(input.Content)
+	for _, keyword := range cfg.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && strings.Contains(content, keyword) {
+			score += 0.6
+			reasons = append(reasons, "keyword "+keyword)
+		}
+	}
+	return SpamResult{Score: score, Reason: strings.Join(reasons, ",")}
+}
@@ -0,0 +1,58 @@
+package comment
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// 默认限流：5 分钟内最多 5 条评论
+const (
+	defaultRateLimit  = 5
+	defaultRateWindow = 300
+
+	commentRateUserPrefix = "comment_rate_user"
+	commentRateIPPrefix   = "comment_rate_ip"
+)
+
+// rateLimitChecker 基于 Redis 的单用户/单IP评论频率限制，超出限制直接判为垃圾
+type rateLimitChecker struct{}
+
+func (checker *rateLimitChecker) Name() string {
+	return "rate_limit"
+}
+
+func (checker *rateLimitChecker) Check(input *SpamInput) SpamResult {
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() {
+		return SpamResult{}
+	}
+
+	cfg := config.GetConfiguration().Comment
+	limit := cfg.RateLimit
+	if limit <= 0 {
+		limit = defaultRateLimit
+	}
+	window := cfg.RateWindow
+	if window <= 0 {
+		window = defaultRateWindow
+	}
+	expiration := time.Duration(window) * time.Second
+
+	keys := []string{dao.GenerateKey(commentRateUserPrefix, input.UserID)}
+	if input.IP != "" {
+		keys = append(keys, dao.GenerateKey(commentRateIPPrefix, input.IP))
+	}
+	for _, key := range keys {
+		count, err := Redis.Incr(key, expiration)
+		if err != nil {
+			continue
+		}
+		if count > int64(limit) {
+			return SpamResult{Score: 1, Reason: fmt.Sprintf("%s exceeded %d/%ds", key, limit, window)}
+		}
+	}
+	return SpamResult{}
+}
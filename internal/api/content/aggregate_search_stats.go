@@ -0,0 +1,45 @@
+package content
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 搜索明细默认保留天数
+const defaultSearchLogRetention = 90
+
+// AggregateSearchStats 定时任务：汇总昨天和今天的搜索记录，并清理过期的搜索明细
+func AggregateSearchStats() {
+	cfg := config.GetConfiguration().Search
+	if !cfg.LogEnabled {
+		return
+	}
+	now := time.Now()
+	// 昨天的记录在零点后再汇总一次，避免漏掉最后一小时
+	for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+		count, err := models.AggregateSearchStats(day)
+		if err != nil {
+			log.DaemonError("scheduler", "search_stats", "汇总搜索记录失败", "day", day.Format("2006-01-02"), "err", err)
+			continue
+		}
+		if count > 0 {
+			log.DaemonInfo("scheduler", "search_stats", "搜索记录已汇总", "day", day.Format("2006-01-02"), "queries", count)
+		}
+	}
+
+	days := cfg.LogRetention
+	if days <= 0 {
+		days = defaultSearchLogRetention
+	}
+	// 至少保留两天，保证汇总时明细仍在
+	days = max(days, 2)
+	deleted, err := models.DeleteSearchLogsBefore(now.AddDate(0, 0, -days))
+	if err != nil {
+		log.DaemonError("scheduler", "search_stats", "清理搜索记录失败", "err", err)
+	} else if deleted > 0 {
+		log.DaemonInfo("scheduler", "search_stats", "已清理过期搜索记录", "count", deleted, "retention_days", days)
+	}
+}
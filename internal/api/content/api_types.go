@@ -0,0 +1,194 @@
+package content
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PostView /api/v1 博文结构
+type PostView struct {
+	ID           uint       `json:"id" example:"1"`
+	Title        string     `json:"title" example:"Hello"`
+	Slug         string     `json:"slug" example:"hello"`
+	URL          string     `json:"url" example:"/posts/hello"`
+	Body         string     `json:"body,omitempty"`
+	View         int        `json:"view"`
+	IsPublished  bool       `json:"is_published"`
+	Pending      bool       `json:"pending"` // 已提交审核，等待编辑发布
+	PublishAt    *time.Time `json:"publish_at"`
+	AuthorID     uint       `json:"author_id"`
+	CreatedAt    *time.Time `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+	Tags         []TagView  `json:"tags"`
+	CommentTotal int        `json:"comment_total"`
+}
+
+// PostInput 创建或修改博文的请求体，修改时未提供的字段保持不变
+type PostInput struct {
+	Title       *string  `json:"title" example:"Hello"`
+	Slug        *string  `json:"slug" example:"hello"`
+	Body        *string  `json:"body" example:"# Hello"`
+	IsPublished *bool    `json:"is_published" example:"true"`     // 没有发布权限时表示提交审核
+	PublishAt   NullTime `json:"publish_at" swaggertype:"string"` // 定时发布时间，晚于当前时间时博文保持未发布，传 null 取消定时发布
+	TagIDs      *[]uint  `json:"tag_ids"`                         // 标签ID列表，提供时整体替换
+}
+
+// NullTime 区分请求中未提供的时间和显式传入的 null，修改时 null 表示清空
+type NullTime struct {
+	Set  bool       // 请求中是否提供了该字段
+	Time *time.Time // 为 nil 表示传入了 null
+}
+
+func (t *NullTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = nil
+		return nil
+	}
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	t.Time = &value
+	return nil
+}
+
+// PageView /api/v1 页面结构
+type PageView struct {
+	ID          uint       `json:"id" example:"1"`
+	Title       string     `json:"title" example:"About"`
+	Slug        string     `json:"slug" example:"about"`
+	URL         string     `json:"url" example:"/pages/about"`
+	Body        string     `json:"body,omitempty"`
+	View        int        `json:"view"`
+	IsPublished bool       `json:"is_published"`
+	PublishAt   *time.Time `json:"publish_at"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+// PageInput 创建或修改页面的请求体，修改时未提供的字段保持不变
+type PageInput struct {
+	Title       *string  `json:"title" example:"About"`
+	Slug        *string  `json:"slug" example:"about"`
+	Body        *string  `json:"body" example:"about me"`
+	IsPublished *bool    `json:"is_published" example:"true"`
+	PublishAt   NullTime `json:"publish_at" swaggertype:"string"` // 传 null 取消定时发布
+}
+
+// TagView /api/v1 标签结构
+type TagView struct {
+	ID        uint       `json:"id" example:"1"`
+	Name      string     `json:"name" example:"golang"`
+	Total     int        `json:"total,omitempty"` // 关联的博文数
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// TagInput 创建或修改标签的请求体
+type TagInput struct {
+	Name string `json:"name" binding:"required" example:"golang"`
+}
+
+func newPostView(post *models.Post, withBody bool) PostView {
+	view := PostView{
+		ID:           post.ID,
+		Title:        post.Title,
+		Slug:         post.Slug,
+		URL:          post.URL(),
+		View:         post.View,
+		IsPublished:  post.IsPublished,
+		Pending:      post.Pending,
+		PublishAt:    post.PublishAt,
+		AuthorID:     post.AuthorID,
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+		Tags:         make([]TagView, 0, len(post.Tags)),
+		CommentTotal: post.CommentTotal,
+	}
+	if withBody {
+		view.Body = post.Body
+	}
+	for _, tag := range post.Tags {
+		view.Tags = append(view.Tags, newTagView(tag))
+	}
+	return view
+}
+
+func (input *PostInput) apply(post *models.Post) {
+	if input.Title != nil {
+		post.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Slug != nil {
+		post.Slug = *input.Slug
+	}
+	if input.Body != nil {
+		post.Body = *input.Body
+	}
+	if input.IsPublished != nil {
+		post.IsPublished = *input.IsPublished
+	}
+	if input.PublishAt.Set {
+		post.PublishAt = input.PublishAt.Time
+	}
+	post.IsPublished, post.PublishAt = resolvePublishAt(post.IsPublished, post.PublishAt)
+}
+
+func newPageView(page *models.Page, withBody bool) PageView {
+	view := PageView{
+		ID:          page.ID,
+		Title:       page.Title,
+		Slug:        page.Slug,
+		URL:         page.URL(),
+		View:        page.View,
+		IsPublished: page.IsPublished,
+		PublishAt:   page.PublishAt,
+		CreatedAt:   page.CreatedAt,
+		UpdatedAt:   page.UpdatedAt,
+	}
+	if withBody {
+		view.Body = page.Body
+	}
+	return view
+}
+
+func (input *PageInput) apply(page *models.Page) {
+	if input.Title != nil {
+		page.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Slug != nil {
+		page.Slug = *input.Slug
+	}
+	if input.Body != nil {
+		page.Body = *input.Body
+	}
+	if input.IsPublished != nil {
+		page.IsPublished = *input.IsPublished
+	}
+	if input.PublishAt.Set {
+		page.PublishAt = input.PublishAt.Time
+	}
+	page.IsPublished, page.PublishAt = resolvePublishAt(page.IsPublished, page.PublishAt)
+}
+
+func newTagView(tag *models.Tag) TagView {
+	return TagView{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Total:     tag.Total,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+// joinIDs 将ID列表转换为 setPostTags 使用的逗号分隔格式
+func joinIDs(ids []uint) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(values, ",")
+}
@@ -1,13 +1,10 @@
 package content
 
 import (
-	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/microcosm-cc/bluemonday"
-	"github.com/russross/blackfriday"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
@@ -25,7 +22,6 @@ func ArchiveGet(c *gin.Context) {
 		total     int
 		err       error
 		posts     []*models.Post
-		policy    *bluemonday.Policy
 	)
 	year = c.Param("year")
 	month = c.Param("month")
@@ -47,24 +43,9 @@ func ArchiveGet(c *gin.Context) {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
-	policy = bluemonday.StrictPolicy()
-	for _, post := range posts {
-		post.Tags, _ = models.ListTagByPostId(post.ID)
-		post.Body = policy.Sanitize(string(blackfriday.MarkdownCommon([]byte(post.Body))))
-		post.CommentTotal = models.CountCommentByPostID(post.ID)
-	}
 	user, _ := c.Get(common.ContextUserKey)
-	c.HTML(http.StatusOK, "index/index.html", gin.H{
-		"posts":           posts,
-		"tags":            models.MustListTag(),
-		"archives":        models.MustListPostArchives(),
-		"links":           models.MustListLinks(),
-		"pageIndex":       pageIndex,
-		"totalPage":       int(math.Ceil(float64(total) / float64(pageSize))),
-		"maxReadPosts":    models.MustListMaxReadPost(),
-		"maxCommentPosts": models.MustListMaxCommentPost(),
-		"user":            user,
-		"cfg":             config.GetConfiguration(),
-	})
+	data := ListPageData(posts, pageIndex, total)
+	data["user"] = user
+	c.HTML(http.StatusOK, "index/index.html", data)
 
 }
@@ -0,0 +1,23 @@
+package content
+
+import (
+	"errors"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CheckSearchConsistency 定时任务：检查搜索索引与数据库是否一致，并为差异创建同步任务
+func CheckSearchConsistency() {
+	report, err := models.CheckSearchConsistency(true)
+	if errors.Is(err, models.ErrSearchIndexUnsupported) {
+		return
+	}
+	if err != nil {
+		log.DaemonError("scheduler", "search_consistency", "搜索索引一致性检查失败", "err", err)
+		return
+	}
+	log.DaemonInfo("scheduler", "search_consistency", "搜索索引一致性检查完成", "engine", report.Engine,
+		"posts", report.Posts, "documents", report.Documents, "missing", len(report.Missing),
+		"stale", len(report.Stale), "extra", len(report.Extra), "took", report.Took)
+}
@@ -1,13 +1,10 @@
 package content
 
 import (
-	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/microcosm-cc/bluemonday"
-	"github.com/russross/blackfriday"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
@@ -22,7 +19,6 @@ func IndexGet(c *gin.Context) {
 		page      string
 		err       error
 		posts     []*models.Post
-		policy    *bluemonday.Policy
 	)
 	page = c.Query("page")
 	pageIndex, _ = strconv.Atoi(page)
@@ -41,24 +37,9 @@ func IndexGet(c *gin.Context) {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
-	policy = bluemonday.StrictPolicy()
-	for _, post := range posts {
-		post.Tags, _ = models.ListTagByPostId(post.ID)
-		post.Body = policy.Sanitize(string(blackfriday.MarkdownCommon([]byte(post.Body))))
-		post.CommentTotal = models.CountCommentByPostID(post.ID)
-	}
 	user, _ := c.Get(common.ContextUserKey)
-	c.HTML(http.StatusOK, "index/index.html", gin.H{
-		"posts":           posts,
-		"tags":            models.MustListTag(),
-		"archives":        models.MustListPostArchives(),
-		"links":           models.MustListLinks(),
-		"user":            user,
-		"pageIndex":       pageIndex,
-		"totalPage":       int(math.Ceil(float64(total) / float64(pageSize))),
-		"path":            c.Request.URL.Path,
-		"maxReadPosts":    models.MustListMaxReadPost(),
-		"maxCommentPosts": models.MustListMaxCommentPost(),
-		"cfg":             config.GetConfiguration(),
-	})
+	data := ListPageData(posts, pageIndex, total)
+	data["user"] = user
+	data["path"] = c.Request.URL.Path
+	c.HTML(http.StatusOK, "index/index.html", data)
 }
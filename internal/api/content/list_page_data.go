@@ -0,0 +1,33 @@
+package content
+
+import (
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/russross/blackfriday"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// ListPageData 首页、标签页和归档页共用的模板数据，静态站点生成时复用
+func ListPageData(posts []*models.Post, pageIndex, total int) gin.H {
+	pageSize := config.GetConfiguration().PageSize
+	policy := bluemonday.StrictPolicy()
+	for _, post := range posts {
+		post.Tags, _ = models.ListTagByPostId(post.ID)
+		post.Body = policy.Sanitize(string(blackfriday.MarkdownCommon([]byte(post.Body))))
+		post.CommentTotal = models.CountCommentByPostID(post.ID)
+	}
+	return gin.H{
+		"posts":           posts,
+		"tags":            models.MustListTag(),
+		"archives":        models.MustListPostArchives(),
+		"links":           models.MustListLinks(),
+		"pageIndex":       pageIndex,
+		"totalPage":       int(math.Ceil(float64(total) / float64(pageSize))),
+		"maxReadPosts":    models.MustListMaxReadPost(),
+		"maxCommentPosts": models.MustListMaxCommentPost(),
+		"cfg":             config.GetConfiguration(),
+	}
+}
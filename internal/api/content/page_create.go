@@ -16,14 +16,19 @@ func PageCreate(c *gin.Context) {
 	body := c.PostForm("body")
 	isPublished := c.PostForm("isPublished")
 	published := isPublished == "on"
+	published, publishAt, err := parsePublishAt(c, published)
 	log.Debug("PageCreate", zap.String("title", title), zap.Bool("isPublished", published))
 
 	page := &models.Page{
 		Title:       title,
 		Body:        body,
 		IsPublished: published,
+		Slug:        c.PostForm("slug"),
+		PublishAt:   publishAt,
+	}
+	if err == nil {
+		err = page.Insert()
 	}
-	err := page.Insert()
 	if err != nil {
 		c.HTML(http.StatusOK, "page/new.html", gin.H{
 			"message": err.Error(),
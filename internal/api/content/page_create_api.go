@@ -0,0 +1,40 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PageCreateAPI 创建页面
+// @Summary 创建页面
+// @Tags pages
+// @Accept json
+// @Produce json
+// @Param page body PageInput true "页面内容，title 必填"
+// @Success 201 {object} common.APIResponse{data=PageView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/pages [post]
+func PageCreateAPI(c *gin.Context) {
+	var input PageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	page := &models.Page{}
+	input.apply(page)
+	if page.Title == "" {
+		common.APIBadRequest(c, "title is required")
+		return
+	}
+	log.Debug("PageCreateAPI", zap.String("title", page.Title), zap.Bool("isPublished", page.IsPublished))
+	if err := page.Insert(); err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	common.APICreated(c, newPageView(page, true))
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
-	"github.com/xiuivfbc/bmtdblog/internal/models"
 	"go.uber.org/zap"
 )
 
@@ -20,10 +19,7 @@ func PageDelete(c *gin.Context) {
 		return
 	}
 	log.Debug("PageDelete", zap.Uint("id", id))
-	page := &models.Page{}
-	page.ID = id
-	err = page.Delete()
-	if err != nil {
+	if err = removePage(id); err != nil {
 		res["message"] = err.Error()
 		return
 	}
@@ -0,0 +1,37 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PageDeleteAPI 删除页面
+// @Summary 删除页面
+// @Tags pages
+// @Produce json
+// @Param id path int true "页面ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/pages/{id} [delete]
+func PageDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	if _, err = models.GetPageById(id); err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	log.Debug("PageDeleteAPI", zap.Uint("id", id))
+	if err = removePage(id); err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	common.APIOK(c, nil)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
+// PageGet 旧的数字ID地址，存在 slug 时 301 跳转到 slug 地址
 func PageGet(c *gin.Context) {
 	id, err := common.ParamUint(c, "id")
 	if err != nil {
@@ -20,6 +21,15 @@ func PageGet(c *gin.Context) {
 		common.Handle404(c)
 		return
 	}
+	if page.Slug != "" {
+		c.Redirect(http.StatusMovedPermanently, page.URL())
+		return
+	}
+	showPage(c, page)
+}
+
+// showPage 渲染单页
+func showPage(c *gin.Context, page *models.Page) {
 	page.View++
 	page.UpdateView()
 	user, _ := c.Get(common.ContextUserKey)
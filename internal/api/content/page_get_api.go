@@ -0,0 +1,31 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PageGetAPI 查询单个页面
+// @Summary 页面详情
+// @Tags pages
+// @Produce json
+// @Param id path int true "页面ID"
+// @Success 200 {object} common.APIResponse{data=PageView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/pages/{id} [get]
+func PageGetAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	page, err := models.GetPageById(id)
+	if err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	common.APIOK(c, newPageView(page, true))
+}
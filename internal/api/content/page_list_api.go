@@ -0,0 +1,38 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PageListAPI 分页查询页面
+// @Summary 页面列表
+// @Tags pages
+// @Produce json
+// @Param page query int false "页码，从1开始"
+// @Param page_size query int false "每页数量，最大100"
+// @Param published query bool false "发布状态"
+// @Success 200 {object} common.APIResponse{data=common.APIList{items=[]PageView}}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/pages [get]
+func PageListAPI(c *gin.Context) {
+	published, err := common.QueryBool(c, "published")
+	if err != nil {
+		common.APIBadRequest(c, "invalid published")
+		return
+	}
+	page, pageSize := common.APIPagination(c)
+	pages, total, err := models.ListPageByFilter(published, page, pageSize)
+	if err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	items := make([]PageView, 0, len(pages))
+	for _, p := range pages {
+		items = append(items, newPageView(p, false))
+	}
+	common.APIOK(c, common.APIList{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
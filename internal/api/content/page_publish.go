@@ -23,6 +23,8 @@ func PagePublish(c *gin.Context) {
 		return
 	}
 	page.IsPublished = !page.IsPublished
+	// 手动切换发布状态后取消定时发布
+	page.PublishAt = nil
 	err = page.Update()
 	if err != nil {
 		res["message"] = err.Error()
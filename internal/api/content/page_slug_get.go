@@ -0,0 +1,33 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PageSlugGet 通过 slug 访问页面，旧 slug 301 跳转到当前地址
+func PageSlugGet(c *gin.Context) {
+	slug := c.Param("slug")
+	id, err := models.GetPageIdBySlug(slug)
+	redirect := false
+	if err != nil {
+		if id, err = models.FindSlugRedirect(models.SlugKindPage, slug); err != nil {
+			common.Handle404(c)
+			return
+		}
+		redirect = true
+	}
+	page, err := models.GetPageById(id)
+	if err != nil || !page.IsPublished {
+		common.Handle404(c)
+		return
+	}
+	if redirect && page.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, page.URL())
+		return
+	}
+	showPage(c, page)
+}
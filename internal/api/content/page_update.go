@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
 	"go.uber.org/zap"
 )
@@ -15,6 +16,7 @@ func PageUpdate(c *gin.Context) {
 	body := c.PostForm("body")
 	isPublished := c.PostForm("isPublished")
 	published := isPublished == "on"
+	published, publishAt, publishErr := parsePublishAt(c, published)
 
 	id, err := common.ParamUint(c, "id")
 	if err != nil {
@@ -22,8 +24,17 @@ func PageUpdate(c *gin.Context) {
 		return
 	}
 	log.Debug("PageUpdate", zap.Uint("id", id), zap.String("title", title), zap.Bool("isPublished", published))
-	page := &models.Page{Title: title, Body: body, IsPublished: published}
+	page := &models.Page{Title: title, Body: body, Slug: c.PostForm("slug"), IsPublished: published, PublishAt: publishAt}
 	page.ID = id
+	if publishErr != nil {
+		c.HTML(http.StatusOK, "page/modify.html", gin.H{
+			"message": publishErr.Error(),
+			"page":    page,
+			"user":    c.MustGet(common.ContextUserKey),
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
 	err = page.Update()
 	if err != nil {
 		log.Error("page.Update error", "err", err)
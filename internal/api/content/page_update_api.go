@@ -0,0 +1,50 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PageUpdateAPI 修改页面，只更新请求中提供的字段
+// @Summary 修改页面
+// @Tags pages
+// @Accept json
+// @Produce json
+// @Param id path int true "页面ID"
+// @Param page body PageInput true "需要修改的字段"
+// @Success 200 {object} common.APIResponse{data=PageView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/pages/{id} [put]
+func PageUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input PageInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	page, err := models.GetPageById(id)
+	if err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	input.apply(page)
+	if page.Title == "" {
+		common.APIBadRequest(c, "title is required")
+		return
+	}
+	log.Debug("PageUpdateAPI", zap.Uint("id", id), zap.String("title", page.Title), zap.Bool("isPublished", page.IsPublished))
+	if err = page.Update(); err != nil {
+		common.APIDBError(c, err, "page")
+		return
+	}
+	common.APIOK(c, newPageView(page, true))
+}
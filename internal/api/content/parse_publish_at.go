@@ -0,0 +1,40 @@
+package content
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+)
+
+// errInvalidPublishAt 表单中的定时发布时间无法解析
+var errInvalidPublishAt = errors.New("定时发布时间格式有误")
+
+// parsePublishAt 解析表单中的定时发布时间
+// 设置了未来的发布时间时，内容先保持未发布状态，由定时任务到点发布；
+// 时间格式有误时返回错误，不能按发布开关直接发布
+func parsePublishAt(c *gin.Context, published bool) (bool, *time.Time, error) {
+	value := c.PostForm("publishAt")
+	if value == "" {
+		return published, nil, nil
+	}
+	publishAt, err := common.ParseLocalTime(value)
+	if err != nil {
+		return published, nil, errInvalidPublishAt
+	}
+	published, scheduled := resolvePublishAt(published, &publishAt)
+	return published, scheduled, nil
+}
+
+// resolvePublishAt 根据发布开关和定时发布时间确定最终的发布状态
+func resolvePublishAt(published bool, publishAt *time.Time) (bool, *time.Time) {
+	if publishAt == nil {
+		return published, nil
+	}
+	if published && !publishAt.After(time.Now()) {
+		// 发布时间已过且勾选了发布，直接发布
+		return true, nil
+	}
+	return false, publishAt
+}
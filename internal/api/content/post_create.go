@@ -2,7 +2,6 @@ package content
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
@@ -18,14 +17,22 @@ func PostCreate(c *gin.Context) {
 	body := c.PostForm("body")
 	isPublished := c.PostForm("isPublished")
 	published := isPublished == "on"
+	published, publishAt, err := parsePublishAt(c, published)
 	log.Debug("PostCreate", zap.String("title", title), zap.String("tags", tags), zap.Bool("isPublished", published))
 
 	post := &models.Post{
 		Title:       title,
 		Body:        body,
 		IsPublished: published,
+		Slug:        c.PostForm("slug"),
+		PublishAt:   publishAt,
+	}
+	user := currentUser(c)
+	post.AuthorID = user.ID
+	resolvePostReview(user, post, false)
+	if err == nil {
+		err = post.Insert()
 	}
-	err := post.Insert()
 	if err != nil {
 		c.HTML(http.StatusOK, "post/new.html", gin.H{
 			"post":    post,
@@ -37,19 +44,7 @@ func PostCreate(c *gin.Context) {
 	}
 
 	// add tag for post
-	if len(tags) > 0 {
-		tagArr := strings.Split(tags, ",")
-		for _, tag := range tagArr {
-			tagId, err := common.ParseUint(tag)
-			if err != nil {
-				continue
-			}
-			pt := &models.PostTag{
-				PostId: post.ID,
-				TagId:  tagId,
-			}
-			pt.Insert()
-		}
-	}
+	setPostTags(post.ID, tags)
+	savePostRevision(c, post)
 	c.Redirect(http.StatusMovedPermanently, "/admin/post")
 }
@@ -0,0 +1,47 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PostCreateAPI 创建博文
+// @Summary 创建博文
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param post body PostInput true "博文内容，title 必填"
+// @Success 201 {object} common.APIResponse{data=PostView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/posts [post]
+func PostCreateAPI(c *gin.Context) {
+	var input PostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	user := currentUser(c)
+	post := &models.Post{AuthorID: user.ID}
+	input.apply(post)
+	if post.Title == "" {
+		common.APIBadRequest(c, "title is required")
+		return
+	}
+	resolvePostReview(user, post, false)
+	log.Debug("PostCreateAPI", zap.String("title", post.Title), zap.Bool("isPublished", post.IsPublished))
+	if err := post.Insert(); err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	if input.TagIDs != nil {
+		setPostTags(post.ID, joinIDs(*input.TagIDs))
+	}
+	savePostRevision(c, post)
+	models.LoadPostRelations(post)
+	common.APICreated(c, newPostView(post, true))
+}
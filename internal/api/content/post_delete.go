@@ -19,14 +19,19 @@ func PostDelete(c *gin.Context) {
 		res["message"] = err.Error()
 		return
 	}
-	log.Debug("PostDelete", zap.Uint("id", id))
-	post := &models.Post{}
-	post.ID = id
-	err = post.Delete()
+	post, err := models.GetPostById(id)
 	if err != nil {
 		res["message"] = err.Error()
 		return
 	}
-	models.DeletePostTagByPostId(id)
+	if !canEditPost(c, post) {
+		res["message"] = errPostForbidden
+		return
+	}
+	log.Debug("PostDelete", zap.Uint("id", id))
+	if err = removePost(id); err != nil {
+		res["message"] = err.Error()
+		return
+	}
 	res["succeed"] = true
 }
@@ -0,0 +1,45 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PostDeleteAPI 删除博文
+// @Summary 删除博文
+// @Tags posts
+// @Produce json
+// @Param id path int true "博文ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 403 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/posts/{id} [delete]
+func PostDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	post, err := models.GetPostById(id)
+	if err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	if !canEditPost(c, post) {
+		common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "not allowed to delete this post")
+		return
+	}
+	log.Debug("PostDeleteAPI", zap.Uint("id", id))
+	if err = removePost(id); err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	common.APIOK(c, nil)
+}
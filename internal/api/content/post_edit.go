@@ -20,6 +20,10 @@ func PostEdit(c *gin.Context) {
 		common.Handle404(c)
 		return
 	}
+	if !canEditPost(c, post) {
+		handlePostForbidden(c)
+		return
+	}
 	c.HTML(http.StatusOK, "post/modify.html", gin.H{
 		"post": post,
 		"user": c.MustGet(common.ContextUserKey),
@@ -2,13 +2,20 @@ package content
 
 import (
 	"net/http"
+	"slices"
 
+	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
+// 会话中保存的最近浏览博文数，新浏览的博文与其中每篇各计一次共同浏览
+const viewedPostsSize = 10
+
+// PostGet 旧的数字ID地址，存在 slug 时 301 跳转到 slug 地址
 func PostGet(c *gin.Context) {
 	id, err := common.ParamUint(c, "id")
 	if err != nil {
@@ -20,16 +27,51 @@ func PostGet(c *gin.Context) {
 		common.Handle404(c)
 		return
 	}
+	if post.Slug != "" {
+		c.Redirect(http.StatusMovedPermanently, post.URL())
+		return
+	}
+	showPost(c, post)
+}
+
+// showPost 渲染博文详情页
+func showPost(c *gin.Context, post *models.Post) {
 	// 更新浏览数（异步，避免影响缓存和性能）
 	go func() {
 		post.View++
 		post.UpdateView()
 	}()
 
+	recordCoView(c, post.ID)
+
 	user, _ := c.Get(common.ContextUserKey)
 	c.HTML(http.StatusOK, "post/display.html", gin.H{
-		"post": post,
-		"user": user,
-		"cfg":  config.GetConfiguration(),
+		"post":         post,
+		"user":         user,
+		"relatedPosts": models.MustListRelatedPosts(post),
+		"cfg":          config.GetConfiguration(),
 	})
 }
+
+// recordCoView 记录访客在本次会话中先后浏览的博文，作为相关博文的共同浏览信号，
+// 同一会话重复浏览同一篇博文不重复计数。只记录已有会话的访客，不为匿名访客创建会话
+func recordCoView(c *gin.Context, postID uint) {
+	if _, err := c.Cookie(common.SessionCookieName); err != nil {
+		return
+	}
+	session := sessions.Default(c)
+	viewed, _ := session.Get(common.SessionViewedPosts).([]uint)
+	if slices.Contains(viewed, postID) {
+		return
+	}
+	go models.RecordPostCoViews(postID, viewed)
+
+	viewed = append([]uint{postID}, viewed...)
+	if len(viewed) > viewedPostsSize {
+		viewed = viewed[:viewedPostsSize]
+	}
+	session.Set(common.SessionViewedPosts, viewed)
+	if err := session.Save(); err != nil {
+		log.Warn("保存浏览记录失败", "error", err)
+	}
+}
@@ -0,0 +1,40 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PostGetAPI 查询单篇博文
+// @Summary 博文详情
+// @Tags posts
+// @Produce json
+// @Param id path int true "博文ID"
+// @Success 200 {object} common.APIResponse{data=PostView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 403 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/posts/{id} [get]
+func PostGetAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	post, err := models.GetPostById(id)
+	if err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	// 他人未发布的博文只对有权修改的用户可见
+	if !post.IsPublished && !canEditPost(c, post) {
+		common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "not allowed to view this post")
+		return
+	}
+	models.LoadPostRelations(post)
+	common.APIOK(c, newPostView(post, true))
+}
@@ -10,7 +10,13 @@ import (
 )
 
 func PostIndex(c *gin.Context) {
-	posts, _ := models.ListAllPost("")
+	var posts []*models.Post
+	// 没有权限修改他人博文的角色只列出自己的博文
+	if user := currentUser(c); user.Can(models.PermEditOthersPost) {
+		posts, _ = models.ListAllPost("")
+	} else {
+		posts, _ = models.ListAllPostByAuthor(user.ID)
+	}
 	c.HTML(http.StatusOK, "admin/post.html", gin.H{
 		"posts":    posts,
 		"Active":   "posts",
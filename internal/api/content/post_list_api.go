@@ -0,0 +1,58 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PostListAPI 分页查询博文，作者和投稿者只能查询自己的博文
+// @Summary 博文列表
+// @Tags posts
+// @Produce json
+// @Param page query int false "页码，从1开始"
+// @Param page_size query int false "每页数量，最大100"
+// @Param tag query int false "标签ID"
+// @Param published query bool false "发布状态"
+// @Param q query string false "标题关键字"
+// @Success 200 {object} common.APIResponse{data=common.APIList{items=[]PostView}}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/posts [get]
+func PostListAPI(c *gin.Context) {
+	var (
+		filter models.PostFilter
+		err    error
+	)
+	if tag := c.Query("tag"); tag != "" {
+		if filter.TagID, err = common.ParseUint(tag); err != nil {
+			common.APIBadRequest(c, "invalid tag")
+			return
+		}
+	}
+	if filter.Published, err = common.QueryBool(c, "published"); err != nil {
+		common.APIBadRequest(c, "invalid published")
+		return
+	}
+	filter.Keyword = strings.TrimSpace(c.Query("q"))
+	// 没有权限修改他人博文的角色只能看到自己的博文
+	if user := currentUser(c); !user.Can(models.PermEditOthersPost) {
+		filter.AuthorID = user.ID
+	}
+	page, pageSize := common.APIPagination(c)
+	posts, total, err := models.ListPostByFilter(filter, page, pageSize)
+	if err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	items := make([]PostView, 0, len(posts))
+	for _, post := range posts {
+		post.Tags, _ = models.ListTagByPostId(post.ID)
+		post.CommentTotal = models.CountCommentByPostID(post.ID)
+		items = append(items, newPostView(post, false))
+	}
+	common.APIOK(c, common.APIList{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
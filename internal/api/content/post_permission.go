@@ -0,0 +1,50 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// errPostForbidden 无权操作博文时的提示
+const errPostForbidden = "没有权限操作该博文"
+
+// currentUser 当前登录用户，需在 AuthRequired 之后使用
+func currentUser(c *gin.Context) *models.User {
+	return c.MustGet(common.ContextUserKey).(*models.User)
+}
+
+// canEditPost 判断当前用户能否修改博文，无权限时记录日志
+func canEditPost(c *gin.Context, post *models.Post) bool {
+	user := currentUser(c)
+	if user.CanEditPost(post) {
+		return true
+	}
+	log.Warn("Post edit forbidden", "post_id", post.ID, "author_id", post.AuthorID, "user_id", user.ID, "role", user.Role)
+	return false
+}
+
+// handlePostForbidden 无权操作博文时返回 403 页面
+func handlePostForbidden(c *gin.Context) {
+	c.HTML(http.StatusForbidden, "errors/error.html", gin.H{
+		"message": errPostForbidden,
+		"user":    c.MustGet(common.ContextUserKey),
+		"cfg":     config.GetConfiguration(),
+	})
+}
+
+// resolvePostReview 按角色修正博文的发布状态，pending 为博文原有的待审核状态
+// 没有发布权限的用户请求发布或定时发布时，博文保持未发布并提交审核；
+// 有发布权限的用户发布后审核状态随之清除
+func resolvePostReview(user *models.User, post *models.Post, pending bool) {
+	if user.CanPublishPost(post) {
+		post.Pending = pending && !post.IsPublished
+		return
+	}
+	post.Pending = post.IsPublished || post.PublishAt != nil
+	post.IsPublished, post.PublishAt = false, nil
+}
@@ -23,7 +23,14 @@ func PostPublish(c *gin.Context) {
 		res["message"] = err.Error()
 		return
 	}
+	if !currentUser(c).CanPublishPost(post) {
+		res["message"] = errPostForbidden
+		return
+	}
 	post.IsPublished = !post.IsPublished
+	post.Pending = false
+	// 手动切换发布状态后取消定时发布
+	post.PublishAt = nil
 	err = post.Update()
 	if err != nil {
 		res["message"] = err.Error()
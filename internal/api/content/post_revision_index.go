@@ -0,0 +1,68 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PostRevisionIndex 博文修订历史，from/to参数指定需要对比的两个版本
+func PostRevisionIndex(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	post, err := models.GetPostById(id)
+	if err != nil {
+		common.Handle404(c)
+		return
+	}
+	if !canEditPost(c, post) {
+		handlePostForbidden(c)
+		return
+	}
+	revisions, _ := models.ListPostRevisions(id)
+
+	var (
+		from *models.PostRevision
+		to   *models.PostRevision
+	)
+	// 默认对比最近两个版本
+	if len(revisions) > 1 {
+		from, to = revisions[1], revisions[0]
+	}
+	if fromId, err := common.QueryUint(c, "from"); err == nil {
+		from = findRevision(revisions, fromId)
+	}
+	if toId, err := common.QueryUint(c, "to"); err == nil {
+		to = findRevision(revisions, toId)
+	}
+
+	h := gin.H{
+		"post":      post,
+		"revisions": revisions,
+		"user":      c.MustGet(common.ContextUserKey),
+		"comments":  models.MustListUnreadComment(),
+		"cfg":       config.GetConfiguration(),
+	}
+	if from != nil && to != nil {
+		h["from"] = from
+		h["to"] = to
+		h["titleDiff"] = common.LineDiff(from.Title, to.Title)
+		h["bodyDiff"] = common.LineDiff(from.Body, to.Body)
+	}
+	c.HTML(http.StatusOK, "admin/post_revisions.html", h)
+}
+
+func findRevision(revisions []*models.PostRevision, id uint) *models.PostRevision {
+	for _, revision := range revisions {
+		if revision.ID == id {
+			return revision
+		}
+	}
+	return nil
+}
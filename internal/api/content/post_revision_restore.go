@@ -0,0 +1,55 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PostRevisionRestore 将博文恢复到指定修订版本，走正常的更新流程（缓存延迟双删、ES重建索引）
+func PostRevisionRestore(c *gin.Context) {
+	var (
+		err      error
+		res      = gin.H{}
+		post     *models.Post
+		revision *models.PostRevision
+	)
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	rid, err := common.ParamUint(c, "rid")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Debug("PostRevisionRestore", zap.Uint("id", id), zap.Uint("rid", rid))
+	revision, err = models.GetPostRevisionById(rid)
+	if err != nil || revision.PostID != id {
+		res["message"] = "revision not found"
+		return
+	}
+	post, err = models.GetPostById(id)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if !canEditPost(c, post) {
+		res["message"] = errPostForbidden
+		return
+	}
+	post.Title = revision.Title
+	post.Body = revision.Body
+	err = post.Update()
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	setPostTags(post.ID, revision.TagIds)
+	savePostRevision(c, post)
+	res["succeed"] = true
+}
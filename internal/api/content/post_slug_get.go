@@ -0,0 +1,33 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PostSlugGet 通过 slug 访问博文，旧 slug 301 跳转到当前地址
+func PostSlugGet(c *gin.Context) {
+	slug := c.Param("slug")
+	id, err := models.GetPostIdBySlug(slug)
+	redirect := false
+	if err != nil {
+		if id, err = models.FindSlugRedirect(models.SlugKindPost, slug); err != nil {
+			common.Handle404(c)
+			return
+		}
+		redirect = true
+	}
+	post, err := models.GetPostByIdWithCache(id)
+	if err != nil || !post.IsPublished {
+		common.Handle404(c)
+		return
+	}
+	if redirect && post.Slug != slug {
+		c.Redirect(http.StatusMovedPermanently, post.URL())
+		return
+	}
+	showPost(c, post)
+}
@@ -2,7 +2,6 @@ package content
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
@@ -18,6 +17,7 @@ func PostUpdate(c *gin.Context) {
 	body := c.PostForm("body")
 	isPublished := c.PostForm("isPublished")
 	published := isPublished == "on"
+	published, publishAt, publishErr := parsePublishAt(c, published)
 
 	id, err := common.ParamUint(c, "id")
 	if err != nil {
@@ -25,14 +25,33 @@ func PostUpdate(c *gin.Context) {
 		return
 	}
 	log.Debug("PostUpdate", zap.Uint("id", id), zap.String("title", title), zap.String("tags", tags), zap.Bool("isPublished", published))
+	origin, err := models.GetPostById(id)
+	if err != nil {
+		common.Handle404(c)
+		return
+	}
+	if !canEditPost(c, origin) {
+		handlePostForbidden(c)
+		return
+	}
 
 	post := &models.Post{
 		Title:       title,
 		Body:        body,
 		IsPublished: published,
+		Slug:        c.PostForm("slug"),
+		PublishAt:   publishAt,
 	}
 	post.ID = id
-	err = post.Update()
+	post.AuthorID = origin.AuthorID
+	resolvePostReview(currentUser(c), post, origin.Pending)
+	if publishErr != nil {
+		err = publishErr
+	} else {
+		// 首次修改前为原内容保存一份快照，避免覆盖后无法找回
+		ensurePostRevisionBaseline(id)
+		err = post.Update()
+	}
 	if err != nil {
 		c.HTML(http.StatusOK, "post/modify.html", gin.H{
 			"post":    post,
@@ -42,22 +61,7 @@ func PostUpdate(c *gin.Context) {
 		})
 		return
 	}
-	// 删除tag
-	models.DeletePostTagByPostId(post.ID)
-	// 添加tag
-	if len(tags) > 0 {
-		tagArr := strings.Split(tags, ",")
-		for _, tag := range tagArr {
-			tagId, err := common.ParseUint(tag)
-			if err != nil {
-				continue
-			}
-			pt := &models.PostTag{
-				PostId: post.ID,
-				TagId:  tagId,
-			}
-			pt.Insert()
-		}
-	}
+	setPostTags(post.ID, tags)
+	savePostRevision(c, post)
 	c.Redirect(http.StatusMovedPermanently, "/admin/post")
 }
@@ -0,0 +1,65 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PostUpdateAPI 修改博文，只更新请求中提供的字段
+// @Summary 修改博文
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param id path int true "博文ID"
+// @Param post body PostInput true "需要修改的字段"
+// @Success 200 {object} common.APIResponse{data=PostView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 403 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/posts/{id} [put]
+func PostUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input PostInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	post, err := models.GetPostById(id)
+	if err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	if !canEditPost(c, post) {
+		common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "not allowed to edit this post")
+		return
+	}
+	pending := post.Pending
+	input.apply(post)
+	if post.Title == "" {
+		common.APIBadRequest(c, "title is required")
+		return
+	}
+	resolvePostReview(currentUser(c), post, pending)
+	log.Debug("PostUpdateAPI", zap.Uint("id", id), zap.String("title", post.Title), zap.Bool("isPublished", post.IsPublished))
+	ensurePostRevisionBaseline(id)
+	if err = post.Update(); err != nil {
+		common.APIDBError(c, err, "post")
+		return
+	}
+	if input.TagIDs != nil {
+		setPostTags(post.ID, joinIDs(*input.TagIDs))
+	}
+	savePostRevision(c, post)
+	models.LoadPostRelations(post)
+	common.APIOK(c, newPostView(post, true))
+}
@@ -0,0 +1,55 @@
+package content
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/subscribe"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PublishScheduled 定时任务：发布已到定时发布时间的博文和页面
+func PublishScheduled() {
+	cfg := config.GetConfiguration()
+	now := time.Now()
+
+	posts, err := models.ListDuePosts(now)
+	if err != nil {
+		log.DaemonError("scheduler", "publish", "查询待发布博文失败", "err", err)
+	}
+	for _, post := range posts {
+		published, err := post.PublishScheduled()
+		if err != nil {
+			log.DaemonError("scheduler", "publish", "定时发布博文失败", "post_id", post.ID, "err", err)
+			continue
+		}
+		if !published {
+			continue
+		}
+		log.DaemonInfo("scheduler", "publish", "博文已定时发布", "post_id", post.ID, "title", post.Title)
+		if cfg.Schedule.NotifySubscribers {
+			subject := fmt.Sprintf("[%s]%s", cfg.Title, post.Title)
+			body := fmt.Sprintf("<a href=\"%s%s\" target=\"_blank\">%s</a><p>%s</p>", cfg.Domain, post.URL(), post.Title, post.Excerpt())
+			if err := subscribe.NotifySubscribers(subject, body); err != nil {
+				log.DaemonWarn("scheduler", "publish", "通知订阅者失败", "post_id", post.ID, "err", err)
+			}
+		}
+	}
+
+	pages, err := models.ListDuePages(now)
+	if err != nil {
+		log.DaemonError("scheduler", "publish", "查询待发布页面失败", "err", err)
+	}
+	for _, page := range pages {
+		published, err := page.PublishScheduled()
+		if err != nil {
+			log.DaemonError("scheduler", "publish", "定时发布页面失败", "page_id", page.ID, "err", err)
+			continue
+		}
+		if published {
+			log.DaemonInfo("scheduler", "publish", "页面已定时发布", "page_id", page.ID, "title", page.Title)
+		}
+	}
+}
@@ -1,22 +1,39 @@
 package content
 
 import (
-	"time"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 
+	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
-	"go.uber.org/zap"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
-// recordSearchLog 记录搜索日志
-func recordSearchLog(keyword string, resultCount int) {
-	if keyword == "" {
+// recordSearchLog 记录搜索日志，翻页不重复记录
+func recordSearchLog(req *models.SearchRequest, resultCount int, ip string) {
+	if req.Query == "" || req.Page > 1 || !config.GetConfiguration().Search.LogEnabled {
 		return
 	}
 
-	// 这里可以实现搜索日志记录
-	// 比如记录到数据库或日志文件，用于分析热门搜索词
-	log.Info("搜索记录",
-		zap.String("keyword", keyword),
-		zap.Int("result_count", resultCount),
-		zap.Time("timestamp", time.Now()))
+	searchLog := &models.SearchLog{
+		Keyword:     common.Truncate(req.Query, 255),
+		Normalized:  common.Truncate(models.NormalizeSearchKeyword(req.Query), 255),
+		ResultCount: resultCount,
+		SortBy:      common.Truncate(req.SortBy, 16),
+		Tags:        common.Truncate(strings.Join(req.Tags, ","), 255),
+		Visitor:     searchVisitor(ip),
+	}
+	if err := searchLog.Insert(); err != nil {
+		log.Error("保存搜索记录失败", "keyword", req.Query, "err", err)
+	}
+}
+
+// searchVisitor 访客IP的摘要，用于统计不同访客数，热门搜索不展示只有个别访客搜索过的内容
+func searchVisitor(ip string) string {
+	mac := hmac.New(sha256.New, []byte(config.GetConfiguration().SessionSecret))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
 }
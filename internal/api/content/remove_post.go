@@ -0,0 +1,27 @@
+package content
+
+import "github.com/xiuivfbc/bmtdblog/internal/models"
+
+// removePost 删除博文及其标签关联、修订记录和旧 slug
+func removePost(id uint) error {
+	post := &models.Post{}
+	post.ID = id
+	if err := post.Delete(); err != nil {
+		return err
+	}
+	models.DeletePostTagByPostId(id)
+	models.DeletePostRevisionsByPostId(id)
+	models.DeleteSlugHistory(models.SlugKindPost, id)
+	return nil
+}
+
+// removePage 删除页面及其旧 slug
+func removePage(id uint) error {
+	page := &models.Page{}
+	page.ID = id
+	if err := page.Delete(); err != nil {
+		return err
+	}
+	models.DeleteSlugHistory(models.SlugKindPage, id)
+	return nil
+}
@@ -13,6 +13,17 @@ import (
 )
 
 func RssGet(c *gin.Context) {
+	rss, err := BuildRSS()
+	if err != nil {
+		log.Error("BuildRSS err", "err", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Writer.WriteString(rss)
+}
+
+// BuildRSS 生成全部已发布博文的 RSS 内容
+func BuildRSS() (string, error) {
 	cfg := config.GetConfiguration()
 	now := common.GetCurrentTime()
 	domain := config.GetConfiguration().Domain
@@ -27,26 +38,18 @@ func RssGet(c *gin.Context) {
 	feed.Items = make([]*feeds.Item, 0)
 	posts, err := models.ListPublishedPost("", 0, 0)
 	if err != nil {
-		log.Error("models.ListPublishedPost err", "err", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
+		return "", err
 	}
 
 	for _, post := range posts {
 		item := &feeds.Item{
 			Id:          fmt.Sprintf("%s/post/%d", domain, post.ID),
 			Title:       post.Title,
-			Link:        &feeds.Link{Href: fmt.Sprintf("%s/post/%d", domain, post.ID)},
+			Link:        &feeds.Link{Href: domain + post.URL()},
 			Description: string(post.Excerpt()),
 			Created:     now,
 		}
 		feed.Items = append(feed.Items, item)
 	}
-	rss, err := feed.ToRss()
-	if err != nil {
-		log.Error("feed.ToRss err", "err", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
-		return
-	}
-	c.Writer.WriteString(rss)
+	return feed.ToRss()
 }
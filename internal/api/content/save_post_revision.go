@@ -0,0 +1,62 @@
+package content
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// savePostRevision 为博文当前内容生成一份修订快照（标签需已写入）
+func savePostRevision(c *gin.Context, post *models.Post) {
+	revision := newPostRevision(post)
+	if user, ok := c.Get(common.ContextUserKey); ok {
+		if u, ok := user.(*models.User); ok {
+			revision.UserID = u.ID
+			revision.Author = u.NickName
+			if revision.Author == "" {
+				revision.Author = u.Email
+			}
+		}
+	}
+	if err := revision.Insert(); err != nil {
+		log.Error("保存博文修订失败", "post_id", post.ID, "error", err)
+	}
+}
+
+// ensurePostRevisionBaseline 博文尚无修订记录时，先为修改前的内容补一份快照
+func ensurePostRevisionBaseline(postID uint) {
+	if models.CountPostRevisions(postID) > 0 {
+		return
+	}
+	post, err := models.GetPostById(postID)
+	if err != nil {
+		return
+	}
+	revision := newPostRevision(post)
+	if err := revision.Insert(); err != nil {
+		log.Error("保存博文基线修订失败", "post_id", postID, "error", err)
+	}
+}
+
+func newPostRevision(post *models.Post) *models.PostRevision {
+	revision := &models.PostRevision{
+		PostID: post.ID,
+		Title:  post.Title,
+		Body:   post.Body,
+	}
+	if tags, err := models.ListTagByPostId(post.ID); err == nil {
+		ids := make([]string, 0, len(tags))
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			ids = append(ids, strconv.FormatUint(uint64(tag.ID), 10))
+			names = append(names, tag.Name)
+		}
+		revision.TagIds = strings.Join(ids, ",")
+		revision.TagNames = strings.Join(names, ",")
+	}
+	return revision
+}
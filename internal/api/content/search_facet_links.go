@@ -0,0 +1,109 @@
+package content
+
+import (
+	"html/template"
+	"net/url"
+	"slices"
+
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// searchSorts 搜索结果的排序方式
+var searchSorts = []struct {
+	key   string
+	label string
+}{
+	{"relevance", "相关性"},
+	{"date", "时间"},
+	{"views", "浏览量"},
+}
+
+// facetLink 搜索结果页的筛选链接，点击后在当前条件上增加或取消该筛选
+type facetLink struct {
+	Label    string
+	Count    int64
+	Selected bool
+	URL      template.URL
+}
+
+// searchParams 当前搜索条件对应的查询参数，不包含页码
+func searchParams(req *models.SearchRequest) url.Values {
+	params := url.Values{}
+	params.Set("q", req.Query)
+	if req.SortBy != "" {
+		params.Set("sort", req.SortBy)
+	}
+	if len(req.Tags) > 0 {
+		params["tags"] = req.Tags
+	}
+	for key, value := range map[string]string{
+		"date_from": req.DateFrom,
+		"date_to":   req.DateTo,
+		"month":     req.Month,
+		"views":     req.Views,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	return params
+}
+
+// searchURL 修改当前搜索条件中的一项，values 为空时去掉该条件
+func searchURL(params url.Values, key string, values ...string) template.URL {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	if len(values) == 0 {
+		query.Del(key)
+	} else {
+		query[key] = values
+	}
+	return template.URL("/search?" + query.Encode())
+}
+
+// clearFacetsURL 去掉全部分面筛选
+func clearFacetsURL(params url.Values) template.URL {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	for _, key := range []string{"tags", "month", "views"} {
+		query.Del(key)
+	}
+	return template.URL("/search?" + query.Encode())
+}
+
+// tagFacetLinks 标签筛选链接，可以同时选中多个标签
+func tagFacetLinks(params url.Values, req *models.SearchRequest, buckets []*models.FacetBucket) []*facetLink {
+	links := make([]*facetLink, 0, len(buckets))
+	for _, bucket := range buckets {
+		link := &facetLink{Label: bucket.Label, Count: bucket.Count}
+		if slices.Contains(req.Tags, bucket.Key) {
+			link.Selected = true
+			link.URL = searchURL(params, "tags", slices.DeleteFunc(slices.Clone(req.Tags), func(tag string) bool {
+				return tag == bucket.Key
+			})...)
+		} else {
+			link.URL = searchURL(params, "tags", append(slices.Clone(req.Tags), bucket.Key)...)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// singleFacetLinks 只能选中一项的筛选链接，再次点击取消
+func singleFacetLinks(params url.Values, key, selected string, buckets []*models.FacetBucket) []*facetLink {
+	links := make([]*facetLink, 0, len(buckets))
+	for _, bucket := range buckets {
+		link := &facetLink{Label: bucket.Label, Count: bucket.Count, Selected: bucket.Key == selected}
+		if link.Selected {
+			link.URL = searchURL(params, key)
+		} else {
+			link.URL = searchURL(params, key, bucket.Key)
+		}
+		links = append(links, link)
+	}
+	return links
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
@@ -17,7 +19,10 @@ import (
 // SearchGet 搜索页面
 func SearchGet(c *gin.Context) {
 	keyword := strings.TrimSpace(c.Query("q"))
-	tags := c.QueryArray("tags")
+	// 高级搜索的标签下拉框可能提交空值
+	tags := slices.DeleteFunc(c.QueryArray("tags"), func(tag string) bool {
+		return strings.TrimSpace(tag) == ""
+	})
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if page <= 0 {
 		page = 1
@@ -26,6 +31,14 @@ func SearchGet(c *gin.Context) {
 	sortBy := c.DefaultQuery("sort", "relevance")
 	dateFrom := c.Query("date_from")
 	dateTo := c.Query("date_to")
+	month := c.Query("month")
+	if _, err := time.Parse("2006-01", month); err != nil {
+		month = ""
+	}
+	views := c.Query("views")
+	if _, ok := models.GetViewRange(views); !ok {
+		views = ""
+	}
 	pageSize := 10
 
 	// 构建搜索请求
@@ -37,6 +50,8 @@ func SearchGet(c *gin.Context) {
 		SortBy:   sortBy,
 		DateFrom: dateFrom,
 		DateTo:   dateTo,
+		Month:    month,
+		Views:    views,
 	}
 
 	// 执行搜索
@@ -55,14 +70,31 @@ func SearchGet(c *gin.Context) {
 	}
 
 	// 记录搜索日志（用于分析热门搜索词）
-	go recordSearchLog(keyword, int(searchResp.Total))
+	go recordSearchLog(req, int(searchResp.Total), c.ClientIP())
 
 	log.Debug("搜索完成", "keyword", keyword, "results", len(searchResp.Posts), "total", searchResp.Total)
 
+	params := searchParams(req)
+	var tagFacets, monthFacets, viewFacets []*facetLink
+	if facets := searchResp.Facets; facets != nil {
+		tagFacets = tagFacetLinks(params, req, facets.Tags)
+		monthFacets = singleFacetLinks(params, "month", month, facets.Months)
+		viewFacets = singleFacetLinks(params, "views", views, facets.Views)
+	}
+	sortLinks := make([]*facetLink, 0, len(searchSorts))
+	for _, sort := range searchSorts {
+		sortLinks = append(sortLinks, &facetLink{
+			Label:    sort.label,
+			Selected: sort.key == sortBy,
+			URL:      searchURL(params, "sort", sort.key),
+		})
+	}
+
 	user, _ := c.Get(common.ContextUserKey)
 	c.HTML(http.StatusOK, "search/results.html", gin.H{
 		"keyword":         keyword,
 		"posts":           searchResp.Posts,
+		"highlights":      searchResp.Highlights,
 		"total":           searchResp.Total,
 		"page":            page,
 		"totalPages":      int(math.Ceil(float64(searchResp.Total) / float64(pageSize))),
@@ -76,6 +108,15 @@ func SearchGet(c *gin.Context) {
 		"maxCommentPosts": models.MustListMaxCommentPost(),
 		"dateFrom":        dateFrom,
 		"dateTo":          dateTo,
+		"month":           month,
+		"views":           views,
+		"tagFacets":       tagFacets,
+		"monthFacets":     monthFacets,
+		"viewFacets":      viewFacets,
+		"sortLinks":       sortLinks,
+		"pageURL":         searchURL(params, "page"),
+		"filtered":        len(tags) > 0 || month != "" || views != "",
+		"clearURL":        clearFacetsURL(params),
 		"suggestions":     searchResp.Suggestions,
 		"user":            user,
 		"cfg":             config.GetConfiguration(),
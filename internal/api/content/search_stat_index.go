@@ -0,0 +1,92 @@
+package content
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 搜索统计页面每个列表显示的关键词数
+const searchStatLimit = 50
+
+// searchStatPeriods 可选的统计天数
+var searchStatPeriods = []int{7, 30, 90}
+
+// SearchStatIndex 搜索统计页面：热门搜索、无结果搜索和每日趋势
+func SearchStatIndex(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	valid := false
+	for _, period := range searchStatPeriods {
+		valid = valid || period == days
+	}
+	if !valid {
+		days = 30
+	}
+
+	// 今天的记录先汇总一次，页面显示的数据不必等定时任务
+	now := time.Now()
+	if _, err := models.AggregateSearchStats(now); err != nil {
+		log.Error("Aggregate search stats failed", "err", err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-days)
+
+	topQueries, err := models.ListTopSearchQueries(since, searchStatLimit)
+	if err != nil {
+		log.Error("List top search queries failed", "err", err)
+	}
+	zeroQueries, err := models.ListZeroResultQueries(since, searchStatLimit)
+	if err != nil {
+		log.Error("List zero result queries failed", "err", err)
+	}
+	trends, err := models.ListSearchTrends(since)
+	if err != nil {
+		log.Error("List search trends failed", "err", err)
+	}
+	trends = fillSearchTrends(trends, since, days)
+
+	var total, zero, peak int64
+	for _, trend := range trends {
+		total += trend.Searches
+		zero += trend.ZeroResults
+		peak = max(peak, trend.Searches)
+	}
+	c.HTML(http.StatusOK, "admin/search_stat.html", gin.H{
+		"days":        days,
+		"periods":     searchStatPeriods,
+		"topQueries":  topQueries,
+		"zeroQueries": zeroQueries,
+		"trends":      trends,
+		"total":       total,
+		"zero":        zero,
+		"peak":        peak,
+		"enabled":     config.GetConfiguration().Search.LogEnabled,
+		"user":        c.MustGet(common.ContextUserKey),
+		"comments":    models.MustListUnreadComment(),
+		"cfg":         config.GetConfiguration(),
+	})
+}
+
+// fillSearchTrends 补齐没有搜索的日期，从新到旧排列
+func fillSearchTrends(trends []*models.SearchTrend, since time.Time, days int) []*models.SearchTrend {
+	byDay := make(map[string]*models.SearchTrend, len(trends))
+	for _, trend := range trends {
+		byDay[trend.Day.Format("2006-01-02")] = trend
+	}
+	filled := make([]*models.SearchTrend, 0, days)
+	for i := days - 1; i >= 0; i-- {
+		day := since.AddDate(0, 0, i)
+		trend, ok := byDay[day.Format("2006-01-02")]
+		if !ok {
+			trend = &models.SearchTrend{Day: day}
+		}
+		filled = append(filled, trend)
+	}
+	return filled
+}
@@ -0,0 +1,20 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SearchSyncCheck 立即检查搜索索引与数据库是否一致，并为差异创建同步任务
+func SearchSyncCheck(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	report, err := models.CheckSearchConsistency(true)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+	res["consistent"] = report.Consistent()
+}
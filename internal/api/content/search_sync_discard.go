@@ -0,0 +1,33 @@
+package content
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SearchSyncDiscard 丢弃死信任务，不带 id 时丢弃全部，索引差异可由一致性检查重新发现
+func SearchSyncDiscard(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	var ids []uint
+	if idStr := c.PostForm("id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			res["message"] = err.Error()
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	count, err := models.DeleteDeadSearchTasks(ids...)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Dead search tasks discarded", "count", count, "ip", c.ClientIP())
+	res["succeed"] = true
+	res["count"] = count
+}
@@ -0,0 +1,36 @@
+package content
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 搜索同步页面显示的死信任务数
+const deadSearchTaskLimit = 100
+
+// SearchSyncIndex 搜索同步页面：待处理任务、死信任务和最近一次一致性检查结果
+func SearchSyncIndex(c *gin.Context) {
+	pending, dead, err := models.CountSearchOutbox()
+	if err != nil {
+		log.Error("Count search outbox failed", "err", err)
+	}
+	deadTasks, err := models.ListDeadSearchTasks(deadSearchTaskLimit)
+	if err != nil {
+		log.Error("List dead search tasks failed", "err", err)
+	}
+	c.HTML(http.StatusOK, "admin/search_sync.html", gin.H{
+		"engine":    models.CurrentSearchEngine().Name(),
+		"pending":   pending,
+		"dead":      dead,
+		"deadTasks": deadTasks,
+		"report":    models.LastSearchConsistencyReport(),
+		"user":      c.MustGet(common.ContextUserKey),
+		"comments":  models.MustListUnreadComment(),
+		"cfg":       config.GetConfiguration(),
+	})
+}
@@ -0,0 +1,33 @@
+package content
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SearchSyncRetry 重试死信任务，不带 id 时重试全部
+func SearchSyncRetry(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	var ids []uint
+	if idStr := c.PostForm("id"); idStr != "" {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			res["message"] = err.Error()
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+	count, err := models.RetryDeadSearchTasks(ids...)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Dead search tasks retried", "count", count, "ip", c.ClientIP())
+	res["succeed"] = true
+	res["count"] = count
+}
@@ -0,0 +1,26 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// setPostTags 重置博文标签，tags为逗号分隔的标签ID
+func setPostTags(postID uint, tags string) {
+	var tagIds []uint
+	if len(tags) > 0 {
+		for _, tag := range strings.Split(tags, ",") {
+			tagId, err := common.ParseUint(tag)
+			if err != nil {
+				continue
+			}
+			tagIds = append(tagIds, tagId)
+		}
+	}
+	if err := models.SetPostTags(postID, tagIds); err != nil {
+		log.Error("设置博文标签失败", "post_id", postID, "err", err)
+	}
+}
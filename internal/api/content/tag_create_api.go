@@ -0,0 +1,37 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TagCreateAPI 创建标签，同名标签已存在时直接返回
+// @Summary 创建标签
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body TagInput true "标签"
+// @Success 201 {object} common.APIResponse{data=TagView}
+// @Failure 400 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/tags [post]
+func TagCreateAPI(c *gin.Context) {
+	var input TagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	tag := &models.Tag{Name: strings.TrimSpace(input.Name)}
+	if tag.Name == "" {
+		common.APIBadRequest(c, "name is required")
+		return
+	}
+	if err := tag.Insert(); err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	common.APICreated(c, newTagView(tag))
+}
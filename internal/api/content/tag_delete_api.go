@@ -0,0 +1,35 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TagDeleteAPI 删除标签及其与博文的关联
+// @Summary 删除标签
+// @Tags tags
+// @Produce json
+// @Param id path int true "标签ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/tags/{id} [delete]
+func TagDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	tag, err := models.GetTagById(id)
+	if err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	if err = tag.Delete(); err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	common.APIOK(c, nil)
+}
@@ -1,13 +1,10 @@
 package content
 
 import (
-	"math"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/microcosm-cc/bluemonday"
-	"github.com/russross/blackfriday"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
@@ -22,7 +19,6 @@ func TagGet(c *gin.Context) {
 		pageSize  = config.GetConfiguration().PageSize
 		total     int
 		err       error
-		policy    *bluemonday.Policy
 		posts     []*models.Post
 	)
 	tagName = c.Param("tag")
@@ -43,23 +39,8 @@ func TagGet(c *gin.Context) {
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
-	policy = bluemonday.StrictPolicy()
-	for _, post := range posts {
-		post.Tags, _ = models.ListTagByPostId(post.ID)
-		post.Body = policy.Sanitize(string(blackfriday.MarkdownCommon([]byte(post.Body))))
-		post.CommentTotal = models.CountCommentByPostID(post.ID)
-	}
 	user, _ := c.Get(common.ContextUserKey)
-	c.HTML(http.StatusOK, "index/index.html", gin.H{
-		"posts":           posts,
-		"tags":            models.MustListTag(),
-		"archives":        models.MustListPostArchives(),
-		"links":           models.MustListLinks(),
-		"pageIndex":       pageIndex,
-		"totalPage":       int(math.Ceil(float64(total) / float64(pageSize))),
-		"maxReadPosts":    models.MustListMaxReadPost(),
-		"maxCommentPosts": models.MustListMaxCommentPost(),
-		"user":            user,
-		"cfg":             config.GetConfiguration(),
-	})
+	data := ListPageData(posts, pageIndex, total)
+	data["user"] = user
+	c.HTML(http.StatusOK, "index/index.html", data)
 }
@@ -0,0 +1,28 @@
+package content
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TagListAPI 列出全部标签
+// @Summary 标签列表
+// @Tags tags
+// @Produce json
+// @Success 200 {object} common.APIResponse{data=[]TagView}
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/tags [get]
+func TagListAPI(c *gin.Context) {
+	tags, err := models.ListAllTag()
+	if err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	items := make([]TagView, 0, len(tags))
+	for _, tag := range tags {
+		items = append(items, newTagView(tag))
+	}
+	common.APIOK(c, items)
+}
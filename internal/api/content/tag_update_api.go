@@ -0,0 +1,49 @@
+package content
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TagUpdateAPI 修改标签名称
+// @Summary 修改标签
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "标签ID"
+// @Param tag body TagInput true "标签"
+// @Success 200 {object} common.APIResponse{data=TagView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/tags/{id} [put]
+func TagUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input TagInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	tag, err := models.GetTagById(id)
+	if err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	tag.Name = strings.TrimSpace(input.Name)
+	if tag.Name == "" {
+		common.APIBadRequest(c, "name is required")
+		return
+	}
+	if err = tag.Update(); err != nil {
+		common.APIDBError(c, err, "tag")
+		return
+	}
+	common.APIOK(c, newTagView(tag))
+}
@@ -72,6 +72,7 @@ type EmailQueue struct {
 	stats       EmailQueueStats
 	statsMutex  sync.RWMutex
 	sendFunc    func(to, subject, body string) error // 邮件发送回调函数
+	failFunc    func(task EmailTask, err error)      // 邮件移入失败队列时的回调函数
 }
 
 // EmailWorker 邮件工作者
@@ -234,6 +235,13 @@ func SetEmailSender(sendFunc func(to, subject, body string) error) {
 	}
 }
 
+// SetEmailFailureHandler 设置邮件多次发送失败、移入失败队列时的回调函数
+func SetEmailFailureHandler(failFunc func(task EmailTask, err error)) {
+	if EmailQueueInstance != nil {
+		EmailQueueInstance.failFunc = failFunc
+	}
+}
+
 // startWorker 启动一个新的Worker
 func (eq *EmailQueue) startWorker(workerID int) {
 	eq.workerMutex.Lock()
@@ -713,6 +721,10 @@ func (ew *EmailWorker) moveToFailedQueue(task EmailTask, err error) error {
 		"worker_id", ew.id,
 		"to", task.To)
 
+	if ew.queue.failFunc != nil {
+		ew.queue.failFunc(task, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,317 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// PostIndexVersion 博文索引映射的版本号，修改映射后递增，启动时会自动重建索引
+const PostIndexVersion = 3
+
+// 中文分析器
+const (
+	AnalyzerAuto     = "auto"     // 按已安装的插件依次选择 ik、smartcn、cjk
+	AnalyzerCJK      = "cjk"      // 内置的 CJK 二元切分，不需要插件
+	AnalyzerIK       = "ik"       // analysis-ik 插件
+	AnalyzerSmartcn  = "smartcn"  // analysis-smartcn 插件
+	AnalyzerStandard = "standard" // 默认的标准分析器，中文按单字切分
+)
+
+// 分析器依赖的插件
+const (
+	pluginIK      = "analysis-ik"
+	pluginSmartcn = "analysis-smartcn"
+	pluginPinyin  = "analysis-pinyin"
+)
+
+// PostIndexSpec 博文索引的映射规格，保存在索引的 _meta 中，规格不同时需要重建索引
+type PostIndexSpec struct {
+	Version  int    `json:"version"`
+	Analyzer string `json:"analyzer"`
+	Pinyin   bool   `json:"pinyin"`
+}
+
+// ESPlugins 查询 ES 已安装的插件
+func ESPlugins() (map[string]bool, error) {
+	res, err := ESClient.Cat.Plugins(ESClient.Cat.Plugins.WithFormat("json"))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("查询插件失败: %s", res.String())
+	}
+	var rows []struct {
+		Component string `json:"component"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
+		return nil, err
+	}
+	plugins := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		plugins[row.Component] = true
+	}
+	return plugins, nil
+}
+
+// ResolvePostIndexSpec 根据配置和已安装的插件确定索引规格，配置的插件不存在时退回 cjk
+func ResolvePostIndexSpec(cfg config.ElasticsearchConfig) PostIndexSpec {
+	plugins, err := ESPlugins()
+	if err != nil {
+		log.Warn("查询ES插件失败，按未安装处理", "error", err)
+	}
+	spec := PostIndexSpec{Version: PostIndexVersion, Analyzer: strings.ToLower(cfg.Analyzer)}
+	switch spec.Analyzer {
+	case AnalyzerCJK, AnalyzerStandard:
+	case AnalyzerIK, AnalyzerSmartcn:
+		if !plugins["analysis-"+spec.Analyzer] {
+			log.Warn("ES未安装分析器插件，使用内置的cjk分析器", "analyzer", spec.Analyzer)
+			spec.Analyzer = AnalyzerCJK
+		}
+	default:
+		spec.Analyzer = AnalyzerCJK
+		if plugins[pluginIK] {
+			spec.Analyzer = AnalyzerIK
+		} else if plugins[pluginSmartcn] {
+			spec.Analyzer = AnalyzerSmartcn
+		}
+	}
+	spec.Pinyin = cfg.Pinyin && plugins[pluginPinyin]
+	if cfg.Pinyin && !spec.Pinyin {
+		log.Warn("ES未安装analysis-pinyin插件，搜索建议不支持拼音")
+	}
+	return spec
+}
+
+// textAnalyzers 正文字段的索引和搜索分析器
+func (spec PostIndexSpec) textAnalyzers() (index, search string) {
+	switch spec.Analyzer {
+	case AnalyzerIK:
+		return "ik_max_word", "ik_smart"
+	case AnalyzerSmartcn:
+		return "smartcn", "smartcn"
+	case AnalyzerStandard:
+		return "standard", "standard"
+	default:
+		return "blog_cjk", "blog_cjk"
+	}
+}
+
+// postIndexBody 创建博文索引的 settings 和 mappings
+func postIndexBody(spec PostIndexSpec) map[string]interface{} {
+	analysis := map[string]interface{}{
+		"analyzer": map[string]interface{}{
+			// 全角转半角、转小写后对中日韩文字做二元切分，其他文字按标准分词
+			"blog_cjk": map[string]interface{}{
+				"type":      "custom",
+				"tokenizer": "standard",
+				"filter":    []string{"cjk_width", "lowercase", "cjk_bigram"},
+			},
+		},
+	}
+	if spec.Pinyin {
+		analysis["tokenizer"] = map[string]interface{}{
+			"blog_pinyin": map[string]interface{}{
+				"type":                      "pinyin",
+				"keep_first_letter":         true,
+				"keep_full_pinyin":          false,
+				"keep_joined_full_pinyin":   true,
+				"keep_original":             false,
+				"limit_first_letter_length": 32,
+				"lowercase":                 true,
+				"remove_duplicated_term":    true,
+			},
+		}
+		analysis["analyzer"].(map[string]interface{})["blog_pinyin"] = map[string]interface{}{
+			"type":      "custom",
+			"tokenizer": "blog_pinyin",
+		}
+	}
+
+	indexAnalyzer, searchAnalyzer := spec.textAnalyzers()
+	text := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":            "text",
+			"analyzer":        indexAnalyzer,
+			"search_analyzer": searchAnalyzer,
+		}
+	}
+	title := text()
+	titleFields := map[string]interface{}{
+		"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+	}
+	if spec.Pinyin {
+		titleFields["pinyin"] = map[string]interface{}{"type": "text", "analyzer": "blog_pinyin"}
+	}
+	title["fields"] = titleFields
+
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"number_of_shards":   1,
+			"number_of_replicas": 0,
+			"analysis":           analysis,
+		},
+		"mappings": map[string]interface{}{
+			"_meta": spec,
+			"properties": map[string]interface{}{
+				"id":            map[string]interface{}{"type": "long"},
+				"title":         title,
+				"body":          text(),
+				"excerpt":       text(),
+				"tags":          map[string]interface{}{"type": "keyword"},
+				"author":        map[string]interface{}{"type": "keyword"},
+				"is_published":  map[string]interface{}{"type": "boolean"},
+				"created_at":    map[string]interface{}{"type": "date"},
+				"updated_at":    map[string]interface{}{"type": "date"},
+				"view_count":    map[string]interface{}{"type": "long"},
+				"comment_count": map[string]interface{}{"type": "long"},
+				"checksum":      map[string]interface{}{"type": "keyword", "index": false},
+			},
+		},
+	}
+}
+
+// NewPostIndexName 生成带版本和时间的索引名，别名指向当前使用的索引
+func NewPostIndexName(alias string) string {
+	return fmt.Sprintf("%s_v%d_%s", alias, PostIndexVersion, time.Now().Format("20060102150405"))
+}
+
+// CreatePostIndex 按规格创建博文索引
+func CreatePostIndex(indexName string, spec PostIndexSpec) error {
+	if ESClient == nil {
+		return fmt.Errorf("ES客户端未初始化")
+	}
+	body, err := json.Marshal(postIndexBody(spec))
+	if err != nil {
+		return err
+	}
+	res, err := ESClient.Indices.Create(indexName, ESClient.Indices.Create.WithBody(strings.NewReader(string(body))))
+	if err := checkESResponse(res, err); err != nil {
+		return fmt.Errorf("创建索引失败: %w", err)
+	}
+	log.Info("索引创建成功", "index", indexName, "analyzer", spec.Analyzer, "pinyin", spec.Pinyin)
+	return nil
+}
+
+// AliasIndices 别名当前指向的索引，别名不存在时返回空
+func AliasIndices(alias string) ([]string, error) {
+	res, err := ESClient.Indices.GetAlias(ESClient.Indices.GetAlias.WithName(alias))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("查询别名失败: %s", res.String())
+	}
+	var aliases map[string]json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+		return nil, err
+	}
+	indices := make([]string, 0, len(aliases))
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// IndexExists 检查索引或别名是否存在
+func IndexExists(name string) (bool, error) {
+	res, err := ESClient.Indices.Exists([]string{name})
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode == http.StatusOK, nil
+}
+
+// GetPostIndexSpec 读取索引 _meta 中保存的映射规格，旧版索引没有规格时返回零值
+func GetPostIndexSpec(indexName string) (PostIndexSpec, error) {
+	var spec PostIndexSpec
+	res, err := ESClient.Indices.GetMapping(ESClient.Indices.GetMapping.WithIndex(indexName))
+	if err != nil {
+		return spec, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return spec, fmt.Errorf("查询映射失败: %s", res.String())
+	}
+	var mappings map[string]struct {
+		Mappings struct {
+			Meta PostIndexSpec `json:"_meta"`
+		} `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&mappings); err != nil {
+		return spec, err
+	}
+	if mapping, ok := mappings[indexName]; ok {
+		spec = mapping.Mappings.Meta
+	}
+	return spec, nil
+}
+
+// SwitchAlias 原子地把别名切换到新索引；legacy 为与别名同名的旧索引，切换时一并删除
+func SwitchAlias(alias, newIndex string, oldIndices []string, legacy bool) error {
+	actions := []map[string]interface{}{}
+	for _, index := range oldIndices {
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]string{"index": index, "alias": alias},
+		})
+	}
+	if legacy {
+		actions = append(actions, map[string]interface{}{
+			"remove_index": map[string]string{"index": alias},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]string{"index": newIndex, "alias": alias},
+	})
+	body, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	res, err := ESClient.Indices.UpdateAliases(strings.NewReader(string(body)))
+	if err := checkESResponse(res, err); err != nil {
+		return fmt.Errorf("切换别名失败: %w", err)
+	}
+	log.Info("索引别名已切换", "alias", alias, "index", newIndex, "previous", oldIndices, "legacy_removed", legacy)
+	return nil
+}
+
+// RefreshIndex 刷新索引，使写入的文档可以被搜索到
+func RefreshIndex(indexName string) error {
+	res, err := ESClient.Indices.Refresh(ESClient.Indices.Refresh.WithIndex(indexName))
+	return checkESResponse(res, err)
+}
+
+// DeleteIndices 删除索引
+func DeleteIndices(indices ...string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	res, err := ESClient.Indices.Delete(indices)
+	return checkESResponse(res, err)
+}
+
+// checkESResponse 关闭响应并把错误状态码转换为 error
+func checkESResponse(res *esapi.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%s: %s", res.Status(), body)
+	}
+	return nil
+}
@@ -197,6 +197,62 @@ func (r *RedisCacheClient) SetNX(key string, value interface{}, expiration time.
 	return result, nil
 }
 
+// Incr 计数器自增，首次创建时设置过期时间（固定窗口计数）
+func (r *RedisCacheClient) Incr(key string, expiration time.Duration) (int64, error) {
+	if !r.IsAvailable() {
+		return 0, fmt.Errorf("redis is not available")
+	}
+
+	count, err := r.client.Incr(r.ctx, key).Result()
+	if err != nil {
+		log.Error("Failed to incr", "key", key, "error", err)
+		return 0, err
+	}
+	if count == 1 {
+		if err := r.client.Expire(r.ctx, key, expiration).Err(); err != nil {
+			log.Error("Failed to set expiration", "key", key, "error", err)
+		}
+	}
+
+	return count, nil
+}
+
+// ZIncrBy 有序集合中成员的分数自增，并刷新整个集合的过期时间
+func (r *RedisCacheClient) ZIncrBy(key, member string, increment float64, expiration time.Duration) error {
+	if !r.IsAvailable() {
+		return fmt.Errorf("redis is not available")
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.ZIncrBy(r.ctx, key, increment, member)
+	pipe.Expire(r.ctx, key, expiration)
+	if _, err := pipe.Exec(r.ctx); err != nil {
+		log.Error("Failed to zincrby", "key", key, "member", member, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ZTopScores 有序集合中分数最高的 limit 个成员及其分数
+func (r *RedisCacheClient) ZTopScores(key string, limit int) (map[string]float64, error) {
+	if !r.IsAvailable() {
+		return nil, fmt.Errorf("redis is not available")
+	}
+
+	members, err := r.client.ZRevRangeWithScores(r.ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		log.Error("Failed to get sorted set", "key", key, "error", err)
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(members))
+	for _, member := range members {
+		scores[fmt.Sprint(member.Member)] = member.Score
+	}
+	return scores, nil
+}
+
 // 设置过期时间
 func (r *RedisCacheClient) Expire(key string, expiration time.Duration) error {
 	if !r.IsAvailable() {
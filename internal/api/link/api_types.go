@@ -0,0 +1,47 @@
+package link
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// LinkView /api/v1 友情链接结构
+type LinkView struct {
+	ID        uint       `json:"id" example:"1"`
+	Name      string     `json:"name" example:"Go"`
+	Url       string     `json:"url" example:"https://go.dev"`
+	Sort      int        `json:"sort"`
+	View      int        `json:"view"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// LinkInput 创建或修改友情链接的请求体，修改时未提供的字段保持不变
+type LinkInput struct {
+	Name *string `json:"name" example:"Go"`
+	Url  *string `json:"url" example:"https://go.dev"`
+	Sort *int    `json:"sort" example:"0"`
+}
+
+func newLinkView(link *models.Link) LinkView {
+	return LinkView{
+		ID:        link.ID,
+		Name:      link.Name,
+		Url:       link.Url,
+		Sort:      link.Sort,
+		View:      link.View,
+		CreatedAt: link.CreatedAt,
+	}
+}
+
+func (input *LinkInput) apply(link *models.Link) {
+	if input.Name != nil {
+		link.Name = *input.Name
+	}
+	if input.Url != nil {
+		link.Url = *input.Url
+	}
+	if input.Sort != nil {
+		link.Sort = *input.Sort
+	}
+}
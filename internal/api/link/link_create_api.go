@@ -0,0 +1,39 @@
+package link
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// LinkCreateAPI 创建友情链接，相同地址的链接已存在时直接返回
+// @Summary 创建友情链接
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param link body LinkInput true "友情链接，name 和 url 必填"
+// @Success 201 {object} common.APIResponse{data=LinkView}
+// @Failure 400 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/links [post]
+func LinkCreateAPI(c *gin.Context) {
+	var input LinkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	link := &models.Link{}
+	input.apply(link)
+	if link.Name == "" || link.Url == "" {
+		common.APIBadRequest(c, "name and url are required")
+		return
+	}
+	log.Debug("LinkCreateAPI", zap.String("name", link.Name), zap.String("url", link.Url), zap.Int("sort", link.Sort))
+	if err := link.Insert(); err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	common.APICreated(c, newLinkView(link))
+}
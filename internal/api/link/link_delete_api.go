@@ -0,0 +1,38 @@
+package link
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// LinkDeleteAPI 删除友情链接
+// @Summary 删除友情链接
+// @Tags links
+// @Produce json
+// @Param id path int true "友情链接ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/links/{id} [delete]
+func LinkDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	link, err := models.GetLinkById(id)
+	if err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	log.Debug("LinkDeleteAPI", zap.Uint("id", id))
+	if err = link.Delete(); err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	common.APIOK(c, nil)
+}
@@ -17,8 +17,8 @@ func LinkGet(c *gin.Context) {
 	}
 	link, err := models.GetLinkById(id)
 	if err != nil {
-		log.Error("models.GetLinkById error", "err", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
+		log.Warn("models.GetLinkById error", "id", id, "err", err)
+		common.Handle404(c)
 		return
 	}
 	link.View++
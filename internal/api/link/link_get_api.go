@@ -0,0 +1,31 @@
+package link
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// LinkGetAPI 查询单个友情链接
+// @Summary 友情链接详情
+// @Tags links
+// @Produce json
+// @Param id path int true "友情链接ID"
+// @Success 200 {object} common.APIResponse{data=LinkView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/links/{id} [get]
+func LinkGetAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	link, err := models.GetLinkById(id)
+	if err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	common.APIOK(c, newLinkView(link))
+}
@@ -0,0 +1,28 @@
+package link
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// LinkListAPI 按排序列出全部友情链接
+// @Summary 友情链接列表
+// @Tags links
+// @Produce json
+// @Success 200 {object} common.APIResponse{data=[]LinkView}
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/links [get]
+func LinkListAPI(c *gin.Context) {
+	links, err := models.ListLinks()
+	if err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	items := make([]LinkView, 0, len(links))
+	for _, link := range links {
+		items = append(items, newLinkView(link))
+	}
+	common.APIOK(c, items)
+}
@@ -0,0 +1,50 @@
+package link
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// LinkUpdateAPI 修改友情链接，只更新请求中提供的字段
+// @Summary 修改友情链接
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param id path int true "友情链接ID"
+// @Param link body LinkInput true "需要修改的字段"
+// @Success 200 {object} common.APIResponse{data=LinkView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/links/{id} [put]
+func LinkUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input LinkInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	link, err := models.GetLinkById(id)
+	if err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	input.apply(link)
+	if link.Name == "" || link.Url == "" {
+		common.APIBadRequest(c, "name and url are required")
+		return
+	}
+	log.Debug("LinkUpdateAPI", zap.Uint("id", id), zap.String("name", link.Name), zap.String("url", link.Url), zap.Int("sort", link.Sort))
+	if err = link.Update(); err != nil {
+		common.APIDBError(c, err, "link")
+		return
+	}
+	common.APIOK(c, newLinkView(link))
+}
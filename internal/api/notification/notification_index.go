@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
+)
+
+// 通知页面每页显示的通知数
+const notificationPageSize = 20
+
+// NotificationIndex 通知页面：已配置的渠道、事件路由和站内通知列表
+func NotificationIndex(c *gin.Context) {
+	pageIndex, _ := strconv.Atoi(c.Query("page"))
+	if pageIndex <= 0 {
+		pageIndex = 1
+	}
+	notifications, err := models.ListNotification(pageIndex, notificationPageSize)
+	if err != nil {
+		log.Error("List notification failed", "err", err)
+	}
+	total, err := models.CountNotification()
+	if err != nil {
+		log.Error("Count notification failed", "err", err)
+	}
+	var (
+		channels []*notify.ChannelInfo
+		routes   []*notify.RouteInfo
+	)
+	if notifier := notify.Default(); notifier != nil {
+		channels = notifier.Channels()
+		routes = notifier.Routes()
+	}
+	c.HTML(http.StatusOK, "admin/notification.html", gin.H{
+		"channels":      channels,
+		"routes":        routes,
+		"notifications": notifications,
+		"total":         total,
+		"pageIndex":     pageIndex,
+		"hasPrev":       pageIndex > 1,
+		"hasNext":       int64(pageIndex*notificationPageSize) < total,
+		"user":          c.MustGet(common.ContextUserKey),
+		"comments":      models.MustListUnreadComment(),
+		"cfg":           config.GetConfiguration(),
+	})
+}
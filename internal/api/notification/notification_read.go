@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// NotificationRead 标记一条通知为已读
+func NotificationRead(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = models.SetNotificationRead(id); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
@@ -0,0 +1,18 @@
+package notification
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// NotificationReadAll 标记全部通知为已读
+func NotificationReadAll(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	if err := models.SetAllNotificationRead(); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
+)
+
+// NotificationTestSend 向指定渠道同步发送一条测试通知，返回发送结果，用于检查渠道配置
+func NotificationTestSend(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	notifier := notify.Default()
+	if notifier == nil {
+		res["message"] = "通知渠道未初始化"
+		return
+	}
+	name := c.PostForm("channel")
+	cfg := config.GetConfiguration()
+	err := notifier.Send(name, &notify.Message{
+		Event: "test",
+		Title: "测试通知",
+		Text:  "这是一条来自 " + cfg.Title + " 的测试通知，收到说明渠道配置正确。",
+		Link:  cfg.Domain + "/admin/notification",
+		Time:  time.Now(),
+	})
+	if err != nil {
+		log.Warn("Test notification failed", "channel", name, "err", err)
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
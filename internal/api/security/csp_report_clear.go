@@ -0,0 +1,21 @@
+package security
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// CSPReportClear 清空 CSP 违规报告，修复策略后用于观察是否还有新的违规
+func CSPReportClear(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	deleted, err := models.ClearCSPReports()
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("CSP reports cleared", "count", deleted, "ip", c.ClientIP())
+	res["succeed"] = true
+}
@@ -0,0 +1,34 @@
+package security
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/middleware"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 报告页面显示的汇总条数
+const reportGroupLimit = 100
+
+// CSPReportIndex CSP 违规报告页面，按违规指令和被拦截资源汇总
+func CSPReportIndex(c *gin.Context) {
+	groups, err := models.ListCSPReportGroups(reportGroupLimit)
+	if err != nil {
+		log.Error("List csp reports failed", "err", err)
+	}
+	total, _ := models.CountCSPReports()
+	cfg := config.GetConfiguration()
+	c.HTML(http.StatusOK, "admin/csp_report.html", gin.H{
+		"groups":   groups,
+		"total":    total,
+		"enabled":  cfg.Security.Enabled,
+		"mode":     middleware.CSPMode(cfg.Security),
+		"user":     c.MustGet(common.ContextUserKey),
+		"comments": models.MustListUnreadComment(),
+		"cfg":      cfg,
+	})
+}
@@ -0,0 +1,155 @@
+package security
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+const (
+	// 单个请求体大小上限
+	maxReportBody = 64 << 10
+	// 单个IP每分钟最多接收的报告数
+	reportRateLimit  = 60
+	reportRatePrefix = "csp_report_ip"
+	// 默认保留天数
+	defaultReportRetention = 30
+	// 清理过期报告的间隔
+	pruneInterval = time.Hour
+)
+
+// legacyReport report-uri 上报格式（application/csp-report）
+type legacyReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ScriptSample       string `json:"script-sample"`
+		Disposition        string `json:"disposition"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport Reporting API 上报格式（application/reports+json）
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		Sample             string `json:"sample"`
+		Disposition        string `json:"disposition"`
+	} `json:"body"`
+}
+
+var (
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+)
+
+// CSPReportPost 接收浏览器上报的 CSP 违规，兼容 report-uri 和 Reporting API 两种格式
+func CSPReportPost(c *gin.Context) {
+	ip := c.ClientIP()
+	if Redis := dao.GetRedis(); Redis != nil && Redis.IsAvailable() {
+		count, err := Redis.Incr(dao.GenerateKey(reportRatePrefix, ip), time.Minute)
+		if err == nil && count > reportRateLimit {
+			c.Status(http.StatusTooManyRequests)
+			return
+		}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxReportBody))
+	if err != nil {
+		c.Status(http.StatusRequestEntityTooLarge)
+		return
+	}
+	reports := parseReports(body)
+	if len(reports) == 0 {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	userAgent := common.Truncate(c.Request.UserAgent(), 255)
+	for _, report := range reports {
+		report.IP = ip
+		report.UserAgent = userAgent
+		if err = report.Insert(); err != nil {
+			log.Error("Save csp report failed", "err", err)
+			break
+		}
+	}
+	pruneReports()
+	c.Status(http.StatusNoContent)
+}
+
+// parseReports 解析上报内容，无法识别时返回空
+func parseReports(body []byte) []*models.CSPReport {
+	var legacy legacyReport
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Report.DocumentURI != "" {
+		r := legacy.Report
+		directive := r.EffectiveDirective
+		if directive == "" {
+			directive, _, _ = strings.Cut(r.ViolatedDirective, " ")
+		}
+		return []*models.CSPReport{newReport(r.Disposition, r.DocumentURI, r.BlockedURI, directive, r.SourceFile, r.LineNumber, r.ScriptSample)}
+	}
+	var list []reportingAPIReport
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil
+	}
+	reports := make([]*models.CSPReport, 0, len(list))
+	for _, item := range list {
+		if item.Type != "csp-violation" || item.Body.DocumentURL == "" {
+			continue
+		}
+		b := item.Body
+		reports = append(reports, newReport(b.Disposition, b.DocumentURL, b.BlockedURL, b.EffectiveDirective, b.SourceFile, b.LineNumber, b.Sample))
+	}
+	return reports
+}
+
+func newReport(disposition, documentURI, blockedURI, directive, sourceFile string, line int, sample string) *models.CSPReport {
+	return &models.CSPReport{
+		Disposition:       common.Truncate(disposition, 16),
+		DocumentURI:       common.Truncate(documentURI, 512),
+		BlockedURI:        common.Truncate(blockedURI, 512),
+		ViolatedDirective: common.Truncate(directive, 128),
+		SourceFile:        common.Truncate(sourceFile, 512),
+		LineNumber:        line,
+		Sample:            common.Truncate(sample, 255),
+	}
+}
+
+// pruneReports 每小时最多一次删除超过保留天数的报告
+func pruneReports() {
+	pruneMu.Lock()
+	if time.Since(lastPrune) < pruneInterval {
+		pruneMu.Unlock()
+		return
+	}
+	lastPrune = time.Now()
+	pruneMu.Unlock()
+
+	days := config.GetConfiguration().Security.ReportRetention
+	if days <= 0 {
+		days = defaultReportRetention
+	}
+	deleted, err := models.DeleteCSPReportsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Error("Prune csp reports failed", "err", err)
+	} else if deleted > 0 {
+		log.Info("Pruned csp reports", "count", deleted, "retention_days", days)
+	}
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
 )
 
 func ActiveSubscriber(c *gin.Context) {
@@ -27,12 +29,16 @@ func ActiveSubscriber(c *gin.Context) {
 		common.HandleMessage(c, "激活链接已过期，请重新获取！")
 		return
 	}
-	subscriber.VerifyState = true
 	subscriber.OutTime = common.GetCurrentTime()
-	err = subscriber.Update()
+	err = subscriber.Activate()
 	if err != nil {
 		common.HandleMessage(c, fmt.Sprintf("激活失败！%s", err.Error()))
 		return
 	}
+	notify.Notify(notify.EventSubscriber, &notify.Message{
+		Title: "新订阅者",
+		Text:  subscriber.Email,
+		Link:  config.GetConfiguration().Domain + "/admin/subscriber",
+	})
 	common.HandleMessage(c, "激活成功！")
 }
@@ -0,0 +1,36 @@
+package subscribe
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SubscriberView /api/v1 订阅者结构
+type SubscriberView struct {
+	ID             uint       `json:"id" example:"1"`
+	Email          string     `json:"email" example:"reader@example.com"`
+	VerifyState    bool       `json:"verify_state"`    // 是否已激活
+	SubscribeState bool       `json:"subscribe_state"` // 是否处于订阅状态
+	CreatedAt      *time.Time `json:"created_at"`
+}
+
+// SubscriberInput 添加订阅者的请求体，添加后发送激活邮件
+type SubscriberInput struct {
+	Email string `json:"email" binding:"required,email" example:"reader@example.com"`
+}
+
+// SubscriberStateInput 修改订阅状态的请求体
+type SubscriberStateInput struct {
+	SubscribeState *bool `json:"subscribe_state" binding:"required" example:"false"`
+}
+
+func newSubscriberView(subscriber *models.Subscriber) SubscriberView {
+	return SubscriberView{
+		ID:             subscriber.ID,
+		Email:          subscriber.Email,
+		VerifyState:    subscriber.VerifyState,
+		SubscribeState: subscriber.SubscribeState,
+		CreatedAt:      subscriber.CreatedAt,
+	}
+}
@@ -0,0 +1,6 @@
+package subscribe
+
+// NotifySubscribers 向所有已激活的订阅者发送通知邮件
+func NotifySubscribers(subject, body string) error {
+	return sendEmailToSubscribers(subject, body)
+}
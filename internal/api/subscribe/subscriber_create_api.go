@@ -0,0 +1,44 @@
+package subscribe
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// SubscriberCreateAPI 添加订阅者并发送激活邮件，激活后才会收到推送
+// @Summary 添加订阅者
+// @Tags subscribers
+// @Accept json
+// @Produce json
+// @Param subscriber body SubscriberInput true "订阅者"
+// @Success 201 {object} common.APIResponse{data=SubscriberView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 409 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/subscribers [post]
+func SubscriberCreateAPI(c *gin.Context) {
+	var input SubscriberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	if _, err := models.GetSubscriberByEmail(input.Email); err == nil {
+		common.APIFail(c, http.StatusConflict, common.APICodeConflict, "subscriber already exists")
+		return
+	}
+	log.Debug("SubscriberCreateAPI", zap.String("mail", input.Email))
+	subscriber := &models.Subscriber{Email: input.Email}
+	if err := subscriber.Insert(); err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	if err := sendActiveEmail(subscriber); err != nil {
+		log.Warn("send active email failed", "mail", subscriber.Email, "err", err)
+	}
+	common.APICreated(c, newSubscriberView(subscriber))
+}
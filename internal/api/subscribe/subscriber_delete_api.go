@@ -0,0 +1,38 @@
+package subscribe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// SubscriberDeleteAPI 删除订阅者
+// @Summary 删除订阅者
+// @Tags subscribers
+// @Produce json
+// @Param id path int true "订阅者ID"
+// @Success 200 {object} common.APIResponse
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/subscribers/{id} [delete]
+func SubscriberDeleteAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	subscriber, err := models.GetSubscriberById(id)
+	if err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	log.Debug("SubscriberDeleteAPI", zap.Uint("id", id))
+	if err = subscriber.Delete(); err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	common.APIOK(c, nil)
+}
@@ -0,0 +1,31 @@
+package subscribe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SubscriberGetAPI 查询单个订阅者
+// @Summary 订阅者详情
+// @Tags subscribers
+// @Produce json
+// @Param id path int true "订阅者ID"
+// @Success 200 {object} common.APIResponse{data=SubscriberView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/subscribers/{id} [get]
+func SubscriberGetAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	subscriber, err := models.GetSubscriberById(id)
+	if err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	common.APIOK(c, newSubscriberView(subscriber))
+}
@@ -0,0 +1,44 @@
+package subscribe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SubscriberListAPI 分页查询订阅者
+// @Summary 订阅者列表
+// @Tags subscribers
+// @Produce json
+// @Param page query int false "页码，从1开始"
+// @Param page_size query int false "每页数量，最大100"
+// @Param verified query bool false "是否已激活"
+// @Param subscribed query bool false "是否处于订阅状态"
+// @Success 200 {object} common.APIResponse{data=common.APIList{items=[]SubscriberView}}
+// @Failure 400 {object} common.APIResponse
+// @Failure 401 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/subscribers [get]
+func SubscriberListAPI(c *gin.Context) {
+	verified, err := common.QueryBool(c, "verified")
+	if err != nil {
+		common.APIBadRequest(c, "invalid verified")
+		return
+	}
+	subscribed, err := common.QueryBool(c, "subscribed")
+	if err != nil {
+		common.APIBadRequest(c, "invalid subscribed")
+		return
+	}
+	page, pageSize := common.APIPagination(c)
+	subscribers, total, err := models.ListSubscriberByFilter(verified, subscribed, page, pageSize)
+	if err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	items := make([]SubscriberView, 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		items = append(items, newSubscriberView(subscriber))
+	}
+	common.APIOK(c, common.APIList{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
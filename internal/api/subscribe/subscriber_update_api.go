@@ -0,0 +1,46 @@
+package subscribe
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// SubscriberUpdateAPI 修改订阅状态
+// @Summary 修改订阅状态
+// @Tags subscribers
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅者ID"
+// @Param subscriber body SubscriberStateInput true "订阅状态"
+// @Success 200 {object} common.APIResponse{data=SubscriberView}
+// @Failure 400 {object} common.APIResponse
+// @Failure 404 {object} common.APIResponse
+// @Security BearerAuth
+// @Router /api/v1/subscribers/{id} [put]
+func SubscriberUpdateAPI(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.APIBadRequest(c, "invalid id")
+		return
+	}
+	var input SubscriberStateInput
+	if err = c.ShouldBindJSON(&input); err != nil {
+		common.APIBadRequest(c, err.Error())
+		return
+	}
+	subscriber, err := models.GetSubscriberById(id)
+	if err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	log.Debug("SubscriberUpdateAPI", zap.Uint("id", id), zap.Bool("subscribeState", *input.SubscribeState))
+	subscriber.SubscribeState = *input.SubscribeState
+	if err = subscriber.Update(); err != nil {
+		common.APIDBError(c, err, "subscriber")
+		return
+	}
+	common.APIOK(c, newSubscriberView(subscriber))
+}
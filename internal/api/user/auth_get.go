@@ -1,34 +1,42 @@
 package user
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
-	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/oauth"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 )
 
+// AuthGet 跳转到第三方登录授权页面
 func AuthGet(c *gin.Context) {
 	authType := c.Param("authType")
 	log.Debug("AuthGet", zap.String("authType", authType))
 
+	provider, ok := oauth.Get(authType)
+	if !ok {
+		c.Redirect(http.StatusFound, "/signin")
+		return
+	}
+	state := common.UUID()
+	nonce := common.UUID()
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		log.Error("Build oauth authorize url failed", "provider", authType, "err", err)
+		common.HandleMessage(c, "第三方登录暂时不可用，请稍后再试")
+		return
+	}
+
 	session := sessions.Default(c)
-	uuid := common.UUID()
-	session.Delete(common.SessionGithubState)
-	session.Set(common.SessionGithubState, uuid)
+	session.Set(common.SessionOAuthState, state)
+	session.Set(common.SessionOAuthProvider, provider.Name())
+	session.Set(common.SessionOAuthNonce, nonce)
+	session.Set(common.SessionOAuthVerifier, verifier)
 	session.Save()
-
-	cfg := config.GetConfiguration()
-
-	authurl := "/signin"
-	switch authType {
-	case "github":
-		authurl = fmt.Sprintf(cfg.Github.AuthUrl, cfg.Github.ClientId, uuid)
-	default:
-	}
-	c.Redirect(http.StatusFound, authurl)
+	c.Redirect(http.StatusFound, authURL)
 }
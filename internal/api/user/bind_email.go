@@ -22,6 +22,10 @@ func BindEmail(c *gin.Context) {
 		res["message"] = "email have bound"
 		return
 	}
+	if len(email) == 0 {
+		res["message"] = "email cannot be null"
+		return
+	}
 	_, err = models.GetUserByUsername(email)
 	if err == nil {
 		res["message"] = "email have be registered"
@@ -33,4 +37,11 @@ func BindEmail(c *gin.Context) {
 		return
 	}
 	res["succeed"] = true
+	// 绑定成功后发送验证邮件，验证前不接收通知
+	if err = sendEmailLink(user, linkPurposeVerify); err != nil {
+		log.Warn("Send verify email failed", "user_id", user.ID, "err", err)
+		res["message"] = "邮箱已绑定，验证邮件发送失败：" + err.Error()
+		return
+	}
+	res["message"] = "邮箱已绑定，请查收验证邮件"
 }
@@ -0,0 +1,103 @@
+package user
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 邮件链接用途和有效期
+const (
+	linkPurposeReset  = models.LinkPurposeReset
+	linkPurposeVerify = models.LinkPurposeVerify
+
+	resetLinkTTL  = 30 * time.Minute
+	verifyLinkTTL = 24 * time.Hour
+
+	// 同一用户同一用途的邮件发送间隔
+	emailLinkInterval = time.Minute
+	emailLinkPrefix   = "email_link"
+
+	resetPasswordPath = "/password/reset"
+	verifyEmailPath   = "/email/verify"
+)
+
+var errEmailLinkTooFrequent = errors.New("邮件发送过于频繁，请稍后再试")
+
+// sendEmailLink 生成签名链接并通过邮件队列发送，新链接会使之前的同用途链接失效，不影响其他用途的链接
+func sendEmailLink(user *models.User, purpose string) error {
+	if user.Email == "" {
+		return errors.New("email haven't bound")
+	}
+	if !config.GetConfiguration().Smtp.Enabled {
+		return errors.New("邮件服务未启用")
+	}
+	if Redis := dao.GetRedis(); Redis != nil && Redis.IsAvailable() {
+		key := dao.GenerateKey(emailLinkPrefix, fmt.Sprintf("%s:%d", purpose, user.ID))
+		if ok, err := Redis.SetNX(key, time.Now().Unix(), emailLinkInterval); err == nil && !ok {
+			return errEmailLinkTooFrequent
+		}
+	}
+
+	ttl, path := verifyLinkTTL, verifyEmailPath
+	if purpose == linkPurposeReset {
+		ttl, path = resetLinkTTL, resetPasswordPath
+	}
+	link := &common.SignedLink{
+		Purpose: purpose,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Nonce:   common.UUID(),
+		Expires: time.Now().Add(ttl),
+	}
+	if err := user.SetLinkSecret(purpose, link.Nonce, link.Expires); err != nil {
+		return err
+	}
+
+	cfg := config.GetConfiguration()
+	href := html.EscapeString(link.URL(path))
+	var subject, body string
+	if purpose == linkPurposeReset {
+		subject = fmt.Sprintf("[%s]重置密码", cfg.Title)
+		body = fmt.Sprintf("<p>您正在重置 %s 的登录密码，请在 %d 分钟内点击下面的链接设置新密码：</p><p><a href=\"%s\" target=\"_blank\">%s</a></p><p>如果不是您本人操作，请忽略这封邮件。</p>",
+			html.EscapeString(cfg.Title), int(ttl.Minutes()), href, href)
+	} else {
+		subject = fmt.Sprintf("[%s]邮箱验证", cfg.Title)
+		body = fmt.Sprintf("<p>请在 %d 小时内点击下面的链接验证您的邮箱，验证后才能收到评论回复等通知：</p><p><a href=\"%s\" target=\"_blank\">%s</a></p>",
+			int(ttl.Hours()), href, href)
+	}
+	if err := dao.PushEmailTask(user.Email, subject, body); err != nil {
+		log.Error("Push email link failed", "purpose", purpose, "user_id", user.ID, "err", err)
+		return err
+	}
+	log.Info("Email link sent", "purpose", purpose, "user_id", user.ID)
+	return nil
+}
+
+// userFromEmailLink 校验邮件链接的签名、有效期以及是否已使用，返回链接对应的用户
+func userFromEmailLink(c *gin.Context, purpose string) (*models.User, error) {
+	link, sig, err := common.ParseSignedLink(purpose, c.Request.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	user, err := models.GetUser(link.UserID)
+	if err != nil {
+		return nil, common.ErrLinkInvalid
+	}
+	link.Email = user.Email
+	if err = link.Verify(sig); err != nil {
+		return nil, err
+	}
+	if !user.MatchLinkSecret(purpose, link.Nonce) {
+		return nil, common.ErrLinkInvalid
+	}
+	return user, nil
+}
@@ -0,0 +1,22 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+)
+
+// EmailVerify 通过邮件链接验证邮箱
+func EmailVerify(c *gin.Context) {
+	user, err := userFromEmailLink(c, linkPurposeVerify)
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	if err = user.ConfirmEmail(); err != nil {
+		common.HandleMessage(c, "验证失败！"+err.Error())
+		return
+	}
+	log.Info("Email verified", "user_id", user.ID)
+	common.HandleMessage(c, "邮箱验证成功！")
+}
@@ -0,0 +1,27 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// EmailVerifySend 重新发送邮箱验证邮件
+func EmailVerifySend(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	if user.Email == "" {
+		res["message"] = "email haven't bound"
+		return
+	}
+	if user.EmailVerified() {
+		res["message"] = "邮箱已验证"
+		return
+	}
+	if err := sendEmailLink(user, linkPurposeVerify); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// finishSignin 通过第一步验证后完成登录
+// 启用两步验证的用户先进入验证码页面，验证通过后才写入 common.SessionKey
+func finishSignin(c *gin.Context, user *models.User) {
+	s := sessions.Default(c)
+	s.Clear()
+	if user.TotpEnabled {
+		s.Set(common.SessionTwoFactor, user.ID)
+		s.Set(common.SessionTwoFactorAt, time.Now().Unix())
+		s.Save()
+		c.Redirect(http.StatusSeeOther, "/signin/2fa")
+		return
+	}
+	if err := startSession(c, user); err != nil {
+		common.HandleMessage(c, "登录失败，请稍后重试")
+		return
+	}
+	redirectAfterSignin(c, user)
+}
+
+// startSession 写入登录会话，同时在服务端记录设备和IP，便于查看和远程注销
+// 没有服务端记录的会话无法撤销，记录失败时不登录
+func startSession(c *gin.Context, user *models.User) error {
+	sid, _, err := models.CreateUserSession(user.ID, c.ClientIP(), truncateUserAgent(c.Request.UserAgent()))
+	if err != nil {
+		log.Error("Create user session failed", "user_id", user.ID, "err", err)
+		return err
+	}
+	s := sessions.Default(c)
+	s.Clear()
+	s.Set(common.SessionKey, user.ID)
+	s.Set(common.SessionIDKey, sid)
+	s.Save()
+	return nil
+}
+
+// redirectAfterSignin 登录后可进入后台的用户跳转到控制台，其他用户回到首页
+func redirectAfterSignin(c *gin.Context, user *models.User) {
+	if user.Can(models.PermAccessAdmin) {
+		c.Redirect(http.StatusMovedPermanently, "/admin/index")
+	} else {
+		c.Redirect(http.StatusMovedPermanently, "/")
+	}
+}
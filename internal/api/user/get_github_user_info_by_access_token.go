@@ -1,68 +0,0 @@
-package user
-
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-)
-
-func getGithubUserInfoByAccessToken(token string) (*GithubUserInfo, error) {
-	var (
-		resp *http.Response
-		req  *http.Request
-		body []byte
-		err  error
-	)
-	req, err = http.NewRequest("GET", "https://api.github.com/user", nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
-
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	var userInfo GithubUserInfo
-	err = json.Unmarshal(body, &userInfo)
-	return &userInfo, err
-}
-
-type GithubUserInfo struct {
-	AvatarURL         string      `json:"avatar_url"`
-	Bio               interface{} `json:"bio"`
-	Blog              string      `json:"blog"`
-	Company           interface{} `json:"company"`
-	CreatedAt         string      `json:"created_at"`
-	Email             interface{} `json:"email"`
-	EventsURL         string      `json:"events_url"`
-	Followers         int         `json:"followers"`
-	FollowersURL      string      `json:"followers_url"`
-	Following         int         `json:"following"`
-	FollowingURL      string      `json:"following_url"`
-	GistsURL          string      `json:"gists_url"`
-	GravatarID        string      `json:"gravatar_id"`
-	Hireable          interface{} `json:"hireable"`
-	HTMLURL           string      `json:"html_url"`
-	ID                int         `json:"id"`
-	Location          interface{} `json:"location"`
-	Login             string      `json:"login"`
-	Name              interface{} `json:"name"`
-	OrganizationsURL  string      `json:"organizations_url"`
-	PublicGists       int         `json:"public_gists"`
-	PublicRepos       int         `json:"public_repos"`
-	ReceivedEventsURL string      `json:"received_events_url"`
-	ReposURL          string      `json:"repos_url"`
-	SiteAdmin         bool        `json:"site_admin"`
-	StarredURL        string      `json:"starred_url"`
-	SubscriptionsURL  string      `json:"subscriptions_url"`
-	Type              string      `json:"type"`
-	UpdatedAt         string      `json:"updated_at"`
-	URL               string      `json:"url"`
-}
@@ -0,0 +1,222 @@
+package user
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/middleware"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 默认策略：15 分钟内账号失败 10 次或 IP 失败 50 次后锁定 15 分钟，
+// 失败 3 次后需要验证码，失败 5 次后每次响应延迟翻倍，最多 8 秒
+const (
+	defaultLoginMaxFailures   = 10
+	defaultLoginMaxIPFailures = 50
+	defaultLoginWindow        = 900
+	defaultLoginLockout       = 900
+	defaultLoginCaptchaAfter  = 3
+	defaultLoginDelayAfter    = 5
+	defaultLoginMaxDelay      = 8
+
+	loginFailUserPrefix = "login_fail_user"
+	loginFailIPPrefix   = "login_fail_ip"
+	loginLockUserPrefix = "login_lock_user"
+	loginLockIPPrefix   = "login_lock_ip"
+)
+
+type loginPolicy struct {
+	maxFailures   int64
+	maxIPFailures int64
+	window        time.Duration
+	lockout       time.Duration
+	captchaAfter  int64
+	delayAfter    int64
+	maxDelay      time.Duration
+}
+
+func orDefault(value, def int) int {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
+
+func getLoginPolicy() loginPolicy {
+	cfg := config.GetConfiguration().Login
+	return loginPolicy{
+		maxFailures:   int64(orDefault(cfg.MaxFailures, defaultLoginMaxFailures)),
+		maxIPFailures: int64(orDefault(cfg.MaxIPFailures, defaultLoginMaxIPFailures)),
+		window:        time.Duration(orDefault(cfg.Window, defaultLoginWindow)) * time.Second,
+		lockout:       time.Duration(orDefault(cfg.Lockout, defaultLoginLockout)) * time.Second,
+		captchaAfter:  int64(orDefault(cfg.CaptchaAfter, defaultLoginCaptchaAfter)),
+		delayAfter:    int64(orDefault(cfg.DelayAfter, defaultLoginDelayAfter)),
+		maxDelay:      time.Duration(orDefault(cfg.MaxDelay, defaultLoginMaxDelay)) * time.Second,
+	}
+}
+
+// loginAccount 统一账号格式，避免大小写不同绕过计数
+func loginAccount(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func loginRedis() *dao.RedisCacheClient {
+	if Redis := dao.GetRedis(); Redis != nil && Redis.IsAvailable() {
+		return Redis
+	}
+	return nil
+}
+
+// loginFailures 时间窗口内账号和IP的失败次数，Redis 不可用时从审计表统计
+func loginFailures(account, ip string) (byAccount, byIP int64) {
+	if Redis := loginRedis(); Redis != nil {
+		Redis.Get(dao.GenerateKey(loginFailUserPrefix, account), &byAccount)
+		Redis.Get(dao.GenerateKey(loginFailIPPrefix, ip), &byIP)
+		return
+	}
+	policy := getLoginPolicy()
+	byAccount, byIP, err := models.CountLoginFailures(account, ip, time.Now().Add(-policy.window))
+	if err != nil {
+		log.Error("Count login failures failed", "err", err)
+	}
+	return
+}
+
+// loginLockRemaining 账号或IP的临时锁定剩余时间，未锁定时返回 0
+func loginLockRemaining(account, ip string) time.Duration {
+	if Redis := loginRedis(); Redis != nil {
+		var remaining time.Duration
+		for _, key := range []string{dao.GenerateKey(loginLockUserPrefix, account), dao.GenerateKey(loginLockIPPrefix, ip)} {
+			if ttl, err := Redis.TTL(key); err == nil && ttl > remaining {
+				remaining = ttl
+			}
+		}
+		return remaining
+	}
+	// 没有 Redis 时按时间窗口内的失败次数判断，失败次数降到阈值以下时自动解除，
+	// 即最近第 maxFailures 次失败滑出时间窗口的时刻
+	policy := getLoginPolicy()
+	now := time.Now()
+	byAccount, byIP, err := models.RecentLoginFailures(account, ip, now.Add(-policy.window),
+		int(policy.maxFailures), int(policy.maxIPFailures))
+	if err != nil {
+		log.Error("Query login failures failed", "err", err)
+		return 0
+	}
+	var remaining time.Duration
+	if n := len(byAccount); int64(n) >= policy.maxFailures {
+		remaining = byAccount[n-1].Add(policy.window).Sub(now)
+	}
+	if n := len(byIP); int64(n) >= policy.maxIPFailures {
+		remaining = max(remaining, byIP[n-1].Add(policy.window).Sub(now))
+	}
+	return max(remaining, 0)
+}
+
+// needLoginCaptcha 账号或IP的失败次数达到阈值后登录需要验证码
+func needLoginCaptcha(account, ip string) bool {
+	byAccount, byIP := loginFailures(account, ip)
+	return max(byAccount, byIP) >= getLoginPolicy().captchaAfter
+}
+
+// loginDelay 失败次数超过阈值后逐次翻倍的响应延迟，拖慢自动化猜测
+func loginDelay(failures int64) time.Duration {
+	policy := getLoginPolicy()
+	n := failures - policy.delayAfter
+	if n < 0 {
+		return 0
+	}
+	delay := time.Second << uint(min(n, 10))
+	return min(delay, policy.maxDelay)
+}
+
+// recordLoginFailure 记录一次密码错误，达到阈值时临时锁定账号或IP
+func recordLoginFailure(c *gin.Context, account string, userID uint) {
+	ip := c.ClientIP()
+	policy := getLoginPolicy()
+	auditLogin(c, account, userID, models.LoginMethodPassword, false, models.LoginReasonInvalidPassword)
+
+	byAccount, byIP := loginFailures(account, ip)
+	if Redis := loginRedis(); Redis != nil {
+		byAccount, _ = Redis.Incr(dao.GenerateKey(loginFailUserPrefix, account), policy.window)
+		byIP, _ = Redis.Incr(dao.GenerateKey(loginFailIPPrefix, ip), policy.window)
+		if byAccount >= policy.maxFailures {
+			Redis.SetNX(dao.GenerateKey(loginLockUserPrefix, account), time.Now().Unix(), policy.lockout)
+		}
+		if byIP >= policy.maxIPFailures {
+			Redis.SetNX(dao.GenerateKey(loginLockIPPrefix, ip), time.Now().Unix(), policy.lockout)
+		}
+	} else {
+		// 审计记录异步写入，这里补上本次失败
+		byAccount++
+		byIP++
+	}
+	log.Warn("Sign-in failed", "account", account, "user_id", userID, "ip", ip,
+		"account_failures", byAccount, "ip_failures", byIP, "trace_id", middleware.GetTraceID(c))
+	if byAccount >= policy.maxFailures || byIP >= policy.maxIPFailures {
+		log.Warn("Sign-in temporarily locked", "account", account, "ip", ip, "lockout", policy.lockout)
+	}
+}
+
+// recordLoginSuccess 登录成功后清除账号的失败计数，IP 计数保留到窗口结束
+func recordLoginSuccess(c *gin.Context, user *models.User, method, reason string) {
+	if Redis := loginRedis(); Redis != nil && user.Email != "" {
+		Redis.Del(dao.GenerateKey(loginFailUserPrefix, loginAccount(user.Email)))
+	}
+	auditLogin(c, user.Email, user.ID, method, true, reason)
+}
+
+// loginLocked 账号是否处于临时锁定状态，用于后台显示
+func loginLocked(account string) bool {
+	if Redis := loginRedis(); Redis != nil {
+		return Redis.Exists(dao.GenerateKey(loginLockUserPrefix, account))
+	}
+	byAccount, _ := loginFailures(account, "")
+	return byAccount >= getLoginPolicy().maxFailures
+}
+
+// unlockLogin 解除账号的临时锁定并清除失败计数
+// 审计表中的解锁记录同时让数据库统计从此刻重新开始
+func unlockLogin(c *gin.Context, user *models.User) error {
+	account := loginAccount(user.Email)
+	if Redis := loginRedis(); Redis != nil {
+		err := Redis.Del(dao.GenerateKey(loginFailUserPrefix, account), dao.GenerateKey(loginLockUserPrefix, account))
+		if err != nil {
+			return err
+		}
+	}
+	return newLoginAttempt(c, account, user.ID, models.LoginMethodAdmin, true, models.LoginReasonUnlocked).Insert()
+}
+
+// auditLogin 异步写入登录审计记录
+func auditLogin(c *gin.Context, account string, userID uint, method string, success bool, reason string) {
+	attempt := newLoginAttempt(c, account, userID, method, success, reason)
+	go func() {
+		if err := attempt.Insert(); err != nil {
+			log.Error("Save login attempt failed", "err", err)
+		}
+	}()
+}
+
+// newLoginAttempt 当前请求的登录记录
+func newLoginAttempt(c *gin.Context, account string, userID uint, method string, success bool, reason string) *models.LoginAttempt {
+	return &models.LoginAttempt{
+		UserID:    userID,
+		Email:     account,
+		IP:        c.ClientIP(),
+		UserAgent: truncateUserAgent(c.Request.UserAgent()),
+		Method:    method,
+		Success:   success,
+		Reason:    reason,
+	}
+}
+
+// truncateUserAgent User-Agent 按字符截断到数据库字段长度
+func truncateUserAgent(ua string) string {
+	return common.Truncate(ua, 255)
+}
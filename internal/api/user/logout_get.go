@@ -5,11 +5,19 @@ import (
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
 func LogoutGet(c *gin.Context) {
 	log.Debug("LogoutGet")
+	if record, ok := c.Get(common.ContextSessionKey); ok {
+		session := record.(*models.UserSession)
+		if err := models.RevokeUserSession(session.UserID, session.ID); err != nil {
+			log.Warn("Revoke session on logout failed", "session_id", session.ID, "err", err)
+		}
+	}
 	s := sessions.Default(c)
 	s.Clear()
 	s.Save()
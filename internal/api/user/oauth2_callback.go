@@ -1,88 +1,204 @@
 package user
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
-	"github.com/pkg/errors"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/oauth"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// Oauth2Callback 第三方登录回调
+// 已登录时为当前用户绑定第三方账号；未登录时按绑定关系登录，没有绑定的账号自动注册
 func Oauth2Callback(c *gin.Context) {
-	var (
-		userInfo *GithubUserInfo
-		user     *models.User
-	)
 	code := c.Query("code")
 	state := c.Query("state")
-	log.Debug("Oauth2Callback", zap.String("code", code), zap.String("state", state))
+	log.Debug("Oauth2Callback", zap.String("provider", c.Param("provider")), zap.String("state", state))
 
 	session := sessions.Default(c)
-	if len(state) == 0 || state != session.Get(common.SessionGithubState) {
-		c.Abort()
+	expected, _ := session.Get(common.SessionOAuthState).(string)
+	name, _ := session.Get(common.SessionOAuthProvider).(string)
+	nonce, _ := session.Get(common.SessionOAuthNonce).(string)
+	verifier, _ := session.Get(common.SessionOAuthVerifier).(string)
+	session.Delete(common.SessionOAuthState)
+	session.Delete(common.SessionOAuthProvider)
+	session.Delete(common.SessionOAuthNonce)
+	session.Delete(common.SessionOAuthVerifier)
+	session.Save()
+	// 旧版 GitHub 回调地址不带提供方名称
+	if param := c.Param("provider"); len(state) == 0 || state != expected || (param != "" && param != name) {
+		common.HandleMessage(c, "登录已过期，请重新登录")
 		return
 	}
-	session.Delete(common.SessionGithubState)
-	session.Save()
-	token, err := exchangeTokenByCode(code)
+	if reason := c.Query("error"); reason != "" {
+		log.Warn("Oauth authorize denied", "provider", name, "error", reason)
+		c.Redirect(http.StatusFound, "/signin")
+		return
+	}
+	provider, ok := oauth.Get(name)
+	if !ok {
+		c.Redirect(http.StatusFound, "/signin")
+		return
+	}
+	identity, err := provider.Identity(c.Request.Context(), code, nonce, verifier)
 	if err != nil {
-		log.Error("exchangeTokenByCode error", "err", err)
-		c.Redirect(http.StatusMovedPermanently, "/signin")
+		log.Error("Oauth identity error", "provider", name, "err", err)
+		c.Redirect(http.StatusFound, "/signin")
 		return
 	}
 
-	userInfo, err = getGithubUserInfoByAccessToken(token)
-	fmt.Println(userInfo)
+	if sessionUser, exists := c.Get(common.ContextUserKey); exists {
+		bindIdentity(c, sessionUser.(*models.User), identity, provider.DisplayName())
+		return
+	}
+	signinWithIdentity(c, identity)
+}
+
+// findIdentity 查找第三方账号的绑定关系，兼容迁移自旧版 github_login_id 的记录
+func findIdentity(identity *oauth.Identity) (*models.UserIdentity, error) {
+	existing, err := models.GetUserIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if identity.Provider == models.IdentityProviderGithub && identity.Login != "" {
+		if legacy, err := models.GetLegacyGithubIdentity(identity.Login); err == nil {
+			return legacy, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyIdentity 用最新的第三方资料更新绑定记录
+func applyIdentity(record *models.UserIdentity, identity *oauth.Identity) {
+	record.Provider = identity.Provider
+	record.Subject = identity.Subject
+	record.Login = identity.Login
+	record.Email = identity.Email
+	record.Name = identity.Name
+	record.AvatarUrl = identity.AvatarURL
+	record.ProfileUrl = identity.ProfileURL
+}
+
+// syncGithubProfile GitHub 账号同时写入旧版字段，评论列表使用这些字段显示作者链接
+func syncGithubProfile(user *models.User, identity *oauth.Identity) {
+	if identity.Provider != models.IdentityProviderGithub {
+		return
+	}
+	user.GithubLoginId = identity.Login
+	user.GithubUrl = identity.ProfileURL
+	if user.AvatarUrl == "" {
+		user.AvatarUrl = identity.AvatarURL
+	}
+}
+
+// bindIdentity 为已登录用户绑定第三方账号
+func bindIdentity(c *gin.Context, user *models.User, identity *oauth.Identity, displayName string) {
+	existing, err := findIdentity(identity)
 	if err != nil {
-		log.Error("getGithubUserInfoByAccessToken error", "err", err)
-		c.Redirect(http.StatusMovedPermanently, "/signin")
+		common.HandleMessage(c, err.Error())
 		return
 	}
+	if existing != nil && existing.UserID != user.ID {
+		common.HandleMessage(c, "该"+displayName+"账号已绑定其他用户")
+		return
+	}
+	if existing == nil {
+		if _, err = models.GetUserIdentityByUser(user.ID, identity.Provider); err == nil {
+			common.HandleMessage(c, "已绑定其他"+displayName+"账号，请先解绑")
+			return
+		}
+		existing = &models.UserIdentity{UserID: user.ID}
+		applyIdentity(existing, identity)
+		err = existing.Insert()
+	} else {
+		applyIdentity(existing, identity)
+		err = existing.Touch()
+	}
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	if identity.Provider == models.IdentityProviderGithub {
+		syncGithubProfile(user, identity)
+		if err = user.UpdateGithubUserInfo(); err != nil {
+			log.Warn("Update github profile failed", "user_id", user.ID, "err", err)
+		}
+	}
+	log.Info("Oauth identity bound", "user_id", user.ID, "provider", identity.Provider, "subject", identity.Subject)
+	if user.Can(models.PermAccessAdmin) {
+		c.Redirect(http.StatusFound, "/admin/profile")
+		return
+	}
+	c.Redirect(http.StatusFound, "/")
+}
 
-	sessionUser, exists := c.Get(common.ContextUserKey)
-	if exists { // 已登录
-		user = sessionUser.(*models.User)
-		if _, e := models.IsGithubIdExists(userInfo.Login, user.ID); e != nil { // 未绑定
-			if user.IsAdmin {
-				user.GithubLoginId = userInfo.Login
-			}
-			user.AvatarUrl = userInfo.AvatarURL
-			user.GithubUrl = userInfo.HTMLURL
-			err = user.UpdateGithubUserInfo()
-		} else {
-			err = errors.New("this github loginId has bound another account.")
+// signinWithIdentity 使用第三方账号登录，首次登录时创建用户
+func signinWithIdentity(c *gin.Context, identity *oauth.Identity) {
+	account := identity.Provider + ":" + identity.Subject
+	if identity.Login != "" {
+		account = identity.Provider + ":" + identity.Login
+	}
+	method := common.Truncate(identity.Provider, 16)
+
+	existing, err := findIdentity(identity)
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	var user *models.User
+	if existing != nil {
+		if user, err = models.GetUser(existing.UserID); err != nil {
+			common.HandleMessage(c, err.Error())
+			return
+		}
+		applyIdentity(existing, identity)
+		if err = existing.Touch(); err != nil {
+			log.Warn("Update oauth identity failed", "user_id", user.ID, "provider", identity.Provider, "err", err)
 		}
 	} else {
 		user = &models.User{
-			GithubLoginId: userInfo.Login,
-			AvatarUrl:     userInfo.AvatarURL,
-			GithubUrl:     userInfo.HTMLURL,
+			NickName:  identity.Name,
+			AvatarUrl: identity.AvatarURL,
+		}
+		if user.NickName == "" {
+			user.NickName = identity.Login
 		}
-		user, err = user.FirstOrCreate()
-		if err == nil {
-			if user.LockState {
-				err = errors.New("Your account have been locked.")
-				common.HandleMessage(c, err.Error())
-				return
+		// 提供方确认过的邮箱且未被注册时直接使用，已注册的邮箱不自动关联，避免账号被接管
+		if identity.Email != "" && identity.EmailVerified {
+			if _, err := models.GetUserByUsername(identity.Email); err != nil {
+				user.Email = identity.Email
+				user.VerifyState = models.VerifyStateVerified
 			}
 		}
+		syncGithubProfile(user, identity)
+		record := &models.UserIdentity{}
+		applyIdentity(record, identity)
+		if err = models.CreateUserWithIdentity(user, record); err != nil {
+			log.Error("Create oauth user failed", "provider", identity.Provider, "subject", identity.Subject, "err", err)
+			common.HandleMessage(c, err.Error())
+			return
+		}
+		log.Info("Oauth user created", "user_id", user.ID, "provider", identity.Provider)
 	}
 
-	if err == nil {
-		s := sessions.Default(c)
-		s.Clear()
-		s.Set(common.SessionKey, user.ID)
-		s.Save()
-		if user.IsAdmin {
-			c.Redirect(http.StatusMovedPermanently, "/admin/index")
-		} else {
-			c.Redirect(http.StatusMovedPermanently, "/")
-		}
+	if user.LockState {
+		auditLogin(c, account, user.ID, method, false, models.LoginReasonAccountLocked)
+		common.HandleMessage(c, "Your account have been locked.")
 		return
 	}
+	reason := ""
+	if user.TotpEnabled {
+		reason = models.LoginReasonTwoFactor
+	}
+	auditLogin(c, account, user.ID, method, true, reason)
+	finishSignin(c, user)
 }
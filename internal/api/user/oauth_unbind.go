@@ -0,0 +1,40 @@
+package user
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"gorm.io/gorm"
+)
+
+// OAuthUnbind 解绑第三方账号
+// 没有设置密码时至少保留一个第三方账号，否则用户将无法再登录
+func OAuthUnbind(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	sessionUser, _ := c.Get(common.ContextUserKey)
+	user := sessionUser.(*models.User)
+	provider := c.Param("provider")
+
+	identities, err := models.ListUserIdentities(user.ID)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if user.Password == "" && len(identities) <= 1 {
+		res["message"] = "请先设置密码或绑定其他账号后再解绑"
+		return
+	}
+	err = models.DeleteUserIdentity(user.ID, provider)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res["message"] = provider + " haven't bound"
+		return
+	}
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
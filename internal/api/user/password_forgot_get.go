@@ -0,0 +1,15 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// PasswordForgotGet 忘记密码页面
+func PasswordForgotGet(c *gin.Context) {
+	c.HTML(http.StatusOK, "auth/forgot_password.html", gin.H{
+		"cfg": config.GetConfiguration(),
+	})
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dchest/captcha"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// PasswordForgotPost 发送重置密码链接
+// 无论邮箱是否注册都返回相同的提示，避免被用来探测账号
+func PasswordForgotPost(c *gin.Context) {
+	email := strings.TrimSpace(c.PostForm("email"))
+	log.Debug("PasswordForgotPost", zap.String("email", email))
+	if email == "" {
+		c.HTML(http.StatusOK, "auth/forgot_password.html", gin.H{
+			"message": "邮箱不能为空",
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+
+	s := sessions.Default(c)
+	captchaId, _ := s.Get(common.SessionCaptcha).(string)
+	s.Delete(common.SessionCaptcha)
+	s.Save()
+	if captchaId == "" || !captcha.VerifyString(captchaId, c.PostForm("verifyCode")) {
+		c.HTML(http.StatusOK, "auth/forgot_password.html", gin.H{
+			"message": "验证码错误",
+			"email":   email,
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+
+	user, err := models.GetUserByUsername(email)
+	if err == nil && !user.LockState {
+		if err = sendEmailLink(user, linkPurposeReset); err != nil {
+			log.Warn("Send reset password link failed", "user_id", user.ID, "err", err)
+		}
+	}
+	c.HTML(http.StatusOK, "auth/forgot_password.html", gin.H{
+		"message": "如果该邮箱已注册，重置密码的链接已发送，请在 30 分钟内查收",
+		"succeed": true,
+		"cfg":     config.GetConfiguration(),
+	})
+}
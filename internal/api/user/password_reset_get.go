@@ -0,0 +1,22 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// PasswordResetGet 通过邮件链接打开的重置密码页面
+func PasswordResetGet(c *gin.Context) {
+	user, err := userFromEmailLink(c, linkPurposeReset)
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	c.HTML(http.StatusOK, "auth/reset_password.html", gin.H{
+		"email": user.Email,
+		"cfg":   config.GetConfiguration(),
+	})
+}
@@ -0,0 +1,58 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// PasswordResetPost 设置新密码，链接使用后立即失效
+func PasswordResetPost(c *gin.Context) {
+	user, err := userFromEmailLink(c, linkPurposeReset)
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	password := c.PostForm("password")
+	message := ""
+	if len(password) == 0 {
+		message = "密码不能为空"
+	} else if password != c.PostForm("confirm") {
+		message = "两次输入的密码不一致"
+	}
+	if message != "" {
+		c.HTML(http.StatusOK, "auth/reset_password.html", gin.H{
+			"message": message,
+			"email":   user.Email,
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+
+	hashedPassword, err := common.HashPassword(password)
+	if err != nil {
+		common.HandleMessage(c, "密码处理失败")
+		return
+	}
+	if err = user.ResetPassword(hashedPassword); err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	log.Info("Password reset", "user_id", user.ID, "ip", c.ClientIP())
+	// 密码可能已泄露，注销已登录的全部会话
+	if _, err = models.RevokeUserSessions(user.ID); err != nil {
+		log.Error("Revoke sessions after password reset failed", "user_id", user.ID, "err", err)
+	}
+	// 重置成功后清除登录失败计数
+	recordLoginSuccess(c, user, models.LoginMethodReset, "")
+	c.HTML(http.StatusOK, "auth/signin.html", gin.H{
+		"message":  "密码已重置，请使用新密码登录",
+		"succeed":  true,
+		"username": user.Email,
+		"cfg":      config.GetConfiguration(),
+	})
+}
@@ -10,9 +10,30 @@ import (
 )
 
 func ProfileGet(c *gin.Context) {
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	tokens, _ := models.ListAccessTokens(user.ID)
+	// 按提供方名称索引，模板中逐个提供方显示绑定状态
+	identities := make(map[string]*models.UserIdentity)
+	list, _ := models.ListUserIdentities(user.ID)
+	for _, identity := range list {
+		identities[identity.Provider] = identity
+	}
+	userSessions, _ := models.ListUserSessions(user.ID)
+	var currentSession uint
+	if record, ok := c.Get(common.ContextSessionKey); ok {
+		currentSession = record.(*models.UserSession).ID
+	}
 	c.HTML(http.StatusOK, "admin/profile.html", gin.H{
-		"user":     c.MustGet(common.ContextUserKey),
-		"comments": models.MustListUnreadComment(),
-		"cfg":      config.GetConfiguration(),
+		"user":          user,
+		"tokens":        tokens,
+		"identities":    identities,
+		"sessions":      userSessions,
+		"sessionID":     currentSession,
+		"recoveryCodes": models.CountUnusedRecoveryCodes(user.ID),
+		"enforce2fa":    config.GetConfiguration().TwoFactor.EnforceAdmin && user.Role == models.RoleAdmin,
+		"enroll2fa":     c.Query("enroll") == "2fa",
+		"scopes":        []string{models.TokenScopeRead, models.TokenScopeContentWrite, models.TokenScopeAdmin},
+		"comments":      models.MustListUnreadComment(),
+		"cfg":           config.GetConfiguration(),
 	})
 }
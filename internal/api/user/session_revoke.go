@@ -0,0 +1,37 @@
+package user
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// SessionRevoke 注销当前用户的某个登录会话，注销的是当前会话时同时退出登录
+func SessionRevoke(c *gin.Context) {
+	var (
+		err error
+		res = gin.H{}
+	)
+	defer common.WriteJSON(c, res)
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = models.RevokeUserSession(user.ID, id); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("user session revoked", zap.Uint("userId", user.ID), zap.Uint("sessionId", id), zap.String("ip", c.ClientIP()))
+	if current, ok := c.Get(common.ContextSessionKey); ok && current.(*models.UserSession).ID == id {
+		s := sessions.Default(c)
+		s.Clear()
+		s.Save()
+		res["current"] = true
+	}
+	res["succeed"] = true
+}
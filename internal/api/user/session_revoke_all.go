@@ -0,0 +1,27 @@
+package user
+
+import (
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// SessionRevokeAll 注销当前用户在所有设备上的登录会话，包括当前会话
+func SessionRevokeAll(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	revoked, err := models.RevokeUserSessions(user.ID)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("all user sessions revoked", zap.Uint("userId", user.ID), zap.Int64("count", revoked), zap.String("ip", c.ClientIP()))
+	s := sessions.Default(c)
+	s.Clear()
+	s.Save()
+	res["succeed"] = true
+}
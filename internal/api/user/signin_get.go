@@ -9,6 +9,7 @@ import (
 
 func SigninGet(c *gin.Context) {
 	c.HTML(http.StatusOK, "auth/signin.html", gin.H{
-		"cfg": config.GetConfiguration(),
+		"captcha": needLoginCaptcha("", c.ClientIP()),
+		"cfg":     config.GetConfiguration(),
 	})
 }
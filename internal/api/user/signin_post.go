@@ -1,8 +1,12 @@
 package user
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 
+	"github.com/dchest/captcha"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
@@ -21,45 +25,70 @@ func SigninPost(c *gin.Context) {
 	password := c.PostForm("password")
 	log.Debug("SigninPost", zap.String("username", username))
 	if username == "" || password == "" {
-		c.HTML(http.StatusOK, "auth/signin.html", gin.H{
-			"message": "username or password cannot be null",
-			"cfg":     config.GetConfiguration(),
-		})
+		renderSignin(c, http.StatusOK, "username or password cannot be null", username)
 		return
 	}
 
+	// 账号或IP失败次数过多时临时锁定
+	account := loginAccount(username)
+	ip := c.ClientIP()
+	if remaining := loginLockRemaining(account, ip); remaining > 0 {
+		auditLogin(c, account, 0, models.LoginMethodPassword, false, models.LoginReasonRateLimited)
+		minutes := int(math.Ceil(remaining.Minutes()))
+		renderSignin(c, http.StatusTooManyRequests, fmt.Sprintf("登录失败次数过多，请 %d 分钟后再试", minutes), username)
+		return
+	}
+
+	byAccount, byIP := loginFailures(account, ip)
+	failures := max(byAccount, byIP)
+	if failures >= getLoginPolicy().captchaAfter {
+		s := sessions.Default(c)
+		captchaId, _ := s.Get(common.SessionCaptcha).(string)
+		s.Delete(common.SessionCaptcha)
+		s.Save()
+		if captchaId == "" || !captcha.VerifyString(captchaId, c.PostForm("verifyCode")) {
+			auditLogin(c, account, 0, models.LoginMethodPassword, false, models.LoginReasonCaptcha)
+			renderSignin(c, http.StatusOK, "验证码错误", username)
+			return
+		}
+	}
+	if delay := loginDelay(failures); delay > 0 {
+		time.Sleep(delay)
+	}
+
 	// 使用优化的登录查询，利用联合索引
 	user, err = models.GetUserForLogin(username)
 	if err != nil {
-		c.HTML(http.StatusOK, "auth/signin.html", gin.H{
-			"message": "invalid username or password",
-			"cfg":     config.GetConfiguration(),
-		})
+		recordLoginFailure(c, account, 0)
+		renderSignin(c, http.StatusOK, "invalid username or password", username)
 		return
 	}
 
 	// 使用bcrypt验证密码
 	if common.CheckPassword(password, user.Password) != nil {
-		c.HTML(http.StatusOK, "auth/signin.html", gin.H{
-			"message": "invalid username or password",
-			"cfg":     config.GetConfiguration(),
-		})
+		recordLoginFailure(c, account, user.ID)
+		renderSignin(c, http.StatusOK, "invalid username or password", username)
 		return
 	}
 	if user.LockState {
-		c.HTML(http.StatusOK, "auth/signin.html", gin.H{
-			"message": "Your account have been locked",
-			"cfg":     config.GetConfiguration(),
-		})
+		auditLogin(c, account, user.ID, models.LoginMethodPassword, false, models.LoginReasonAccountLocked)
+		renderSignin(c, http.StatusOK, "Your account have been locked", username)
 		return
 	}
-	s := sessions.Default(c)
-	s.Clear()
-	s.Set(common.SessionKey, user.ID)
-	s.Save()
-	if user.IsAdmin {
-		c.Redirect(http.StatusMovedPermanently, "/admin/index")
-	} else {
-		c.Redirect(http.StatusMovedPermanently, "/")
+	reason := ""
+	if user.TotpEnabled {
+		reason = models.LoginReasonTwoFactor
 	}
+	recordLoginSuccess(c, user, models.LoginMethodPassword, reason)
+	finishSignin(c, user)
+}
+
+// renderSignin 重新显示登录页，失败次数达到阈值时要求输入验证码
+func renderSignin(c *gin.Context, status int, message, username string) {
+	c.HTML(status, "auth/signin.html", gin.H{
+		"message":  message,
+		"username": username,
+		"captcha":  needLoginCaptcha(loginAccount(username), c.ClientIP()),
+		"cfg":      config.GetConfiguration(),
+	})
 }
@@ -0,0 +1,19 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// SigninTwoFactorGet 两步验证页面
+func SigninTwoFactorGet(c *gin.Context) {
+	if _, err := pendingTwoFactorUser(c); err != nil {
+		c.Redirect(http.StatusSeeOther, "/signin")
+		return
+	}
+	c.HTML(http.StatusOK, "auth/two_factor.html", gin.H{
+		"cfg": config.GetConfiguration(),
+	})
+}
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// SigninTwoFactorPost 校验两步验证码，通过后写入登录会话
+func SigninTwoFactorPost(c *gin.Context) {
+	user, err := pendingTwoFactorUser(c)
+	if err != nil {
+		c.HTML(http.StatusOK, "auth/signin.html", gin.H{
+			"message": err.Error(),
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+
+	limit, _ := twoFactorLimit()
+	if twoFactorFailures(user) >= int64(limit) {
+		log.Warn("Two-factor verification rate limited", "user_id", user.ID, "ip", c.ClientIP())
+		auditLogin(c, user.Email, user.ID, models.LoginMethodTwoFactor, false, models.LoginReasonRateLimited)
+		c.HTML(http.StatusTooManyRequests, "auth/two_factor.html", gin.H{
+			"message": "验证失败次数过多，请稍后再试",
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+
+	ok, recovery, err := verifyTwoFactorCode(user, c.PostForm("code"))
+	if err != nil {
+		log.Error("Two-factor verification error", "user_id", user.ID, "err", err)
+	}
+	if !ok {
+		recordTwoFactorFailure(c, user, "invalid code")
+		c.HTML(http.StatusOK, "auth/two_factor.html", gin.H{
+			"message": "验证码错误或已使用",
+			"cfg":     config.GetConfiguration(),
+		})
+		return
+	}
+	resetTwoFactorFailures(user)
+	recordLoginSuccess(c, user, models.LoginMethodTwoFactor, "")
+	if recovery {
+		log.Info("Signed in with recovery code", "user_id", user.ID, "remaining", models.CountUnusedRecoveryCodes(user.ID))
+	}
+
+	if err := startSession(c, user); err != nil {
+		common.HandleMessage(c, "登录失败，请稍后重试")
+		return
+	}
+	redirectAfterSignin(c, user)
+}
+
+// pendingTwoFactorUser 读取已通过密码验证、等待两步验证的用户
+func pendingTwoFactorUser(c *gin.Context) (*models.User, error) {
+	s := sessions.Default(c)
+	uID := s.Get(common.SessionTwoFactor)
+	at, _ := s.Get(common.SessionTwoFactorAt).(int64)
+	if uID == nil || time.Since(time.Unix(at, 0)) > twoFactorPendingTimeout {
+		return nil, errors.New("登录已过期，请重新登录")
+	}
+	user, err := models.GetUser(uID)
+	if err != nil || !user.TotpEnabled {
+		return nil, errors.New("登录已过期，请重新登录")
+	}
+	if user.LockState {
+		return nil, errors.New("Your account have been locked")
+	}
+	return user, nil
+}
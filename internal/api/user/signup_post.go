@@ -73,11 +73,16 @@ func SignupPost(c *gin.Context) {
 		return
 	}
 
+	// 第一个注册的用户成为管理员，之后注册的用户默认为读者，由管理员分配角色
+	role := models.RoleReader
+	if count, err := models.CountUsers(); err == nil && count == 0 {
+		role = models.RoleAdmin
+	}
 	user := &models.User{
 		Email:     email,
 		Telephone: telephone,
 		Password:  hashedPassword,
-		IsAdmin:   true,
+		Role:      role,
 	}
 	err = user.Insert()
 	if err != nil {
@@ -89,5 +94,9 @@ func SignupPost(c *gin.Context) {
 		})
 		return
 	}
+	// 发送邮箱验证邮件，未验证不影响登录，只是不接收通知
+	if err = sendEmailLink(user, linkPurposeVerify); err != nil {
+		log.Warn("Send verify email failed", "user_id", user.ID, "err", err)
+	}
 	c.Redirect(http.StatusMovedPermanently, "/signin")
 }
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// TokenCreate 创建个人访问令牌，明文令牌只在本次响应中返回
+func TokenCreate(c *gin.Context) {
+	var (
+		err error
+		res = gin.H{}
+	)
+	defer common.WriteJSON(c, res)
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	name := strings.TrimSpace(c.PostForm("name"))
+	scope := c.PostForm("scope")
+	if name == "" || len(name) > 64 {
+		res["message"] = "token name is required and must be at most 64 characters."
+		return
+	}
+	if !models.IsValidTokenScope(scope) {
+		res["message"] = "invalid scope."
+		return
+	}
+	plain, token, err := models.CreateAccessToken(user.ID, name, scope)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("access token created", zap.Uint("userId", user.ID), zap.Uint("tokenId", token.ID), zap.String("scope", scope), zap.String("ip", c.ClientIP()))
+	res["token"] = plain
+	res["succeed"] = true
+}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// TokenRevoke 撤销当前用户的访问令牌
+func TokenRevoke(c *gin.Context) {
+	var (
+		err error
+		res = gin.H{}
+	)
+	defer common.WriteJSON(c, res)
+	user := c.MustGet(common.ContextUserKey).(*models.User)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = models.RevokeAccessToken(user.ID, id); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("access token revoked", zap.Uint("userId", user.ID), zap.Uint("tokenId", id), zap.String("ip", c.ClientIP()))
+	res["succeed"] = true
+}
@@ -0,0 +1,136 @@
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/middleware"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 默认限流：5 分钟内最多失败 5 次
+const (
+	defaultTwoFactorMaxAttempts   = 5
+	defaultTwoFactorAttemptWindow = 300
+
+	// 通过密码验证后需在该时间内完成两步验证
+	twoFactorPendingTimeout = 5 * time.Minute
+
+	twoFactorFailPrefix = "2fa_fail"
+)
+
+// twoFactorIssuer 验证器应用中显示的发行方
+func twoFactorIssuer() string {
+	cfg := config.GetConfiguration()
+	if cfg.TwoFactor.Issuer != "" {
+		return cfg.TwoFactor.Issuer
+	}
+	if cfg.Title != "" {
+		return cfg.Title
+	}
+	return "bmtdblog"
+}
+
+func twoFactorLimit() (int, time.Duration) {
+	cfg := config.GetConfiguration().TwoFactor
+	limit := cfg.MaxAttempts
+	if limit <= 0 {
+		limit = defaultTwoFactorMaxAttempts
+	}
+	window := cfg.AttemptWindow
+	if window <= 0 {
+		window = defaultTwoFactorAttemptWindow
+	}
+	return limit, time.Duration(window) * time.Second
+}
+
+// twoFactorFailures 当前时间窗口内的验证失败次数，Redis 不可用时从审计表统计，
+// 计数保存在服务端，不受客户端会话影响
+func twoFactorFailures(user *models.User) int64 {
+	var count int64
+	if Redis := loginRedis(); Redis != nil {
+		Redis.Get(dao.GenerateKey(twoFactorFailPrefix, user.ID), &count)
+		return count
+	}
+	_, window := twoFactorLimit()
+	since := time.Now().Add(-window)
+	if user.TotpResetAt != nil && user.TotpResetAt.After(since) {
+		since = *user.TotpResetAt
+	}
+	count, err := models.CountTwoFactorFailures(user.ID, since)
+	if err != nil {
+		log.Error("Count two-factor failures failed", "user_id", user.ID, "err", err)
+	}
+	return count
+}
+
+// recordTwoFactorFailure 记录一次验证失败并写入审计表
+func recordTwoFactorFailure(c *gin.Context, user *models.User, reason string) {
+	var count int64
+	if Redis := loginRedis(); Redis != nil {
+		_, window := twoFactorLimit()
+		count, _ = Redis.Incr(dao.GenerateKey(twoFactorFailPrefix, user.ID), window)
+		auditLogin(c, user.Email, user.ID, models.LoginMethodTwoFactor, false, models.LoginReasonInvalidCode)
+	} else {
+		// 审计记录即失败计数，同步写入，避免并发请求在写入前通过检查
+		attempt := newLoginAttempt(c, user.Email, user.ID, models.LoginMethodTwoFactor, false, models.LoginReasonInvalidCode)
+		if err := attempt.Insert(); err != nil {
+			log.Error("Save login attempt failed", "err", err)
+		}
+		count = twoFactorFailures(user)
+	}
+	log.Warn("Two-factor verification failed",
+		"user_id", user.ID, "ip", c.ClientIP(), "reason", reason, "failures", count,
+		"trace_id", middleware.GetTraceID(c))
+}
+
+// resetTwoFactorFailures 验证成功后清除失败计数
+func resetTwoFactorFailures(user *models.User) {
+	if Redis := loginRedis(); Redis != nil {
+		Redis.Del(dao.GenerateKey(twoFactorFailPrefix, user.ID))
+		return
+	}
+	if err := user.ResetTOTPFailures(); err != nil {
+		log.Error("Reset two-factor failures failed", "user_id", user.ID, "err", err)
+	}
+}
+
+// twoFactorSessionUser 两步验证设置只允许通过会话登录的用户修改，访问令牌无权操作
+func twoFactorSessionUser(c *gin.Context) (*models.User, bool) {
+	if _, ok := c.Get(common.ContextTokenKey); ok {
+		return nil, false
+	}
+	return c.MustGet(common.ContextUserKey).(*models.User), true
+}
+
+// checkTwoFactorCode 修改两步验证设置前校验验证码或恢复码，失败次数与登录共用限流
+func checkTwoFactorCode(c *gin.Context, user *models.User) string {
+	limit, _ := twoFactorLimit()
+	if twoFactorFailures(user) >= int64(limit) {
+		return "验证失败次数过多，请稍后再试"
+	}
+	ok, _, err := verifyTwoFactorCode(user, c.PostForm("code"))
+	if err != nil {
+		log.Error("Two-factor verification error", "user_id", user.ID, "err", err)
+	}
+	if !ok {
+		recordTwoFactorFailure(c, user, "invalid code")
+		return "验证码错误或已使用"
+	}
+	resetTwoFactorFailures(user)
+	return ""
+}
+
+// verifyTwoFactorCode 校验验证器中的验证码或一次性恢复码
+func verifyTwoFactorCode(user *models.User, code string) (ok bool, recovery bool, err error) {
+	if step, valid := common.ValidateTOTP(user.TotpSecret, code, time.Now()); valid {
+		ok, err = user.UseTOTPStep(step)
+		return ok, false, err
+	}
+	ok, err = models.UseRecoveryCode(user.ID, code)
+	return ok, ok, err
+}
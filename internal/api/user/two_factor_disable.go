@@ -0,0 +1,38 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TwoFactorDisable 校验验证码或恢复码后关闭两步验证
+func TwoFactorDisable(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user, ok := twoFactorSessionUser(c)
+	if !ok {
+		res["message"] = "access tokens cannot change two-factor settings."
+		return
+	}
+	if !user.TotpEnabled {
+		res["message"] = "两步验证未启用"
+		return
+	}
+	if config.GetConfiguration().TwoFactor.EnforceAdmin && user.Role == models.RoleAdmin {
+		res["message"] = "站点要求管理员必须启用两步验证"
+		return
+	}
+	if message := checkTwoFactorCode(c, user); message != "" {
+		res["message"] = message
+		return
+	}
+	if err := user.DisableTOTP(); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Two-factor disabled", "user_id", user.ID, "ip", c.ClientIP())
+	res["succeed"] = true
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TwoFactorEnable 校验验证器生成的验证码后启用两步验证，并返回一次性恢复码
+func TwoFactorEnable(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user, ok := twoFactorSessionUser(c)
+	if !ok {
+		res["message"] = "access tokens cannot change two-factor settings."
+		return
+	}
+	if user.TotpEnabled {
+		res["message"] = "两步验证已启用"
+		return
+	}
+	if user.TotpSecret == "" {
+		res["message"] = "请先生成二维码"
+		return
+	}
+	step, valid := common.ValidateTOTP(user.TotpSecret, c.PostForm("code"), time.Now())
+	if !valid {
+		recordTwoFactorFailure(c, user, "invalid enrollment code")
+		res["message"] = "验证码错误，请确认手机时间准确后重试"
+		return
+	}
+	if err := user.EnableTOTP(step); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	codes, err := models.GenerateRecoveryCodes(user.ID)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Two-factor enabled", "user_id", user.ID, "ip", c.ClientIP())
+	res["codes"] = codes
+	res["succeed"] = true
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// TwoFactorRecoveryCodes 校验验证码后重新生成恢复码，原有恢复码全部作废
+func TwoFactorRecoveryCodes(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user, ok := twoFactorSessionUser(c)
+	if !ok {
+		res["message"] = "access tokens cannot change two-factor settings."
+		return
+	}
+	if !user.TotpEnabled {
+		res["message"] = "两步验证未启用"
+		return
+	}
+	if message := checkTwoFactorCode(c, user); message != "" {
+		res["message"] = message
+		return
+	}
+	codes, err := models.GenerateRecoveryCodes(user.ID)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Two-factor recovery codes regenerated", "user_id", user.ID, "ip", c.ClientIP())
+	res["codes"] = codes
+	res["succeed"] = true
+}
@@ -0,0 +1,48 @@
+package user
+
+import (
+	"encoding/base64"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+)
+
+// TwoFactorSetup 生成新的两步验证密钥和二维码，用户在验证器中添加后需调用 TwoFactorEnable 确认
+func TwoFactorSetup(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	user, ok := twoFactorSessionUser(c)
+	if !ok {
+		res["message"] = "access tokens cannot change two-factor settings."
+		return
+	}
+	if user.TotpEnabled {
+		res["message"] = "两步验证已启用，请先关闭后再重新绑定"
+		return
+	}
+	secret, err := common.GenerateTOTPSecret()
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	account := user.Email
+	if account == "" {
+		account = user.GithubLoginId
+	}
+	uri := common.TOTPURI(twoFactorIssuer(), account, secret)
+	png, err := common.QRCodePNG(uri, 4)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = user.SetTOTPSecret(secret); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Two-factor setup started", "user_id", user.ID, "ip", c.ClientIP())
+	res["secret"] = secret
+	res["uri"] = uri
+	res["qrcode"] = "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	res["succeed"] = true
+}
@@ -1,28 +0,0 @@
-package user
-
-import (
-	"github.com/gin-gonic/gin"
-	"github.com/xiuivfbc/bmtdblog/internal/common"
-	"github.com/xiuivfbc/bmtdblog/internal/models"
-)
-
-func UnbindGithub(c *gin.Context) {
-	var (
-		err error
-		res = gin.H{}
-	)
-	defer common.WriteJSON(c, res)
-	sessionUser, _ := c.Get(common.ContextUserKey)
-	user := sessionUser.(*models.User)
-	if user.GithubLoginId == "" {
-		res["message"] = "github haven't bound"
-		return
-	}
-	user.GithubLoginId = ""
-	err = user.UpdateGithubUserInfo()
-	if err != nil {
-		res["message"] = err.Error()
-		return
-	}
-	res["succeed"] = true
-}
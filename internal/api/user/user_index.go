@@ -5,16 +5,40 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
+// 用户列表页显示的最近登录记录条数
+const loginAttemptsLimit = 50
+
 func UserIndex(c *gin.Context) {
 	users, _ := models.ListUsers()
+	// 登录失败导致临时锁定的用户
+	loginLocks := make(map[uint]bool)
+	for _, u := range users {
+		if u.Email != "" && loginLocked(loginAccount(u.Email)) {
+			loginLocks[u.ID] = true
+		}
+	}
+	attempts, err := models.ListLoginAttempts(0, loginAttemptsLimit)
+	if err != nil {
+		log.Error("List login attempts failed", "err", err)
+	}
+	identities, err := models.ListIdentitiesByUser()
+	if err != nil {
+		log.Error("List user identities failed", "err", err)
+	}
 	c.HTML(http.StatusOK, "admin/user.html", gin.H{
-		"users":    users,
-		"user":     c.MustGet(common.ContextUserKey),
-		"comments": models.MustListUnreadComment(),
-		"cfg":      config.GetConfiguration(),
+		"users":      users,
+		"identities": identities,
+		"roles":      models.Roles,
+		"roleNames":  models.RoleNames,
+		"loginLocks": loginLocks,
+		"attempts":   attempts,
+		"user":       c.MustGet(common.ContextUserKey),
+		"comments":   models.MustListUnreadComment(),
+		"cfg":        config.GetConfiguration(),
 	})
 }
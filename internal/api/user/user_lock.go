@@ -22,6 +22,10 @@ func UserLock(c *gin.Context) {
 		return
 	}
 	log.Debug("UserLock", zap.Uint("id", id))
+	if current := c.MustGet(common.ContextUserKey).(*models.User); current.ID == id {
+		res["message"] = "不能锁定自己"
+		return
+	}
 	user, err = models.GetUser(id)
 	if err != nil {
 		res["message"] = err.Error()
@@ -33,5 +37,14 @@ func UserLock(c *gin.Context) {
 		res["message"] = err.Error()
 		return
 	}
+	// 锁定后立即注销该用户的全部会话
+	if user.LockState {
+		revoked, err := models.RevokeUserSessions(user.ID)
+		if err != nil {
+			log.Error("Revoke sessions of locked user failed", "user_id", user.ID, "err", err)
+		} else {
+			log.Info("Sessions of locked user revoked", "user_id", user.ID, "count", revoked)
+		}
+	}
 	res["succeed"] = true
 }
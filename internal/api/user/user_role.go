@@ -0,0 +1,46 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// UserRoleUpdate 修改用户角色
+func UserRoleUpdate(c *gin.Context) {
+	var (
+		err  error
+		id   uint
+		res  = gin.H{}
+		user *models.User
+	)
+	defer common.WriteJSON(c, res)
+	id, err = common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	role := c.PostForm("role")
+	if !models.IsValidRole(role) {
+		res["message"] = "invalid role"
+		return
+	}
+	current := c.MustGet(common.ContextUserKey).(*models.User)
+	if current.ID == id {
+		res["message"] = "不能修改自己的角色"
+		return
+	}
+	user, err = models.GetUser(id)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("UserRoleUpdate", zap.Uint("id", id), zap.String("from", user.Role), zap.String("to", role), zap.Uint("operator", current.ID))
+	if err = user.UpdateRole(role); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
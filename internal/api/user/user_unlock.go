@@ -0,0 +1,37 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"go.uber.org/zap"
+)
+
+// UserUnlock 解除登录失败导致的临时锁定
+func UserUnlock(c *gin.Context) {
+	var (
+		err  error
+		id   uint
+		res  = gin.H{}
+		user *models.User
+	)
+	defer common.WriteJSON(c, res)
+	id, err = common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	user, err = models.GetUser(id)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	current := c.MustGet(common.ContextUserKey).(*models.User)
+	log.Info("UserUnlock", zap.Uint("id", id), zap.String("email", user.Email), zap.Uint("operator", current.ID))
+	if err = unlockLogin(c, user); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookCreate 新建 webhook
+func WebhookCreate(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	webhook := &models.Webhook{}
+	if err := bindWebhook(c, webhook); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err := webhook.Insert(); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Webhook created", "id", webhook.ID, "name", webhook.Name, "ip", c.ClientIP())
+	res["succeed"] = true
+	res["id"] = webhook.ID
+}
@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookDelete 删除 webhook 及其投递记录
+func WebhookDelete(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	webhook := &models.Webhook{ID: id}
+	if err = webhook.Delete(); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Webhook deleted", "id", id, "ip", c.ClientIP())
+	res["succeed"] = true
+}
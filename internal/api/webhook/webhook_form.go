@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 投递记录页面显示的条数
+const deliveryLimit = 50
+
+// bindWebhook 读取表单中的 webhook 配置，密钥为空时保留原密钥，新建时自动生成
+func bindWebhook(c *gin.Context, webhook *models.Webhook) error {
+	webhook.Name = strings.TrimSpace(c.PostForm("name"))
+	webhook.URL = strings.TrimSpace(c.PostForm("url"))
+	webhook.Enabled = c.PostForm("enabled") != ""
+	if webhook.Name == "" {
+		return errors.New("名称不能为空")
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("地址必须是 http 或 https 地址")
+	}
+
+	var events []string
+	for _, event := range c.PostFormArray("events") {
+		if !models.IsValidWebhookEvent(event) {
+			return errors.New("未知的事件: " + event)
+		}
+		events = append(events, event)
+	}
+	if len(events) == 0 {
+		return errors.New("至少选择一个事件")
+	}
+	webhook.Events = strings.Join(events, ",")
+
+	if secret := strings.TrimSpace(c.PostForm("secret")); secret != "" {
+		webhook.Secret = secret
+	}
+	if webhook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+	return nil
+}
+
+// newSecret 生成随机的签名密钥
+func newSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
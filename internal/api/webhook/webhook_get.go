@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookGet webhook 详情和最近的投递记录
+func WebhookGet(c *gin.Context) {
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		common.HandleMessage(c, err.Error())
+		return
+	}
+	webhook, err := models.GetWebhook(id)
+	if err != nil {
+		common.Handle404(c)
+		return
+	}
+	deliveries, err := models.ListWebhookDeliveries(id, deliveryLimit)
+	if err != nil {
+		log.Error("List webhook deliveries failed", "id", id, "err", err)
+	}
+	c.HTML(http.StatusOK, "admin/webhook_detail.html", gin.H{
+		"webhook":    webhook,
+		"deliveries": deliveries,
+		"events":     models.WebhookEvents,
+		"user":       c.MustGet(common.ContextUserKey),
+		"comments":   models.MustListUnreadComment(),
+		"cfg":        config.GetConfiguration(),
+	})
+}
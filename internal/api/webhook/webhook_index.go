@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookIndex webhook 列表及各自的投递统计
+func WebhookIndex(c *gin.Context) {
+	webhooks, err := models.ListWebhooks()
+	if err != nil {
+		log.Error("List webhooks failed", "err", err)
+	}
+	stats, err := models.CountWebhookDeliveries()
+	if err != nil {
+		log.Error("Count webhook deliveries failed", "err", err)
+	}
+	c.HTML(http.StatusOK, "admin/webhook.html", gin.H{
+		"webhooks": webhooks,
+		"stats":    stats,
+		"events":   models.WebhookEvents,
+		"user":     c.MustGet(common.ContextUserKey),
+		"comments": models.MustListUnreadComment(),
+		"cfg":      config.GetConfiguration(),
+	})
+}
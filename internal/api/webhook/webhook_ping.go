@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookPing 发送一次测试请求，结果显示在投递记录中
+func WebhookPing(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	webhook, err := models.GetWebhook(id)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if _, err = models.EnqueueWebhookPing(webhook); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	res["succeed"] = true
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookRedeliver 以相同的请求正文重新投递
+func WebhookRedeliver(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	deliveryID, err := common.ParamUint(c, "did")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	delivery, err := models.GetWebhookDelivery(deliveryID)
+	if err != nil || delivery.WebhookID != id {
+		res["message"] = "投递记录不存在"
+		return
+	}
+	redelivery, err := delivery.Redeliver()
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Webhook redelivered", "id", id, "delivery", deliveryID, "redelivery", redelivery.ID, "ip", c.ClientIP())
+	res["succeed"] = true
+}
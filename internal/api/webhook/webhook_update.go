@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// WebhookUpdate 修改 webhook，密钥留空时不修改
+func WebhookUpdate(c *gin.Context) {
+	res := gin.H{}
+	defer common.WriteJSON(c, res)
+	id, err := common.ParamUint(c, "id")
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	webhook, err := models.GetWebhook(id)
+	if err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = bindWebhook(c, webhook); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	if err = webhook.Update(); err != nil {
+		res["message"] = err.Error()
+		return
+	}
+	log.Info("Webhook updated", "id", webhook.ID, "name", webhook.Name, "ip", c.ClientIP())
+	res["succeed"] = true
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"gorm.io/gorm"
+)
+
+// /api/v1 错误码
+const (
+	APICodeInvalidArgument = "invalid_argument"
+	APICodeUnauthorized    = "unauthorized"
+	APICodeForbidden       = "forbidden"
+	APICodeNotFound        = "not_found"
+	APICodeConflict        = "conflict"
+	APICodeInternal        = "internal_error"
+)
+
+// 分页大小上限
+const apiMaxPageSize = 100
+
+// APIResponse /api/v1 统一响应结构，成功时携带 data，失败时携带 error
+type APIResponse struct {
+	Succeed bool        `json:"succeed"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   *APIError   `json:"error,omitempty"`
+}
+
+// APIError 错误信息
+type APIError struct {
+	Code    string `json:"code" example:"not_found"`
+	Message string `json:"message" example:"post not found"`
+}
+
+// APIList 分页列表
+type APIList struct {
+	Items    interface{} `json:"items"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// APIOK 返回成功响应
+func APIOK(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, APIResponse{Succeed: true, Data: data})
+}
+
+// APICreated 返回资源创建成功响应
+func APICreated(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusCreated, APIResponse{Succeed: true, Data: data})
+}
+
+// APIFail 返回错误响应并终止后续处理
+func APIFail(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, APIResponse{Error: &APIError{Code: code, Message: message}})
+}
+
+// APIDBError 根据数据库错误返回 404 或 500
+// 500 时错误详情只写入日志，响应中返回 trace_id 便于对照日志排查
+func APIDBError(c *gin.Context, err error, resource string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		APIFail(c, http.StatusNotFound, APICodeNotFound, resource+" not found")
+		return
+	}
+	// 日志会自动带上当前请求的 trace_id
+	log.Error("api database error", "uri", c.Request.RequestURI, "err", err)
+	traceID := c.GetString(string(log.TraceIDKey))
+	message := "internal server error"
+	if traceID != "" && traceID != "NULL" {
+		message += ", trace_id: " + traceID
+	}
+	APIFail(c, http.StatusInternalServerError, APICodeInternal, message)
+}
+
+// APIBadRequest 返回参数错误响应
+func APIBadRequest(c *gin.Context, message string) {
+	APIFail(c, http.StatusBadRequest, APICodeInvalidArgument, message)
+}
+
+// APIPagination 解析 page、page_size 查询参数
+func APIPagination(c *gin.Context) (page, pageSize int) {
+	page, _ = strconv.Atoi(c.Query("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize, _ = strconv.Atoi(c.Query("page_size"))
+	if pageSize <= 0 {
+		pageSize = config.GetConfiguration().PageSize
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > apiMaxPageSize {
+		pageSize = apiMaxPageSize
+	}
+	return
+}
+
+// QueryBool 解析可选的布尔查询参数，未提供时返回 nil
+func QueryBool(c *gin.Context, key string) (*bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
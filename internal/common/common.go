@@ -2,16 +2,13 @@ package common
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"os"
 	"path"
 	"strconv"
-	"strings"
 
 	"github.com/denisbakhtin/sitemap"
 	"github.com/gin-gonic/gin"
-	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
@@ -19,10 +16,22 @@ import (
 )
 
 const (
-	SessionKey         = "UserID"       // session key
-	ContextUserKey     = "User"         // context user key
-	SessionGithubState = "GITHUB_STATE" // GitHub state session key
-	SessionCaptcha     = "GIN_CAPTCHA"  // captcha session key
+	SessionCookieName    = "gin-session"    // 会话 cookie 名
+	SessionKey           = "UserID"         // session key
+	ContextUserKey       = "User"           // context user key
+	ContextTokenKey      = "AccessToken"    // 通过访问令牌认证时的令牌
+	SessionIDKey         = "SESSION_ID"     // 服务端会话ID，撤销后登录状态立即失效
+	ContextSessionKey    = "UserSession"    // 当前请求对应的服务端会话
+	ContextCSPNonce      = "CSPNonce"       // 当前请求的 CSP nonce
+	SessionOAuthState    = "OAUTH_STATE"    // 第三方登录 state，防止 CSRF
+	SessionOAuthProvider = "OAUTH_PROVIDER" // 发起授权的提供方
+	SessionOAuthNonce    = "OAUTH_NONCE"    // OIDC nonce，防止 ID Token 重放
+	SessionOAuthVerifier = "OAUTH_VERIFIER" // PKCE code_verifier
+	SessionCaptcha       = "GIN_CAPTCHA"    // captcha session key
+	SessionCSRF          = "CSRF_TOKEN"     // 防止跨站请求伪造的令牌
+	SessionTwoFactor     = "2FA_USER"       // 已通过密码验证、等待两步验证的用户ID
+	SessionTwoFactorAt   = "2FA_AT"         // 通过密码验证的时间（unix秒）
+	SessionViewedPosts   = "VIEWED_POSTS"   // 本次会话最近浏览的博文，用于统计共同浏览
 )
 
 func Handle404(c *gin.Context) {
@@ -38,42 +47,14 @@ func HandleMessage(c *gin.Context, message string) {
 	})
 }
 
-func sendMail(to, subject, body string) error {
+func CreateXMLSitemap() (err error) {
 	cfg := config.GetConfiguration()
-	if !cfg.Smtp.Enabled {
-		return nil
-	}
-
-	// 尝试使用邮件队列异步发送
-	if err := dao.PushEmailTask(to, subject, body); err != nil {
-		// 队列失败时降级到同步发送
-		log.Warn("邮件队列发送失败，降级到同步发送", "err", err)
-		return SendToMail(cfg.Smtp.Username, cfg.Smtp.Password, cfg.Smtp.Host, to, subject, body, "html")
-	}
-
-	return nil
+	return WriteXMLSitemap(path.Join(GetCurrentDirectory(), cfg.PublicDir, "sitemap"))
 }
 
-func NotifyEmail(subject, body string) error {
-	notifyEmailsStr := config.GetConfiguration().NotifyEmails
-	if notifyEmailsStr != "" {
-		notifyEmails := strings.Split(notifyEmailsStr, ";")
-		emails := make([]string, 0)
-		for _, email := range notifyEmails {
-			if email != "" {
-				emails = append(emails, email)
-			}
-		}
-		if len(emails) > 0 {
-			return sendMail(strings.Join(emails, ";"), subject, body)
-		}
-	}
-	return nil
-}
-
-func CreateXMLSitemap() (err error) {
+// WriteXMLSitemap 生成站点地图到指定目录
+func WriteXMLSitemap(folder string) (err error) {
 	cfg := config.GetConfiguration()
-	folder := path.Join(GetCurrentDirectory(), cfg.PublicDir, "sitemap")
 	err = os.MkdirAll(folder, os.ModePerm)
 	if err != nil {
 		log.Error("create folder error", "err", err)
@@ -97,7 +78,7 @@ func CreateXMLSitemap() (err error) {
 	}
 	for _, post := range posts {
 		items = append(items, sitemap.Item{
-			Loc:        fmt.Sprintf("%s/post/%d", domain, post.ID),
+			Loc:        domain + post.URL(),
 			LastMod:    *post.UpdatedAt,
 			Changefreq: "weekly",
 			Priority:   0.9,
@@ -111,7 +92,7 @@ func CreateXMLSitemap() (err error) {
 	}
 	for _, page := range pages {
 		items = append(items, sitemap.Item{
-			Loc:        fmt.Sprintf("%s/page/%d", domain, page.ID),
+			Loc:        domain + page.URL(),
 			LastMod:    *page.UpdatedAt,
 			Changefreq: "monthly",
 			Priority:   0.8,
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"strings"
+)
+
+// 差异行类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine 行级差异结果
+type DiffLine struct {
+	Type  string // equal, insert, delete
+	Text  string
+	OldNo int // 旧文本行号，插入行为0
+	NewNo int // 新文本行号，删除行为0
+}
+
+// LineDiff 基于最长公共子序列计算两段文本的行级差异
+func LineDiff(oldText, newText string) []DiffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	n, m := len(oldLines), len(newLines)
+
+	// lcs[i][j] 表示 oldLines[i:] 与 newLines[j:] 的最长公共子序列长度
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := make([]DiffLine, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			result = append(result, DiffLine{Type: DiffEqual, Text: oldLines[i], OldNo: i + 1, NewNo: j + 1})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, DiffLine{Type: DiffDelete, Text: oldLines[i], OldNo: i + 1})
+			i++
+		default:
+			result = append(result, DiffLine{Type: DiffInsert, Text: newLines[j], NewNo: j + 1})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		result = append(result, DiffLine{Type: DiffDelete, Text: oldLines[i], OldNo: i + 1})
+	}
+	for ; j < m; j++ {
+		result = append(result, DiffLine{Type: DiffInsert, Text: newLines[j], NewNo: j + 1})
+	}
+	return result
+}
+
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
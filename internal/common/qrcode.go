@@ -0,0 +1,18 @@
+package common
+
+import (
+	"github.com/skip2/go-qrcode"
+)
+
+// QRCodePNG 将文本编码为二维码 PNG 图片（M 级纠错），scale 为每个模块的像素数
+func QRCodePNG(text string, scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	qr, err := qrcode.New(text, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	// 尺寸为负数时表示每个模块的像素数，图片大小随版本变化
+	return qr.PNG(-scale)
+}
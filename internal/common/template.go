@@ -12,6 +12,11 @@ func DateFormat(date time.Time, layout string) string {
 	return date.Format(layout)
 }
 
+// DatetimeLocal 将时间格式化为本地时区的 datetime-local 表单值
+func DatetimeLocal(date time.Time) string {
+	return date.In(GetCurrentTime().Location()).Format("2006-01-02T15:04")
+}
+
 // Substring 截取字符串
 func Substring(source string, start, end int) string {
 	rs := []rune(source)
@@ -61,6 +61,11 @@ func GetCurrentTime() time.Time {
 	return time.Now().In(loc)
 }
 
+// ParseLocalTime 按本地时区解析表单中的 datetime-local 时间
+func ParseLocalTime(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02T15:04", value, GetCurrentTime().Location())
+}
+
 func GetCurrentDirectory() string {
 	dir := config.GetConfiguration().Dir
 	return dir
@@ -13,6 +13,7 @@ type Configuration struct {
 	Domain        string              `mapstructure:"domain"`
 	FileServer    string              `mapstructure:"file_server"`
 	NotifyEmails  string              `mapstructure:"notify_emails"`
+	AdminEmail    string              `mapstructure:"admin_email"` // 还没有管理员时升级为管理员的账号，为空时使用最早注册的用户
 	PageSize      int                 `mapstructure:"page_size"`
 	PublicDir     string              `mapstructure:"public"`
 	ViewDir       string              `mapstructure:"view"`
@@ -23,12 +24,20 @@ type Configuration struct {
 	Smms          Smms                `mapstructure:"smms"`
 	Redis         RedisConfig         `mapstructure:"redis"`
 	Elasticsearch ElasticsearchConfig `mapstructure:"elasticsearch"`
+	Search        SearchConfig        `mapstructure:"search"`
 	Github        Github              `mapstructure:"github"`
+	OAuth         []OAuthProvider     `mapstructure:"oauth"`
 	Smtp          Smtp                `mapstructure:"smtp"`
 	TLS           TLSConfig           `mapstructure:"tls"`
 	Navigators    []Navigator         `mapstructure:"navigators"`
 	Backup        Backup              `mapstructure:"backup"`
 	Zap           ZapConfig           `mapstructure:"zap"`
+	Schedule      Schedule            `mapstructure:"schedule"`
+	Comment       CommentConfig       `mapstructure:"comment"`
+	TwoFactor     TwoFactorConfig     `mapstructure:"two_factor"`
+	Login         LoginConfig         `mapstructure:"login"`
+	Security      SecurityConfig      `mapstructure:"security"`
+	Notify        NotifyConfig        `mapstructure:"notify"`
 }
 
 // Mysql 数据库配置
@@ -89,7 +98,19 @@ type ElasticsearchConfig struct {
 	URL       string `mapstructure:"url"`
 	Username  string `mapstructure:"username"`
 	Password  string `mapstructure:"password"`
-	IndexName string `mapstructure:"index_name"`
+	IndexName string `mapstructure:"index_name"` // 索引别名，实际索引名带版本号
+	Analyzer  string `mapstructure:"analyzer"`   // 中文分析器：auto、cjk、ik、smartcn 或 standard
+	Pinyin    bool   `mapstructure:"pinyin"`     // 安装了 analysis-pinyin 插件时为标题增加拼音字段，用于搜索建议
+}
+
+// SearchConfig 站内搜索配置
+type SearchConfig struct {
+	Engine          string `mapstructure:"engine"`            // 搜索引擎：auto、elasticsearch、local 或 database，auto 在启用 ES 时使用 ES，否则使用内置引擎
+	IndexPath       string `mapstructure:"index_path"`        // 内置引擎的索引文件，默认 data/search.idx
+	LogEnabled      bool   `mapstructure:"log_enabled"`       // 是否记录访客的搜索
+	LogRetention    int    `mapstructure:"log_retention"`     // 搜索明细保留天数，按天汇总的统计长期保留
+	SuggestDays     int    `mapstructure:"suggest_days"`      // 搜索建议参考最近多少天的热门搜索
+	SuggestMinCount int    `mapstructure:"suggest_min_count"` // 多少个不同访客搜索过才作为建议，默认 2，避免展示个别访客的搜索内容
 }
 
 // Github GitHub OAuth配置
@@ -103,6 +124,22 @@ type Github struct {
 	Scope        string `mapstructure:"scope"`
 }
 
+// OAuthProvider 第三方登录提供方配置
+type OAuthProvider struct {
+	Name         string   `mapstructure:"name"`          // 唯一标识，用于 /auth/<name> 和回调地址
+	Type         string   `mapstructure:"type"`          // github、gitee、google 或 oidc
+	DisplayName  string   `mapstructure:"display_name"`  // 登录按钮上显示的名称
+	Enabled      bool     `mapstructure:"enabled"`       // 是否启用
+	ClientId     string   `mapstructure:"client_id"`     // 客户端ID
+	ClientSecret string   `mapstructure:"client_secret"` // 客户端密钥
+	RedirectUrl  string   `mapstructure:"redirect_url"`  // 回调地址，默认 domain + /oauth2callback/<name>
+	Issuer       string   `mapstructure:"issuer"`        // OIDC 签发者，通过 /.well-known/openid-configuration 发现端点
+	Scopes       []string `mapstructure:"scopes"`        // 授权范围，为空时使用提供方默认值
+	AuthUrl      string   `mapstructure:"auth_url"`      // 覆盖授权地址，用于 GitHub 企业版、自建 Gitee 等
+	TokenUrl     string   `mapstructure:"token_url"`     // 覆盖令牌地址
+	UserInfoUrl  string   `mapstructure:"userinfo_url"`  // 覆盖用户信息地址
+}
+
 // Smtp SMTP邮件配置
 type Smtp struct {
 	Enabled  bool   `mapstructure:"enabled"`
@@ -124,6 +161,78 @@ type Backup struct {
 	BackupKey string `mapstructure:"backup_key"`
 }
 
+// Schedule 定时发布配置
+type Schedule struct {
+	NotifySubscribers bool `mapstructure:"notify_subscribers"` // 定时发布后是否通知订阅者
+}
+
+// CommentConfig 评论反垃圾配置，未配置的数值项使用默认值
+type CommentConfig struct {
+	Moderation      bool     `mapstructure:"moderation"`       // 是否所有评论都需人工审核
+	MaxLinks        int      `mapstructure:"max_links"`        // 单条评论允许的链接数
+	Keywords        []string `mapstructure:"keywords"`         // 屏蔽关键词
+	RateLimit       int      `mapstructure:"rate_limit"`       // 时间窗口内单个用户/IP允许的评论数
+	RateWindow      int      `mapstructure:"rate_window"`      // 限流时间窗口（秒）
+	QueueThreshold  float64  `mapstructure:"queue_threshold"`  // 垃圾分数达到该值进入审核队列
+	RejectThreshold float64  `mapstructure:"reject_threshold"` // 垃圾分数达到该值直接拒绝
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	EnforceAdmin  bool   `mapstructure:"enforce_admin"`  // 是否强制所有管理员启用两步验证
+	Issuer        string `mapstructure:"issuer"`         // 验证器应用中显示的发行方，默认使用站点标题
+	MaxAttempts   int    `mapstructure:"max_attempts"`   // 时间窗口内允许的验证失败次数
+	AttemptWindow int    `mapstructure:"attempt_window"` // 失败计数时间窗口（秒）
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	MaxFailures   int `mapstructure:"max_failures"`    // 时间窗口内单个账号允许的失败次数，超出后临时锁定
+	MaxIPFailures int `mapstructure:"max_ip_failures"` // 时间窗口内单个IP允许的失败次数，超出后临时封禁
+	Window        int `mapstructure:"window"`          // 失败计数时间窗口（秒）
+	Lockout       int `mapstructure:"lockout"`         // 临时锁定时长（秒）
+	CaptchaAfter  int `mapstructure:"captcha_after"`   // 失败达到该次数后需要输入验证码
+	DelayAfter    int `mapstructure:"delay_after"`     // 失败达到该次数后逐次增加响应延迟
+	MaxDelay      int `mapstructure:"max_delay"`       // 最大响应延迟（秒）
+}
+
+// SecurityConfig 安全响应头和内容安全策略（CSP）配置
+type SecurityConfig struct {
+	Enabled               bool     `mapstructure:"enabled"`                 // 是否输出安全响应头
+	CSPMode               string   `mapstructure:"csp_mode"`                // off、report-only 或 enforce
+	ScriptSrc             []string `mapstructure:"script_src"`              // 额外允许的脚本来源
+	StyleSrc              []string `mapstructure:"style_src"`               // 额外允许的样式来源
+	ImgSrc                []string `mapstructure:"img_src"`                 // 额外允许的图片来源
+	FontSrc               []string `mapstructure:"font_src"`                // 额外允许的字体来源
+	ConnectSrc            []string `mapstructure:"connect_src"`             // 额外允许的 XHR/fetch 目标
+	FrameSrc              []string `mapstructure:"frame_src"`               // 额外允许嵌入的页面
+	FrameOptions          string   `mapstructure:"frame_options"`           // X-Frame-Options，DENY 或 SAMEORIGIN
+	ReferrerPolicy        string   `mapstructure:"referrer_policy"`         // Referrer-Policy
+	PermissionsPolicy     string   `mapstructure:"permissions_policy"`      // Permissions-Policy
+	HSTSMaxAge            int      `mapstructure:"hsts_max_age"`            // HSTS 有效期（秒），仅在启用 TLS 时输出
+	HSTSIncludeSubdomains bool     `mapstructure:"hsts_include_subdomains"` // HSTS 是否包含子域名
+	HSTSPreload           bool     `mapstructure:"hsts_preload"`            // 是否申请加入浏览器 HSTS 预加载列表
+	ReportRetention       int      `mapstructure:"report_retention"`        // CSP 违规报告保留天数
+}
+
+// NotifyConfig 通知配置，未配置的事件使用默认路由：新评论发送到 email 和 inapp，其余事件只发送到 inapp
+type NotifyConfig struct {
+	Channels []NotifyChannel     `mapstructure:"channels"` // 通知渠道，内置名为 email 和 inapp 的渠道，同名配置会覆盖内置渠道
+	Routes   map[string][]string `mapstructure:"routes"`   // 事件发送到的渠道名称，值为空列表时不发送
+}
+
+// NotifyChannel 通知渠道
+type NotifyChannel struct {
+	Name    string `mapstructure:"name"`    // 渠道名称，在 routes 中引用
+	Type    string `mapstructure:"type"`    // email、webhook、telegram、dingtalk、feishu、wecom 或 inapp
+	URL     string `mapstructure:"url"`     // webhook 和机器人地址；telegram 为 Bot API 地址，默认 https://api.telegram.org
+	Secret  string `mapstructure:"secret"`  // webhook 签名密钥，钉钉、飞书机器人的加签密钥
+	Token   string `mapstructure:"token"`   // telegram 机器人 token
+	ChatID  string `mapstructure:"chat_id"` // telegram 接收消息的会话ID
+	To      string `mapstructure:"to"`      // 邮件收件人，多个用分号分隔，默认使用 notify_emails
+	Timeout int    `mapstructure:"timeout"` // 请求超时（秒），默认 10
+}
+
 // TLSConfig TLS/SSL配置
 type TLSConfig struct {
 	Enabled  bool   `mapstructure:"enabled"`
@@ -0,0 +1,78 @@
+package fulltext
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Highlight 截取文本中第一处命中关键词附近约 size 个字符的片段，命中的词条用 <mark> 标记，
+// 其余内容做 HTML 转义；没有命中时返回空字符串
+func Highlight(text, query string, size int) string {
+	terms := make(map[string]bool)
+	for _, term := range QueryTerms(query) {
+		terms[term] = true
+	}
+	if len(terms) == 0 || size <= 0 {
+		return ""
+	}
+
+	// 命中的位置，重叠或相邻的合并成一段
+	var matches [][2]int
+	for _, token := range Tokenize(text) {
+		if terms[token.Term] {
+			matches = append(matches, [2]int{token.Start, token.End})
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i][0] < matches[j][0]
+	})
+	merged := matches[:1]
+	for _, match := range matches[1:] {
+		last := &merged[len(merged)-1]
+		if match[0] <= last[1] {
+			last[1] = max(last[1], match[1])
+		} else {
+			merged = append(merged, match)
+		}
+	}
+
+	// 片段从第一处命中前约五分之一的位置开始
+	start := merged[0][0]
+	for back := size / 5; back > 0 && start > 0; back-- {
+		_, width := utf8.DecodeLastRuneInString(text[:start])
+		start -= width
+	}
+	end := start
+	for n := 0; n < size && end < len(text); n++ {
+		_, width := utf8.DecodeRuneInString(text[end:])
+		end += width
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("…")
+	}
+	pos := start
+	for _, match := range merged {
+		if match[1] <= pos {
+			continue
+		}
+		if match[0] >= end {
+			break
+		}
+		builder.WriteString(html.EscapeString(text[pos:match[0]]))
+		matchEnd := min(match[1], end)
+		builder.WriteString("<mark>" + html.EscapeString(text[match[0]:matchEnd]) + "</mark>")
+		pos = matchEnd
+	}
+	builder.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		builder.WriteString("…")
+	}
+	return builder.String()
+}
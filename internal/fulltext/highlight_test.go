@@ -0,0 +1,59 @@
+package fulltext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		size  int
+		want  string
+	}{
+		{"no match", "hello world", "golang", 50, ""},
+		{"empty query", "hello world", "  ", 50, ""},
+		{"zero size", "hello world", "hello", 0, ""},
+		{"latin", "Learn Go today", "go", 50, "Learn <mark>Go</mark> today"},
+		{"every occurrence", "go and go", "GO", 50, "<mark>go</mark> and <mark>go</mark>"},
+		{"escape surrounding text", `<b>"go"</b> & more`, "go", 50,
+			"&lt;b&gt;&#34;<mark>go</mark>&#34;&lt;/b&gt; &amp; more"},
+		{"escape inside mark", "a<go>b", "go", 50, "a&lt;<mark>go</mark>&gt;b"},
+		// 重叠的二元词条合并成一段
+		{"cjk phrase", "我们学习搜索引擎的原理", "搜索引擎", 50, "我们学习<mark>搜索引擎</mark>的原理"},
+		{"cjk single char", "中文和英文", "文", 50, "中<mark>文</mark>和英<mark>文</mark>"},
+		{"multiple terms", "golang 的并发模型", "并发 golang", 50, "<mark>golang</mark> 的<mark>并发</mark>模型"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.query, tt.size); got != tt.want {
+				t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightWindow(t *testing.T) {
+	text := strings.Repeat("前文", 100) + "关键词" + strings.Repeat("后文", 100)
+	got := Highlight(text, "关键词", 20)
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected ellipsis on both sides: %q", got)
+	}
+	// 命中前保留约五分之一的上下文
+	want := "…前文前文<mark>关键词</mark>后文后文后文后文后文后文后…"
+	if got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+}
+
+func TestHighlightMatchAtWindowEnd(t *testing.T) {
+	// 第二处命中只有一部分落在片段内时，标记截止到片段末尾
+	text := "go " + strings.Repeat("x", 5) + " golang"
+	got := Highlight(text, "go golang", 12)
+	want := "<mark>go</mark> xxxxx <mark>gol</mark>…"
+	if got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,429 @@
+package fulltext
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version 索引文件格式版本，分词或打分方式变化时递增，旧版索引文件需要重建
+const Version = 1
+
+// ErrVersionMismatch 索引文件的格式版本与当前程序不一致
+var ErrVersionMismatch = errors.New("fulltext: index version mismatch")
+
+// 参与打分的字段
+const (
+	fieldTitle = iota
+	fieldTags
+	fieldBody
+	fieldCount
+)
+
+// fieldWeights 各字段的权重，与 ES 查询的 title^3、tags^2、body^1 保持一致
+var fieldWeights = [fieldCount]float64{3, 2, 1}
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Document 要索引的博文，正文只用于建立索引，不会保存
+type Document struct {
+	ID        uint
+	Title     string
+	Body      string
+	Tags      []string
+	CreatedAt time.Time
+	Views     int
+	Checksum  string // 调用方计算的内容校验和，用于检查索引是否与数据源一致
+}
+
+// Hit 一条搜索结果
+type Hit struct {
+	Doc   *Document
+	Score float64
+}
+
+// frequencies 词条在各字段中出现的次数，或各字段的词条总数
+type frequencies [fieldCount]int32
+
+// snapshot 写入磁盘的索引内容
+type snapshot struct {
+	Version  int
+	SyncedAt time.Time
+	Docs     map[uint]*Document
+	Lengths  map[uint]frequencies
+	Postings map[string]map[uint]frequencies
+}
+
+// Index 保存在单个文件中的倒排索引，按 BM25F 为标题、标签和正文加权打分
+type Index struct {
+	mutex    sync.RWMutex
+	save     sync.Mutex // 多次保存依次进行，较早的快照不会覆盖较新的文件
+	path     string
+	synced   time.Time
+	docs     map[uint]*Document
+	lengths  map[uint]frequencies
+	postings map[string]map[uint]frequencies
+	totals   [fieldCount]int64
+}
+
+// New 创建空索引，path 为索引文件的保存位置
+func New(path string) *Index {
+	index := &Index{path: path}
+	index.clear()
+	return index
+}
+
+// clear 清空索引内容
+func (index *Index) clear() {
+	index.docs = make(map[uint]*Document)
+	index.lengths = make(map[uint]frequencies)
+	index.postings = make(map[string]map[uint]frequencies)
+	index.totals = [fieldCount]int64{}
+}
+
+// Load 从磁盘读取索引，文件不存在时返回 os.ErrNotExist，版本不一致时返回 ErrVersionMismatch
+func (index *Index) Load() error {
+	file, err := os.Open(index.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&snap); err != nil {
+		return err
+	}
+	if snap.Version != Version {
+		return ErrVersionMismatch
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.clear()
+	index.synced = snap.SyncedAt
+	if snap.Docs != nil {
+		index.docs = snap.Docs
+	}
+	if snap.Lengths != nil {
+		index.lengths = snap.Lengths
+	}
+	if snap.Postings != nil {
+		index.postings = snap.Postings
+	}
+	for _, lengths := range index.lengths {
+		for field, length := range lengths {
+			index.totals[field] += int64(length)
+		}
+	}
+	return nil
+}
+
+// Save 把索引写入磁盘，syncedAt 记录索引与数据库一致的时间点，下次启动时从这里补同步。
+// 只在编码时持有读锁，写文件期间不阻塞搜索和修改；先写临时文件再重命名，写入中途退出不会损坏原有索引
+func (index *Index) Save(syncedAt time.Time) error {
+	index.save.Lock()
+	defer index.save.Unlock()
+
+	var buf bytes.Buffer
+	index.mutex.RLock()
+	err := gob.NewEncoder(&buf).Encode(&snapshot{
+		Version:  Version,
+		SyncedAt: syncedAt,
+		Docs:     index.docs,
+		Lengths:  index.lengths,
+		Postings: index.postings,
+	})
+	index.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(index.path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, filepath.Base(index.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = buf.WriteTo(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(file.Name(), index.path); err != nil {
+		return err
+	}
+	index.mutex.Lock()
+	index.synced = syncedAt
+	index.mutex.Unlock()
+	return nil
+}
+
+// SyncedAt 上次保存时记录的同步时间，从未保存过时为零值
+func (index *Index) SyncedAt() time.Time {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return index.synced
+}
+
+// Len 索引中的文档数
+func (index *Index) Len() int {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	return len(index.docs)
+}
+
+// IDs 索引中全部文档的 ID
+func (index *Index) IDs() []uint {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	ids := make([]uint, 0, len(index.docs))
+	for id := range index.docs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Checksums 索引中全部文档的校验和
+func (index *Index) Checksums() map[uint]string {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+	checksums := make(map[uint]string, len(index.docs))
+	for id, doc := range index.docs {
+		checksums[id] = doc.Checksum
+	}
+	return checksums
+}
+
+// Put 添加或替换文档
+func (index *Index) Put(docs ...*Document) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for _, doc := range docs {
+		index.remove(doc.ID)
+		index.add(doc)
+	}
+}
+
+// Delete 删除文档，不存在的 ID 会被忽略
+func (index *Index) Delete(ids ...uint) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	for _, id := range ids {
+		index.remove(id)
+	}
+}
+
+// Reset 清空索引后写入全部文档
+func (index *Index) Reset(docs []*Document) {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+	index.clear()
+	for _, doc := range docs {
+		index.add(doc)
+	}
+}
+
+// add 为文档建立倒排记录，调用方需持有写锁
+func (index *Index) add(doc *Document) {
+	fields := [fieldCount]string{
+		fieldTitle: doc.Title,
+		fieldTags:  strings.Join(doc.Tags, " "),
+		fieldBody:  doc.Body,
+	}
+	var lengths frequencies
+	for field, text := range fields {
+		for _, token := range Tokenize(text) {
+			postings := index.postings[token.Term]
+			if postings == nil {
+				postings = make(map[uint]frequencies)
+				index.postings[token.Term] = postings
+			}
+			freq := postings[doc.ID]
+			freq[field]++
+			postings[doc.ID] = freq
+			lengths[field]++
+		}
+		index.totals[field] += int64(lengths[field])
+	}
+	stored := *doc
+	stored.Body = ""
+	index.docs[doc.ID] = &stored
+	index.lengths[doc.ID] = lengths
+}
+
+// remove 删除文档的倒排记录，调用方需持有写锁
+func (index *Index) remove(id uint) {
+	if _, ok := index.docs[id]; !ok {
+		return
+	}
+	for term, postings := range index.postings {
+		if _, ok := postings[id]; !ok {
+			continue
+		}
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	for field, length := range index.lengths[id] {
+		index.totals[field] -= int64(length)
+	}
+	delete(index.docs, id)
+	delete(index.lengths, id)
+}
+
+// Search 搜索同时包含全部关键词的文档，按相关度从高到低排序，相关度相同时新文档在前。
+// 关键词为空时返回全部文档；filter 不为空时只返回 filter 返回 true 的文档
+func (index *Index) Search(query string, filter func(*Document) bool) []*Hit {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	terms := QueryTerms(query)
+	var hits []*Hit
+	if len(terms) == 0 {
+		for _, doc := range index.docs {
+			if filter == nil || filter(doc) {
+				hits = append(hits, &Hit{Doc: doc})
+			}
+		}
+		sortHits(hits)
+		return hits
+	}
+
+	// 从最少的倒排记录开始求交集
+	postings := make([]map[uint]frequencies, 0, len(terms))
+	for _, term := range terms {
+		if len(index.postings[term]) == 0 {
+			return nil
+		}
+		postings = append(postings, index.postings[term])
+	}
+	sort.Slice(postings, func(i, j int) bool {
+		return len(postings[i]) < len(postings[j])
+	})
+
+	avgLengths := index.avgLengths()
+	idfs := make([]float64, len(postings))
+	for i, termPostings := range postings {
+		idfs[i] = index.idf(len(termPostings))
+	}
+
+candidates:
+	for id := range postings[0] {
+		for _, termPostings := range postings[1:] {
+			if _, ok := termPostings[id]; !ok {
+				continue candidates
+			}
+		}
+		doc := index.docs[id]
+		if filter != nil && !filter(doc) {
+			continue
+		}
+		score := 0.0
+		for i, termPostings := range postings {
+			score += termScore(termPostings[id], index.lengths[id], &avgLengths, idfs[i])
+		}
+		hits = append(hits, &Hit{Doc: doc, Score: score})
+	}
+	sortHits(hits)
+	return hits
+}
+
+// avgLengths 各字段的平均词条数，调用方需持有读锁
+func (index *Index) avgLengths() [fieldCount]float64 {
+	var avgLengths [fieldCount]float64
+	total := float64(len(index.docs))
+	for field, length := range index.totals {
+		avgLengths[field] = math.Max(float64(length)/total, 1)
+	}
+	return avgLengths
+}
+
+// idf 出现在 df 篇文档中的词条的逆文档频率，调用方需持有读锁
+func (index *Index) idf(df int) float64 {
+	total := float64(len(index.docs))
+	return math.Log(1 + (total-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// termScore 一个词条对一篇文档的 BM25F 得分：各字段的词频按字段长度归一化后加权求和，再做饱和处理
+func termScore(freq, lengths frequencies, avgLengths *[fieldCount]float64, idf float64) float64 {
+	weighted := 0.0
+	for field := range freq {
+		norm := 1 - bm25B + bm25B*float64(lengths[field])/avgLengths[field]
+		weighted += fieldWeights[field] * float64(freq[field]) / norm
+	}
+	return idf * weighted * (bm25K1 + 1) / (weighted + bm25K1)
+}
+
+// sortHits 按相关度、发布时间、ID 降序排列
+func sortHits(hits []*Hit) {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Doc.CreatedAt.Equal(hits[j].Doc.CreatedAt) {
+			return hits[i].Doc.CreatedAt.After(hits[j].Doc.CreatedAt)
+		}
+		return hits[i].Doc.ID > hits[j].Doc.ID
+	})
+}
+
+// Suggest 标题包含 prefix 的文档标题，以 prefix 开头的在前，其余按浏览量排序
+func (index *Index) Suggest(prefix string, limit int) []string {
+	prefix = strings.TrimSpace(strings.Map(fold, prefix))
+	if prefix == "" || limit <= 0 {
+		return nil
+	}
+
+	index.mutex.RLock()
+	type candidate struct {
+		doc    *Document
+		prefix bool
+	}
+	var candidates []candidate
+	for _, doc := range index.docs {
+		title := strings.Map(fold, doc.Title)
+		if strings.HasPrefix(title, prefix) {
+			candidates = append(candidates, candidate{doc, true})
+		} else if strings.Contains(title, prefix) {
+			candidates = append(candidates, candidate{doc, false})
+		}
+	}
+	index.mutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].prefix != candidates[j].prefix {
+			return candidates[i].prefix
+		}
+		if candidates[i].doc.Views != candidates[j].doc.Views {
+			return candidates[i].doc.Views > candidates[j].doc.Views
+		}
+		return candidates[i].doc.ID > candidates[j].doc.ID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	titles := make([]string, len(candidates))
+	for i, c := range candidates {
+		titles[i] = c.doc.Title
+	}
+	return titles
+}
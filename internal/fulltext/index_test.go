@@ -0,0 +1,302 @@
+package fulltext
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testDoc(id uint, title, body string, tags ...string) *Document {
+	return &Document{
+		ID:        id,
+		Title:     title,
+		Body:      body,
+		Tags:      tags,
+		CreatedAt: testBase.Add(time.Duration(id) * time.Hour),
+		Checksum:  title,
+	}
+}
+
+func hitIDs(hits []*Hit) []uint {
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.Doc.ID)
+	}
+	return ids
+}
+
+func TestSearchFieldWeights(t *testing.T) {
+	index := New("")
+	// 同一个词分别出现在标题、标签和正文中，字段长度相同
+	index.Put(
+		testDoc(1, "other words", "golang notes", "misc"),
+		testDoc(2, "golang notes", "other words", "misc"),
+		testDoc(3, "other words", "misc notes", "golang"),
+	)
+	got := hitIDs(index.Search("golang", nil))
+	if want := []uint{2, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranking = %v, want %v (title > tags > body)", got, want)
+	}
+}
+
+func TestSearchBM25(t *testing.T) {
+	index := New("")
+	index.Put(
+		// 词频越高得分越高
+		testDoc(1, "a", "cache cache cache eviction"),
+		testDoc(2, "b", "cache eviction policy notes"),
+		// 相同词频时较短的文档得分更高
+		testDoc(3, "c", "cache eviction policy notes with a much longer body about many unrelated things"),
+		testDoc(4, "d", "nothing relevant here"),
+	)
+	hits := index.Search("cache", nil)
+	if got, want := hitIDs(hits), []uint{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ranking = %v, want %v", got, want)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].Score >= hits[i-1].Score {
+			t.Errorf("scores not strictly decreasing: %v >= %v", hits[i].Score, hits[i-1].Score)
+		}
+	}
+
+	// 出现在较少文档中的词条权重更高
+	rare := index.Search("policy", nil)
+	common := index.Search("eviction", nil)
+	if len(rare) == 0 || len(common) == 0 {
+		t.Fatal("expected hits")
+	}
+	rareScore, commonScore := scoreOf(rare, 2), scoreOf(common, 2)
+	if rareScore <= commonScore {
+		t.Errorf("idf: rare term score %v <= common term score %v", rareScore, commonScore)
+	}
+}
+
+func scoreOf(hits []*Hit, id uint) float64 {
+	for _, hit := range hits {
+		if hit.Doc.ID == id {
+			return hit.Score
+		}
+	}
+	return 0
+}
+
+func TestSearchMatchesAllTerms(t *testing.T) {
+	index := New("")
+	index.Put(
+		testDoc(1, "Go 并发编程", "goroutine 与 channel"),
+		testDoc(2, "Go 入门", "变量与函数"),
+		testDoc(3, "Rust 并发", "所有权"),
+	)
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{"go 并发", []uint{1}},
+		{"并发", []uint{1, 3}},
+		{"channel", []uint{1}},
+		{"并发 python", []uint{}},
+		{"发编", []uint{1}},
+	}
+	for _, tt := range tests {
+		// 只比较命中的文档，顺序由打分决定
+		got := hitIDs(index.Search(tt.query, nil))
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEmptyQueryAndFilter(t *testing.T) {
+	index := New("")
+	index.Put(testDoc(1, "one", ""), testDoc(2, "two", "", "go"), testDoc(3, "three", "", "go"))
+
+	// 没有关键词时按发布时间倒序返回全部文档
+	if got, want := hitIDs(index.Search("", nil)), []uint{3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("empty query = %v, want %v", got, want)
+	}
+	tagged := func(doc *Document) bool { return len(doc.Tags) > 0 }
+	if got, want := hitIDs(index.Search("", tagged)), []uint{3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filtered = %v, want %v", got, want)
+	}
+	if got := hitIDs(index.Search("one", tagged)); len(got) != 0 {
+		t.Errorf("filtered query = %v, want none", got)
+	}
+}
+
+func TestPutReplacesAndDeleteRemoves(t *testing.T) {
+	index := New("")
+	index.Put(testDoc(1, "old title", "body"), testDoc(2, "other", "body"))
+	index.Put(testDoc(1, "new title", "body"))
+
+	if hits := index.Search("old", nil); len(hits) != 0 {
+		t.Errorf("replaced document still matches old title: %v", hitIDs(hits))
+	}
+	if got := hitIDs(index.Search("new", nil)); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("Search(new) = %v", got)
+	}
+	if index.Len() != 2 {
+		t.Errorf("Len = %d, want 2", index.Len())
+	}
+
+	index.Delete(1, 99)
+	if got := hitIDs(index.Search("body", nil)); !reflect.DeepEqual(got, []uint{2}) {
+		t.Errorf("after delete = %v, want [2]", got)
+	}
+	if _, ok := index.postings["new"]; ok {
+		t.Error("postings of deleted document not removed")
+	}
+	for field, length := range index.lengths[2] {
+		if index.totals[field] != int64(length) {
+			t.Errorf("field totals %v do not match remaining document %v", index.totals, index.lengths[2])
+			break
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	index := New("")
+	docs := []*Document{testDoc(1, "Go 入门", ""), testDoc(2, "学习 Go", ""), testDoc(3, "Go 并发", ""), testDoc(4, "Rust", "")}
+	docs[0].Views, docs[2].Views = 10, 20
+	index.Put(docs...)
+
+	got := index.Suggest("ｇｏ", 10)
+	if want := []string{"Go 并发", "Go 入门", "学习 Go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest = %q, want %q", got, want)
+	}
+	if got := index.Suggest("go", 1); !reflect.DeepEqual(got, []string{"Go 并发"}) {
+		t.Errorf("Suggest with limit = %q", got)
+	}
+	if got := index.Suggest(" ", 10); got != nil {
+		t.Errorf("Suggest(blank) = %q, want nil", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "search.idx")
+	index := New(path)
+	index.Put(
+		testDoc(1, "Go 并发编程", "goroutine 与 channel 的用法", "go", "并发"),
+		testDoc(2, "搜索引擎原理", "倒排索引与 BM25 打分", "搜索"),
+		testDoc(3, "Hello", "hello world"),
+	)
+	syncedAt := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	if err := index.Save(syncedAt); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !index.SyncedAt().Equal(syncedAt) {
+		t.Errorf("SyncedAt after save = %v", index.SyncedAt())
+	}
+	// 临时文件已清理
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("unexpected files after save: %v", entries)
+	}
+
+	loaded := New(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !loaded.SyncedAt().Equal(syncedAt) {
+		t.Errorf("loaded SyncedAt = %v, want %v", loaded.SyncedAt(), syncedAt)
+	}
+	if loaded.Len() != index.Len() || !reflect.DeepEqual(loaded.Checksums(), index.Checksums()) {
+		t.Errorf("loaded documents differ: %v vs %v", loaded.Checksums(), index.Checksums())
+	}
+	if loaded.totals != index.totals {
+		t.Errorf("loaded totals %v, want %v", loaded.totals, index.totals)
+	}
+	for _, query := range []string{"go", "并发", "索引", "hello", ""} {
+		want, got := index.Search(query, nil), loaded.Search(query, nil)
+		if !reflect.DeepEqual(hitIDs(got), hitIDs(want)) {
+			t.Errorf("Search(%q) after load = %v, want %v", query, hitIDs(got), hitIDs(want))
+			continue
+		}
+		for i := range want {
+			if got[i].Score != want[i].Score {
+				t.Errorf("Search(%q) score %v after load, want %v", query, got[i].Score, want[i].Score)
+			}
+		}
+	}
+	// 正文只用于建立索引，不写入文件
+	for _, hit := range loaded.Search("", nil) {
+		if hit.Doc.Body != "" {
+			t.Errorf("document %d body was saved", hit.Doc.ID)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := New(filepath.Join(dir, "missing.idx")).Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load(missing) = %v, want os.ErrNotExist", err)
+	}
+
+	path := filepath.Join(dir, "old.idx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = gob.NewEncoder(file).Encode(&snapshot{Version: Version + 1}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	index := New(path)
+	index.Put(testDoc(1, "keep", ""))
+	if err := index.Load(); !errors.Is(err, ErrVersionMismatch) {
+		t.Errorf("Load(old version) = %v, want ErrVersionMismatch", err)
+	}
+	if index.Len() != 1 {
+		t.Error("failed load should keep the current index")
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.idx")
+	if err := os.WriteFile(corrupt, []byte("not a gob"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(corrupt).Load(); err == nil {
+		t.Error("Load(corrupt) should fail")
+	}
+}
+
+// 保存与修改、搜索并发进行，配合 -race 检查加锁
+func TestSaveConcurrentWithWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.idx")
+	index := New(path)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				id := uint(worker*100 + i)
+				index.Put(testDoc(id, "title", "concurrent body"))
+				index.Search("concurrent", nil)
+				if err := index.Save(testBase.Add(time.Duration(id))); err != nil {
+					t.Errorf("Save: %v", err)
+					return
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if err := index.Save(testBase); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 80 {
+		t.Errorf("loaded %d documents, want 80", loaded.Len())
+	}
+}
@@ -0,0 +1,72 @@
+package fulltext
+
+import "sort"
+
+// TermFrequencies 统计文本中各词条的出现次数，用于比较文本的相似度：
+// 字母数字按词统计，中日韩文字按二元词条统计，单独出现的汉字才按单字统计
+func TermFrequencies(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, s := range splitSpans(text) {
+		if !s.cjk || len(s.runes) == 1 {
+			counts[string(s.runes)]++
+			continue
+		}
+		for i := 0; i+1 < len(s.runes); i++ {
+			counts[string(s.runes[i:i+2])]++
+		}
+	}
+	return counts
+}
+
+// MoreLikeThis 找出与 text 内容相近的文档：从 text 中选出 TF-IDF 最高的 maxTerms 个词条，
+// 命中其中任意词条的文档按 BM25F 打分，结果按相关度从高到低排序，不包含 exclude
+func (index *Index) MoreLikeThis(text string, exclude uint, maxTerms int) []*Hit {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	type keyword struct {
+		term   string
+		idf    float64
+		weight float64
+	}
+	var keywords []keyword
+	half := len(index.docs) / 2
+	for term, tf := range TermFrequencies(text) {
+		postings := index.postings[term]
+		df := len(postings)
+		if _, ok := postings[exclude]; ok {
+			df--
+		}
+		// 其他文档都不包含的词条找不到相似文档，超过一半文档都包含的词条区分度太低
+		if df == 0 || (half > 0 && df > half) {
+			continue
+		}
+		idf := index.idf(df)
+		keywords = append(keywords, keyword{term: term, idf: idf, weight: float64(tf) * idf})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].weight != keywords[j].weight {
+			return keywords[i].weight > keywords[j].weight
+		}
+		return keywords[i].term < keywords[j].term
+	})
+	if len(keywords) > maxTerms {
+		keywords = keywords[:maxTerms]
+	}
+
+	avgLengths := index.avgLengths()
+	scores := make(map[uint]float64)
+	for _, kw := range keywords {
+		for id, freq := range index.postings[kw.term] {
+			if id != exclude {
+				scores[id] += termScore(freq, index.lengths[id], &avgLengths, kw.idf)
+			}
+		}
+	}
+	hits := make([]*Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, &Hit{Doc: index.docs[id], Score: score})
+	}
+	sortHits(hits)
+	return hits
+}
@@ -0,0 +1,103 @@
+package fulltext
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token 切分出的词条，Start 和 End 是词条在原文中的字节位置
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// span 原文中连续的同类字符
+type span struct {
+	runes   []rune // 归一化后的字符
+	offsets []int  // 每个字符在原文中的起始位置，最后多记一个结束位置
+	cjk     bool
+}
+
+// isCJK 是否是中日韩文字，这些文字之间没有空格分隔
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// fold 全角转半角并转小写
+func fold(r rune) rune {
+	switch {
+	case r == '　':
+		return ' '
+	case r >= '！' && r <= '～':
+		r -= 0xfee0
+	}
+	return unicode.ToLower(r)
+}
+
+// splitSpans 把文本切成连续的字母数字串和中日韩文字串，其余字符作为分隔
+func splitSpans(text string) []*span {
+	var spans []*span
+	var current *span
+	for i, r := range text {
+		folded := fold(r)
+		cjk := isCJK(folded)
+		if !cjk && !unicode.IsLetter(folded) && !unicode.IsDigit(folded) {
+			current = nil
+			continue
+		}
+		if current == nil || current.cjk != cjk {
+			current = &span{cjk: cjk}
+			spans = append(spans, current)
+		}
+		current.runes = append(current.runes, folded)
+		current.offsets = append(current.offsets, i)
+	}
+	for _, s := range spans {
+		last := s.offsets[len(s.offsets)-1]
+		_, size := utf8.DecodeRuneInString(text[last:])
+		s.offsets = append(s.offsets, last+size)
+	}
+	return spans
+}
+
+// Tokenize 切分要索引的文本：字母数字按词切分，中日韩文字同时生成单字和二元词条，
+// 这样既能按词组精确匹配，也能搜索单个汉字
+func Tokenize(text string) []Token {
+	var tokens []Token
+	for _, s := range splitSpans(text) {
+		if !s.cjk {
+			tokens = append(tokens, Token{Term: string(s.runes), Start: s.offsets[0], End: s.offsets[len(s.runes)]})
+			continue
+		}
+		for i := range s.runes {
+			tokens = append(tokens, Token{Term: string(s.runes[i]), Start: s.offsets[i], End: s.offsets[i+1]})
+			if i+1 < len(s.runes) {
+				tokens = append(tokens, Token{Term: string(s.runes[i : i+2]), Start: s.offsets[i], End: s.offsets[i+2]})
+			}
+		}
+	}
+	return tokens
+}
+
+// QueryTerms 切分搜索关键词：中日韩文字只取二元词条，单个汉字才按单字搜索，结果去重
+func QueryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, s := range splitSpans(query) {
+		if !s.cjk || len(s.runes) == 1 {
+			add(string(s.runes))
+			continue
+		}
+		for i := 0; i+1 < len(s.runes); i++ {
+			add(string(s.runes[i : i+2]))
+		}
+	}
+	return terms
+}
@@ -0,0 +1,91 @@
+package fulltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Token
+	}{
+		{"empty", "", nil},
+		{"punctuation only", "!?, ...", nil},
+		{"latin words", "Hello, World", []Token{
+			{"hello", 0, 5},
+			{"world", 7, 12},
+		}},
+		{"digits and underscore", "go1.24 a_b", []Token{
+			{"go1", 0, 3},
+			{"24", 4, 6},
+			{"a", 7, 8},
+			{"b", 9, 10},
+		}},
+		{"full width", "ＧＯ１", []Token{
+			{"go1", 0, 9},
+		}},
+		{"cjk unigrams and bigrams", "Go语言", []Token{
+			{"go", 0, 2},
+			{"语", 2, 5},
+			{"语言", 2, 8},
+			{"言", 5, 8},
+		}},
+		{"single cjk", "中", []Token{
+			{"中", 0, 3},
+		}},
+		{"cjk separated by punctuation", "搜索，引擎", []Token{
+			{"搜", 0, 3},
+			{"搜索", 0, 6},
+			{"索", 3, 6},
+			{"引", 9, 12},
+			{"引擎", 9, 15},
+			{"擎", 12, 15},
+		}},
+		{"kana", "カナ", []Token{
+			{"カ", 0, 3},
+			{"カナ", 0, 6},
+			{"ナ", 3, 6},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			// 位置必须能截取出原文中对应的片段
+			for _, token := range got {
+				if token.Start < 0 || token.End > len(tt.text) || token.Start >= token.End {
+					t.Errorf("token %v out of range", token)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"Go", []string{"go"}},
+		{"Go  GO go", []string{"go"}},
+		{"ＢＭ２５", []string{"bm25"}},
+		{"中", []string{"中"}},
+		{"搜索引擎", []string{"搜索", "索引", "引擎"}},
+		{"golang 并发编程", []string{"golang", "并发", "发编", "编程"}},
+		{"中 文", []string{"中", "文"}},
+		{"引擎 引擎", []string{"引擎"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := QueryTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryTerms(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
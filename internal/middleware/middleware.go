@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-contrib/sessions"
@@ -9,15 +10,16 @@ import (
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"gorm.io/gorm"
 )
 
-// SharedData 共享数据中间件，将用户信息和配置等共享到上下文
+// SharedData 共享数据中间件，将用户信息和配置等共享到上下文，渲染页面时注入 CSRF 令牌和 CSP nonce
 func SharedData() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		session := sessions.Default(c)
+		c.Writer = &pageWriter{ResponseWriter: c.Writer, session: session, nonce: c.GetString(common.ContextCSPNonce)}
 		if uID := session.Get(common.SessionKey); uID != nil {
-			user, err := models.GetUser(uID)
-			if err == nil {
+			if user := sessionUser(c, session, uID); user != nil {
 				c.Set(common.ContextUserKey, user)
 			}
 		}
@@ -28,12 +30,54 @@ func SharedData() gin.HandlerFunc {
 	}
 }
 
-// AuthRequired 权限验证中间件
-// adminScope: 是否需要管理员权限
+// sessionUser 读取会话中的登录用户，服务端会话已撤销、过期或用户被锁定时清除登录状态
+func sessionUser(c *gin.Context, session sessions.Session, uID interface{}) *models.User {
+	user, err := models.GetUser(uID)
+	if err != nil {
+		return nil
+	}
+	if user.LockState {
+		session.Clear()
+		session.Save()
+		return nil
+	}
+	sid, _ := session.Get(common.SessionIDKey).(string)
+	if sid == "" {
+		// 启用会话记录之前的登录无法撤销，不再补录，要求重新登录
+		log.Info("Session without server-side record, sign in again", "user_id", user.ID, "ip", c.ClientIP())
+		session.Clear()
+		session.Save()
+		return nil
+	}
+	record, err := models.GetUserSession(sid)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.UserID != user.ID) {
+		log.Info("Session revoked or expired", "user_id", user.ID, "ip", c.ClientIP())
+		session.Clear()
+		session.Save()
+		return nil
+	}
+	if err != nil {
+		// 数据库异常时不强制退出
+		log.Error("Get user session failed", "user_id", user.ID, "err", err)
+		return user
+	}
+	if err = record.Touch(c.ClientIP()); err != nil {
+		log.Warn("Touch user session failed", "session_id", record.ID, "err", err)
+	}
+	c.Set(common.ContextSessionKey, record)
+	return user
+}
+
+// AuthRequired 权限验证中间件，支持会话登录和 Authorization: Bearer 访问令牌
+// adminScope: 是否需要管理后台访问权限，更细的权限由 PermissionRequired 校验
 func AuthRequired(adminScope bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if present, err := bearerAuth(c); present && err != nil {
+			rejectBearer(c, err)
+			return
+		}
 		if user, _ := c.Get(common.ContextUserKey); user != nil {
-			if u, ok := user.(*models.User); ok && (!adminScope || u.IsAdmin) {
+			if u, ok := user.(*models.User); ok && (!adminScope || u.Can(models.PermAccessAdmin)) {
 				c.Next()
 				return
 			}
@@ -45,3 +89,25 @@ func AuthRequired(adminScope bool) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// APIAuthRequired /api/v1 权限验证中间件，未登录返回 401，权限不足返回 403
+func APIAuthRequired(adminScope bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if present, err := bearerAuth(c); present && err != nil {
+			rejectBearer(c, err)
+			return
+		}
+		user, _ := c.Get(common.ContextUserKey)
+		u, ok := user.(*models.User)
+		if !ok || u == nil {
+			common.APIFail(c, http.StatusUnauthorized, common.APICodeUnauthorized, "authentication required")
+			return
+		}
+		if adminScope && !u.Can(models.PermAccessAdmin) {
+			log.Warn("User not authorized to call api", "uri", c.Request.RequestURI, "user_id", u.ID, "role", u.Role)
+			common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "admin permission required")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// 模板中读取请求级数据的字段名
+const (
+	csrfTemplateKey  = "csrfToken"
+	nonceTemplateKey = "cspNonce"
+)
+
+// pageWriter 记录渲染页面时需要注入模板的请求级数据，由 SharedData 设置
+// CSRF 令牌在渲染页面时才生成，避免静态资源和 JSON 请求为未登录访客创建会话
+type pageWriter struct {
+	gin.ResponseWriter
+	session sessions.Session
+	nonce   string // CSP nonce，未启用 CSP 时为空
+}
+
+// TemplateRender 渲染页面时向 gin.H 数据注入 csrfToken 和 cspNonce，需配合 SharedData 使用
+func TemplateRender(r render.HTMLRender) render.HTMLRender {
+	return pageRender{HTMLRender: r}
+}
+
+type pageRender struct {
+	render.HTMLRender
+}
+
+func (r pageRender) Instance(name string, data any) render.Render {
+	return pageHTML{render: r.HTMLRender, name: name, data: data}
+}
+
+type pageHTML struct {
+	render render.HTMLRender
+	name   string
+	data   any
+}
+
+func (r pageHTML) Render(w http.ResponseWriter) error {
+	data := r.data
+	if pw, ok := w.(*pageWriter); ok {
+		if h, ok := data.(gin.H); ok {
+			// 复制一份，不修改处理函数传入的数据
+			injected := make(gin.H, len(h)+2)
+			injected[csrfTemplateKey] = csrfToken(pw.session)
+			injected[nonceTemplateKey] = pw.nonce
+			for k, v := range h {
+				injected[k] = v
+			}
+			data = injected
+		}
+	}
+	return r.render.Instance(r.name, data).Render(w)
+}
+
+func (r pageHTML) WriteContentType(w http.ResponseWriter) {
+	r.render.Instance(r.name, r.data).WriteContentType(w)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// permitted 判断当前用户的角色是否拥有权限，需在 AuthRequired 之后使用
+func permitted(c *gin.Context, perm string) bool {
+	u, ok := c.MustGet(common.ContextUserKey).(*models.User)
+	if ok && u.Can(perm) {
+		return true
+	}
+	if ok {
+		log.Warn("User permission denied", "uri", c.Request.RequestURI, "user_id", u.ID, "role", u.Role, "permission", perm)
+	}
+	return false
+}
+
+// PermissionRequired 角色权限校验中间件，权限不足时返回 403 页面
+func PermissionRequired(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if permitted(c, perm) {
+			c.Next()
+			return
+		}
+		c.HTML(http.StatusForbidden, "errors/error.html", gin.H{
+			"message": "Forbidden!",
+		})
+		c.Abort()
+	}
+}
+
+// APIPermissionRequired /api/v1 角色权限校验中间件，权限不足时返回 403
+func APIPermissionRequired(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if permitted(c, perm) {
+			c.Next()
+			return
+		}
+		common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "permission "+perm+" required")
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// CSPReportPath 浏览器上报 CSP 违规的地址
+const CSPReportPath = "/csp-report"
+
+// CSP 模式
+const (
+	CSPModeOff        = "off"
+	CSPModeReportOnly = "report-only"
+	CSPModeEnforce    = "enforce"
+)
+
+// 模板中使用的 CDN，配置中的来源追加在其后
+var cspCDNSources = []string{
+	"https://cdn.jsdelivr.net",
+	"https://cdnjs.cloudflare.com",
+	"https://maxcdn.bootstrapcdn.com",
+}
+
+// cspExcludedPaths 自带内联脚本的第三方页面，不输出 CSP
+var cspExcludedPaths = []string{"/swagger/"}
+
+// CSPMode 配置的 CSP 模式，未配置或无法识别时为 report-only
+func CSPMode(cfg config.SecurityConfig) string {
+	switch cfg.CSPMode {
+	case CSPModeOff, CSPModeEnforce:
+		return cfg.CSPMode
+	default:
+		return CSPModeReportOnly
+	}
+}
+
+// SecurityHeaders 安全响应头中间件：X-Frame-Options、Referrer-Policy、基于 nonce 的 CSP，
+// 以及仅在启用 TLS 时输出的 HSTS。nonce 写入上下文，由 SharedData 注入模板
+func SecurityHeaders() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.GetConfiguration()
+		sc := cfg.Security
+		if !sc.Enabled {
+			c.Next()
+			return
+		}
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		if sc.FrameOptions != "" {
+			h.Set("X-Frame-Options", sc.FrameOptions)
+		}
+		if sc.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", sc.ReferrerPolicy)
+		}
+		if sc.PermissionsPolicy != "" {
+			h.Set("Permissions-Policy", sc.PermissionsPolicy)
+		}
+		if cfg.TLS.Enabled && c.Request.TLS != nil && sc.HSTSMaxAge > 0 {
+			h.Set("Strict-Transport-Security", hstsValue(sc))
+		}
+		if mode := CSPMode(sc); mode != CSPModeOff && !cspExcluded(c.Request.URL.Path) {
+			if nonce := cspNonce(); nonce != "" {
+				c.Set(common.ContextCSPNonce, nonce)
+				name := "Content-Security-Policy"
+				if mode == CSPModeReportOnly {
+					name = "Content-Security-Policy-Report-Only"
+				}
+				h.Set(name, buildCSP(sc, nonce))
+			}
+		}
+		c.Next()
+	}
+}
+
+func hstsValue(sc config.SecurityConfig) string {
+	value := "max-age=" + strconv.Itoa(sc.HSTSMaxAge)
+	if sc.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if sc.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+func cspExcluded(path string) bool {
+	for _, prefix := range cspExcludedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func cspNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		log.Error("Generate csp nonce failed", "err", err)
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// buildCSP 生成策略：脚本只允许本站、模板使用的 CDN 和带 nonce 的内联脚本，
+// 样式允许内联（模板大量使用 style 属性），图片允许任意 https 来源（头像、图床）
+func buildCSP(sc config.SecurityConfig, nonce string) string {
+	frameAncestors := "'self'"
+	if strings.EqualFold(sc.FrameOptions, "DENY") {
+		frameAncestors = "'none'"
+	}
+	directives := [][]string{
+		{"default-src", "'self'"},
+		append(append([]string{"script-src", "'self'", "'nonce-" + nonce + "'"}, cspCDNSources...), sc.ScriptSrc...),
+		append(append([]string{"style-src", "'self'", "'unsafe-inline'", "https://fonts.googleapis.com"}, cspCDNSources...), sc.StyleSrc...),
+		append([]string{"img-src", "'self'", "data:", "https:"}, sc.ImgSrc...),
+		append(append([]string{"font-src", "'self'", "data:", "https://fonts.gstatic.com"}, cspCDNSources...), sc.FontSrc...),
+		append([]string{"connect-src", "'self'"}, sc.ConnectSrc...),
+		append([]string{"frame-src", "'self'"}, sc.FrameSrc...),
+		{"frame-ancestors", frameAncestors},
+		{"object-src", "'none'"},
+		{"base-uri", "'self'"},
+		{"form-action", "'self'"},
+		{"report-uri", CSPReportPath},
+	}
+	parts := make([]string, 0, len(directives))
+	for _, directive := range directives {
+		parts = append(parts, strings.Join(directive, " "))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// bearerAuth 使用 Authorization: Bearer 访问令牌认证请求，成功时将用户和令牌写入上下文
+// present 表示请求是否携带了令牌
+func bearerAuth(c *gin.Context) (present bool, err error) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return false, nil
+	}
+	scheme, plain, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false, nil
+	}
+	plain = strings.TrimSpace(plain)
+	if !strings.HasPrefix(plain, models.AccessTokenPrefix) {
+		return true, errors.New("malformed access token")
+	}
+	token, err := models.GetAccessTokenByPlain(plain)
+	if err != nil {
+		return true, errors.New("invalid access token")
+	}
+	user, err := models.GetUser(token.UserID)
+	if err != nil {
+		return true, errors.New("token owner not found")
+	}
+	if user.LockState {
+		return true, errors.New("token owner is locked")
+	}
+	c.Set(common.ContextUserKey, user)
+	c.Set(common.ContextTokenKey, token)
+	ip := c.ClientIP()
+	go func() {
+		if err := token.Touch(ip); err != nil {
+			log.Warn("record access token usage failed", "token_id", token.ID, "err", err)
+		}
+	}()
+	return true, nil
+}
+
+// rejectBearer 令牌无效时拒绝请求，令牌调用方一般是脚本，统一返回 JSON
+func rejectBearer(c *gin.Context, err error) {
+	log.Warn("Access token rejected", "uri", c.Request.RequestURI, "ip", c.ClientIP(), "err", err)
+	c.Header("WWW-Authenticate", `Bearer realm="bmtdblog"`)
+	common.APIFail(c, http.StatusUnauthorized, common.APICodeUnauthorized, err.Error())
+}
+
+// TokenScopeRequired 访问令牌权限校验，GET 请求需要 readScope，其他请求需要 writeScope
+// 通过会话登录的请求不受影响
+func TokenScopeRequired(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(common.ContextTokenKey)
+		if !ok {
+			c.Next()
+			return
+		}
+		token := value.(*models.AccessToken)
+		scope := writeScope
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = readScope
+		}
+		if token.Allows(scope) {
+			c.Next()
+			return
+		}
+		log.Warn("Access token scope insufficient", "uri", c.Request.RequestURI, "token_id", token.ID, "scope", token.Scope, "required", scope)
+		common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "access token requires scope "+scope)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// twoFactorEnrollPath 强制两步验证时仍可访问的个人资料页面，用于完成绑定
+const twoFactorEnrollPath = "/admin/profile"
+
+// TwoFactorEnrolled 配置 two_factor.enforce_admin 后，未启用两步验证的管理员只能访问个人资料页面，
+// 其访问令牌也会被拒绝，需在 AuthRequired 或 APIAuthRequired 之后使用
+func TwoFactorEnrolled() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.GetConfiguration().TwoFactor.EnforceAdmin {
+			c.Next()
+			return
+		}
+		u, ok := c.MustGet(common.ContextUserKey).(*models.User)
+		if !ok || u.Role != models.RoleAdmin || u.TotpEnabled {
+			c.Next()
+			return
+		}
+		if _, isToken := c.Get(common.ContextTokenKey); isToken {
+			log.Warn("Access token rejected, two-factor not enrolled", "uri", c.Request.RequestURI, "user_id", u.ID)
+			common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "two-factor authentication must be enabled for admin accounts")
+			return
+		}
+		if strings.HasPrefix(c.Request.URL.Path, twoFactorEnrollPath) {
+			c.Next()
+			return
+		}
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			common.APIFail(c, http.StatusForbidden, common.APICodeForbidden, "two-factor authentication must be enabled for admin accounts")
+			return
+		}
+		c.Redirect(http.StatusSeeOther, twoFactorEnrollPath+"?enroll=2fa")
+		c.Abort()
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// 访问令牌权限范围，权限依次递增，高权限包含低权限
+const (
+	TokenScopeRead         = "read"          // 只读
+	TokenScopeContentWrite = "content:write" // 管理博文、页面、标签、评论等内容
+	TokenScopeAdmin        = "admin"         // 全部管理权限
+)
+
+// AccessTokenPrefix 令牌明文前缀，便于识别和密钥扫描
+const AccessTokenPrefix = "bmt_"
+
+// 最后使用时间的更新间隔，避免每次请求都写数据库
+const tokenTouchInterval = time.Minute
+
+var tokenScopeLevels = map[string]int{
+	TokenScopeRead:         1,
+	TokenScopeContentWrite: 2,
+	TokenScopeAdmin:        3,
+}
+
+// AccessToken 个人访问令牌，只保存令牌的哈希值
+type AccessToken struct {
+	ID         uint       `gorm:"primarykey"`
+	CreatedAt  *time.Time `gorm:"autoCreateTime"`
+	UserID     uint       `gorm:"index"`
+	Name       string     `gorm:"type:varchar(64)"`
+	Scope      string     `gorm:"type:varchar(32)"`
+	TokenHash  string     `gorm:"type:char(64);uniqueIndex"` // sha256(明文令牌)
+	Hint       string     `gorm:"type:varchar(16)"`          // 明文末尾几位，用于辨认
+	LastUsedAt *time.Time
+	LastUsedIP string `gorm:"type:varchar(64)"`
+	RevokedAt  *time.Time
+}
+
+// IsValidTokenScope 判断权限范围是否合法
+func IsValidTokenScope(scope string) bool {
+	_, ok := tokenScopeLevels[scope]
+	return ok
+}
+
+// Allows 判断令牌是否具备 scope 所需的权限
+func (token *AccessToken) Allows(scope string) bool {
+	return tokenScopeLevels[token.Scope] >= tokenScopeLevels[scope]
+}
+
+// IsRevoked 令牌是否已撤销
+func (token *AccessToken) IsRevoked() bool {
+	return token.RevokedAt != nil
+}
+
+// HashAccessToken 计算明文令牌的哈希值
+func HashAccessToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAccessToken 为用户生成新令牌，返回的明文只在创建时可见
+func CreateAccessToken(userID uint, name, scope string) (string, *AccessToken, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	plain := AccessTokenPrefix + hex.EncodeToString(buf)
+	token := &AccessToken{
+		UserID:    userID,
+		Name:      name,
+		Scope:     scope,
+		TokenHash: HashAccessToken(plain),
+		Hint:      plain[len(plain)-4:],
+	}
+	DB := dao.GetMysqlDB()
+	if err := DB.Create(token).Error; err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// GetAccessTokenByPlain 根据明文令牌查询未撤销的令牌
+func GetAccessTokenByPlain(plain string) (*AccessToken, error) {
+	var token AccessToken
+	DB := dao.GetMysqlDB()
+	err := DB.First(&token, "token_hash = ? and revoked_at is null", HashAccessToken(plain)).Error
+	return &token, err
+}
+
+// ListAccessTokens 列出用户的全部令牌，最新创建的在前
+func ListAccessTokens(userID uint) ([]*AccessToken, error) {
+	var tokens []*AccessToken
+	DB := dao.GetMysqlDB()
+	err := DB.Where("user_id = ?", userID).Order("id desc").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeAccessToken 撤销用户的令牌
+func RevokeAccessToken(userID, id uint) error {
+	DB := dao.GetMysqlDB()
+	now := time.Now()
+	result := DB.Model(&AccessToken{}).Where("id = ? and user_id = ? and revoked_at is null", id, userID).Update("revoked_at", &now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Touch 记录令牌的最后使用时间和IP
+func (token *AccessToken) Touch(ip string) error {
+	now := time.Now()
+	if token.LastUsedAt != nil && now.Sub(*token.LastUsedAt) < tokenTouchInterval && token.LastUsedIP == ip {
+		return nil
+	}
+	token.LastUsedAt = &now
+	token.LastUsedIP = ip
+	DB := dao.GetMysqlDB()
+	return DB.Model(token).UpdateColumns(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	}).Error
+}
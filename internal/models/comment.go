@@ -1,9 +1,19 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"  // 待审核
+	CommentStatusApproved = "approved" // 已通过
+	CommentStatusSpam     = "spam"     // 垃圾评论
+	CommentStatusRejected = "rejected" // 已拒绝
 )
 
 type Comment struct {
@@ -13,15 +23,153 @@ type Comment struct {
 	UserID    uint
 	Content   string `gorm:"type:text"`
 	PostID    uint
+	ParentID  uint   `gorm:"index;default:0"`                           // 回复的评论ID，0 表示顶层评论
+	Status    string `gorm:"type:varchar(16);index;default:'approved'"` // 审核状态
 	ReadState bool   `gorm:"default:false"`
-	NickName  string `gorm:"-"`
-	AvatarUrl string `gorm:"-"`
-	GithubUrl string `gorm:"-"`
+	// 以下字段来自联表查询，只读且不建列
+	NickName  string     `gorm:"->;-:migration"`
+	AvatarUrl string     `gorm:"->;-:migration"`
+	GithubUrl string     `gorm:"->;-:migration"`
+	PostTitle string     `gorm:"->;-:migration"` // 管理页面展示用
+	Children  []*Comment `gorm:"-"`
+}
+
+// IsValidCommentStatus 判断审核状态是否合法
+func IsValidCommentStatus(status string) bool {
+	switch status {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusSpam, CommentStatusRejected:
+		return true
+	}
+	return false
 }
 
 func (comment *Comment) Insert() error {
 	DB := dao.GetMysqlDB()
-	return DB.Create(comment).Error
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return enqueueCommentWebhook(tx, comment)
+	})
+}
+
+func GetCommentById(id uint) (*Comment, error) {
+	var comment Comment
+	DB := dao.GetMysqlDB()
+	err := DB.First(&comment, "id = ?", id).Error
+	return &comment, err
+}
+
+// UpdateCommentStatus 批量修改评论审核状态，审核过的评论同时标记为已读
+func UpdateCommentStatus(ids []uint, status string) error {
+	DB := dao.GetMysqlDB()
+	return DB.Model(&Comment{}).Where("id in ?", ids).Updates(map[string]any{
+		"status":     status,
+		"read_state": true,
+	}).Error
+}
+
+// DeleteComments 管理员批量删除评论，子评论挂到被删除评论的上一级
+func DeleteComments(ids []uint) error {
+	DB := dao.GetMysqlDB()
+	var comments []*Comment
+	if err := DB.Where("id in ?", ids).Find(&comments).Error; err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if err := DB.Model(&Comment{}).Where("parent_id = ?", comment.ID).UpdateColumn("parent_id", comment.ParentID).Error; err != nil {
+			return err
+		}
+	}
+	return DB.Where("id in ?", ids).Delete(&Comment{}).Error
+}
+
+// ListCommentByStatus 管理页面按审核状态列出评论，status 为空时列出全部
+func ListCommentByStatus(status string) ([]*Comment, error) {
+	var comments []*Comment
+	DB := dao.GetMysqlDB()
+	query := "select c.*,coalesce(nullif(u.nick_name,''),u.github_login_id) nick_name,u.avatar_url,u.github_url,p.title post_title from comments c left join users u on c.user_id = u.id left join posts p on c.post_id = p.id"
+	var args []any
+	if status != "" {
+		query += " where c.status = ?"
+		args = append(args, status)
+	}
+	query += " order by c.created_at desc"
+	rows, err := DB.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var comment Comment
+		DB.ScanRows(rows, &comment)
+		comments = append(comments, &comment)
+	}
+	return comments, err
+}
+
+// ListCommentByFilter 按审核状态和博文分页列出评论，同时返回满足条件的总数
+func ListCommentByFilter(status string, postID uint, pageIndex, pageSize int) ([]*Comment, int64, error) {
+	var (
+		comments []*Comment
+		total    int64
+		where    []string
+		args     []any
+	)
+	if status != "" {
+		where = append(where, "c.status = ?")
+		args = append(args, status)
+	}
+	if postID > 0 {
+		where = append(where, "c.post_id = ?")
+		args = append(args, postID)
+	}
+	condition := ""
+	if len(where) > 0 {
+		condition = " where " + strings.Join(where, " and ")
+	}
+	DB := dao.GetMysqlDB()
+	if err := DB.Raw("select count(*) from comments c"+condition, args...).Row().Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	query := "select c.*,coalesce(nullif(u.nick_name,''),u.github_login_id) nick_name,u.avatar_url,u.github_url,p.title post_title from comments c left join users u on c.user_id = u.id left join posts p on c.post_id = p.id" +
+		condition + " order by c.created_at desc limit ? offset ?"
+	args = append(args, pageSize, (pageIndex-1)*pageSize)
+	rows, err := DB.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var comment Comment
+		DB.ScanRows(rows, &comment)
+		comments = append(comments, &comment)
+	}
+	return comments, total, nil
+}
+
+// ListCommentContentByStatus 列出指定审核状态下最近的评论内容，用于训练垃圾评论分类器
+func ListCommentContentByStatus(status string, limit int) ([]string, error) {
+	var contents []string
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&Comment{}).Where("status = ?", status).Order("id desc").Limit(limit).Pluck("content", &contents).Error
+	return contents, err
+}
+
+// CountCommentByStatus 统计各审核状态的评论数
+func CountCommentByStatus() map[string]int64 {
+	type statusCount struct {
+		Status string
+		Total  int64
+	}
+	var counts []statusCount
+	DB := dao.GetMysqlDB()
+	DB.Model(&Comment{}).Select("status, count(*) total").Group("status").Scan(&counts)
+	result := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		result[count.Status] = count.Total
+	}
+	return result
 }
 
 func (comment *Comment) Update() error {
@@ -51,10 +199,11 @@ func (comment *Comment) Delete() error {
 	return DB.Delete(comment, "user_id = ?", comment.UserID).Error
 }
 
+// ListCommentByPostID 列出博文下已通过审核的评论
 func ListCommentByPostID(id uint) ([]*Comment, error) {
 	var comments []*Comment
 	DB := dao.GetMysqlDB()
-	rows, err := DB.Raw("select c.*,u.github_login_id nick_name,u.avatar_url,u.github_url from comments c inner join users u on c.user_id = u.id where c.post_id = ? order by created_at desc", id).Rows()
+	rows, err := DB.Raw("select c.*,coalesce(nullif(u.nick_name,''),u.github_login_id) nick_name,u.avatar_url,u.github_url from comments c inner join users u on c.user_id = u.id where c.post_id = ? and c.status = ? order by created_at desc", id, CommentStatusApproved).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +226,26 @@ func CountComment() int64 {
 func CountCommentByPostID(postID uint) int {
 	var count int64
 	DB := dao.GetMysqlDB()
-	DB.Model(&Comment{}).Where("post_id = ?", postID).Count(&count)
+	DB.Model(&Comment{}).Where("post_id = ? and status = ?", postID, CommentStatusApproved).Count(&count)
 	return int(count)
 }
+
+// BuildCommentTree 将评论列表组装为树形结构，父评论不可见时子评论作为顶层展示
+// 顶层评论保持原有顺序（最新在前），回复按时间先后排列
+func BuildCommentTree(comments []*Comment) []*Comment {
+	byID := make(map[uint]*Comment, len(comments))
+	for _, comment := range comments {
+		comment.Children = nil
+		byID[comment.ID] = comment
+	}
+	roots := make([]*Comment, 0)
+	for _, comment := range comments {
+		if parent, ok := byID[comment.ParentID]; ok && comment.ParentID != 0 {
+			// comments 按时间倒序，插到头部使回复按时间正序
+			parent.Children = append([]*Comment{comment}, parent.Children...)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+	return roots
+}
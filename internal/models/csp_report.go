@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+)
+
+// CSPReport 浏览器上报的内容安全策略违规记录
+type CSPReport struct {
+	ID                uint       `gorm:"primarykey"`
+	CreatedAt         *time.Time `gorm:"autoCreateTime;index"`
+	Disposition       string     `gorm:"type:varchar(16)"`  // enforce 拦截，report 仅上报
+	DocumentURI       string     `gorm:"type:varchar(512)"` // 发生违规的页面
+	BlockedURI        string     `gorm:"type:varchar(512)"` // 被拦截的资源，内联脚本为 inline
+	ViolatedDirective string     `gorm:"type:varchar(128)"`
+	SourceFile        string     `gorm:"type:varchar(512)"`
+	LineNumber        int
+	Sample            string `gorm:"type:varchar(255)"` // 违规内容的前若干字符
+	IP                string `gorm:"type:varchar(64)"`
+	UserAgent         string `gorm:"type:varchar(255)"`
+}
+
+// CSPReportGroup 按违规指令和被拦截资源汇总的报告
+type CSPReportGroup struct {
+	ViolatedDirective string
+	BlockedURI        string
+	Count             int64
+	Pages             int64 // 涉及的页面数
+	LastSeen          time.Time
+	Sample            *CSPReport `gorm:"-"` // 最近一条报告，用于显示来源文件和行号
+}
+
+// Insert 保存违规报告
+func (report *CSPReport) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(report).Error
+}
+
+// ListCSPReportGroups 汇总违规报告，次数多的在前
+func ListCSPReportGroups(limit int) ([]*CSPReportGroup, error) {
+	var groups []*CSPReportGroup
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&CSPReport{}).
+		Select("violated_directive, blocked_uri, count(*) as count, count(distinct document_uri) as pages, max(created_at) as last_seen").
+		Group("violated_directive, blocked_uri").
+		Order("count desc").
+		Limit(limit).
+		Scan(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		var report CSPReport
+		if DB.Where("violated_directive = ? and blocked_uri = ?", group.ViolatedDirective, group.BlockedURI).
+			Order("id desc").First(&report).Error == nil {
+			group.Sample = &report
+		}
+	}
+	return groups, nil
+}
+
+// CountCSPReports 报告总数
+func CountCSPReports() (count int64, err error) {
+	DB := dao.GetMysqlDB()
+	err = DB.Model(&CSPReport{}).Count(&count).Error
+	return
+}
+
+// DeleteCSPReportsBefore 删除指定时间之前的报告
+func DeleteCSPReportsBefore(before time.Time) (int64, error) {
+	DB := dao.GetMysqlDB()
+	result := DB.Where("created_at < ?", before).Delete(&CSPReport{})
+	return result.RowsAffected, result.Error
+}
+
+// ClearCSPReports 清空全部报告
+func ClearCSPReports() (int64, error) {
+	DB := dao.GetMysqlDB()
+	result := DB.Where("1 = 1").Delete(&CSPReport{})
+	return result.RowsAffected, result.Error
+}
@@ -11,14 +11,35 @@ func RegisterModels(db *gorm.DB) error {
 		&Post{},
 		&Tag{},
 		&PostTag{},
+		&PostRevision{},
+		&SlugHistory{},
 		&User{},
+		&AccessToken{},
+		&RecoveryCode{},
+		&UserLinkSecret{},
+		&LoginAttempt{},
+		&UserSession{},
+		&UserIdentity{},
+		&CSPReport{},
 		&Comment{},
 		&Subscriber{},
 		&Link{},
 		&SmmsFile{},
 		&ESSyncStatus{},
+		&SearchLog{},
+		&SearchStat{},
+		&SearchOutbox{},
+		&Notification{},
+		&Webhook{},
+		&WebhookDelivery{},
 	}
 
 	// 自动迁移模式
-	return db.AutoMigrate(models...)
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	if err := migrateRoles(db); err != nil {
+		return err
+	}
+	return migrateIdentities(db)
 }
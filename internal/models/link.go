@@ -51,6 +51,6 @@ func MustListLinks() []*Link {
 func GetLinkById(id uint) (*Link, error) {
 	var link Link
 	DB := dao.GetMysqlDB()
-	err := DB.FirstOrCreate(&link, "id = ?", id).Error
+	err := DB.First(&link, "id = ?", id).Error
 	return &link, err
 }
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// 登录方式
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodTwoFactor = "2fa"
+	LoginMethodGithub    = "github"
+	LoginMethodAdmin     = "admin" // 管理员手动解除临时锁定
+	LoginMethodReset     = "reset" // 通过邮件链接重置密码
+)
+
+// 登录失败原因
+const (
+	LoginReasonInvalidPassword = "invalid_password" // 账号不存在或密码错误
+	LoginReasonAccountLocked   = "account_locked"   // 账号已被管理员锁定
+	LoginReasonRateLimited     = "rate_limited"     // 失败次数过多被临时锁定
+	LoginReasonCaptcha         = "captcha"          // 验证码错误
+	LoginReasonInvalidCode     = "invalid_code"     // 两步验证码错误
+	LoginReasonTwoFactor       = "2fa_required"     // 密码正确，等待两步验证
+	LoginReasonUnlocked        = "unlocked"         // 管理员手动解除临时锁定
+)
+
+// LoginAttempt 登录审计记录，成功和失败都会记录
+type LoginAttempt struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime;index"`
+	UserID    uint       `gorm:"index"`                   // 账号不存在时为 0
+	Email     string     `gorm:"type:varchar(191);index"` // 登录时填写的账号
+	IP        string     `gorm:"type:varchar(64);index"`
+	UserAgent string     `gorm:"type:varchar(255)"`
+	Method    string     `gorm:"type:varchar(16)"`
+	Success   bool
+	Reason    string `gorm:"type:varchar(32)"`
+}
+
+// Insert 保存登录记录
+func (attempt *LoginAttempt) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(attempt).Error
+}
+
+// ListLoginAttempts 最近的登录记录，userID 为 0 时列出所有用户
+func ListLoginAttempts(userID uint, limit int) ([]*LoginAttempt, error) {
+	var attempts []*LoginAttempt
+	DB := dao.GetMysqlDB()
+	db := DB.Order("id desc").Limit(limit)
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+	err := db.Find(&attempts).Error
+	return attempts, err
+}
+
+// loginFailureQueries since 之后账号和IP登录失败记录的查询
+// 账号登录成功或被手动解锁后，之前的失败不再计入
+func loginFailureQueries(email, ip string, since time.Time) (byEmail, byIP *gorm.DB, err error) {
+	DB := dao.GetMysqlDB()
+	var last LoginAttempt
+	err = DB.Select("id, created_at").
+		Where("email = ? and success = ? and created_at >= ?", email, true, since).
+		Order("id desc").Limit(1).Find(&last).Error
+	if err != nil {
+		return
+	}
+	emailSince := since
+	if last.ID > 0 && last.CreatedAt != nil {
+		emailSince = *last.CreatedAt
+	}
+	failures := DB.Model(&LoginAttempt{}).
+		Where("reason = ?", LoginReasonInvalidPassword).
+		Session(&gorm.Session{})
+	byEmail = failures.Where("email = ? and created_at >= ?", email, emailSince)
+	byIP = failures.Where("ip = ? and created_at >= ?", ip, since)
+	return
+}
+
+// CountLoginFailures 统计 since 之后账号和IP的登录失败次数，Redis 不可用时用于限流
+func CountLoginFailures(email, ip string, since time.Time) (byEmail, byIP int64, err error) {
+	emailQuery, ipQuery, err := loginFailureQueries(email, ip, since)
+	if err != nil {
+		return
+	}
+	if err = emailQuery.Count(&byEmail).Error; err != nil {
+		return
+	}
+	err = ipQuery.Count(&byIP).Error
+	return
+}
+
+// CountTwoFactorFailures 统计 since 之后用户两步验证码错误的次数，Redis 不可用时用于限流
+func CountTwoFactorFailures(userID uint, since time.Time) (int64, error) {
+	var count int64
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&LoginAttempt{}).
+		Where("user_id = ? and method = ? and success = ? and reason = ? and created_at >= ?",
+			userID, LoginMethodTwoFactor, false, LoginReasonInvalidCode, since).
+		Count(&count).Error
+	return count, err
+}
+
+// RecentLoginFailures since 之后账号和IP最近的登录失败时间，按时间倒序，分别最多返回 emailLimit、ipLimit 条
+func RecentLoginFailures(email, ip string, since time.Time, emailLimit, ipLimit int) (byEmail, byIP []time.Time, err error) {
+	emailQuery, ipQuery, err := loginFailureQueries(email, ip, since)
+	if err != nil {
+		return
+	}
+	if err = emailQuery.Order("id desc").Limit(emailLimit).Pluck("created_at", &byEmail).Error; err != nil {
+		return
+	}
+	err = ipQuery.Order("id desc").Limit(ipLimit).Pluck("created_at", &byIP).Error
+	return
+}
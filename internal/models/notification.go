@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+)
+
+// Notification 站内通知，显示在后台顶栏和通知页面
+type Notification struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime;index"`
+	Event     string     `gorm:"type:varchar(32);index"` // 触发通知的事件
+	Title     string     `gorm:"type:varchar(255)"`
+	Body      string     `gorm:"type:text"`
+	Link      string     `gorm:"type:varchar(512)"` // 相关页面地址
+	ReadAt    *time.Time `gorm:"index"`             // 已读时间，为空表示未读
+}
+
+// Insert 保存通知
+func (notification *Notification) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(notification).Error
+}
+
+// MustListUnreadNotification 最近的未读通知，出错时返回空
+func MustListUnreadNotification(limit int) []*Notification {
+	notifications, _ := ListUnreadNotification(limit)
+	return notifications
+}
+
+// ListUnreadNotification 最近的未读通知
+func ListUnreadNotification(limit int) ([]*Notification, error) {
+	var notifications []*Notification
+	DB := dao.GetMysqlDB()
+	err := DB.Where("read_at IS NULL").Order("id desc").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// CountUnreadNotification 未读通知数
+func CountUnreadNotification() (count int64) {
+	DB := dao.GetMysqlDB()
+	DB.Model(&Notification{}).Where("read_at IS NULL").Count(&count)
+	return
+}
+
+// ListNotification 分页列出全部通知，新的在前
+func ListNotification(pageIndex, pageSize int) ([]*Notification, error) {
+	var notifications []*Notification
+	DB := dao.GetMysqlDB()
+	err := DB.Order("id desc").Limit(pageSize).Offset((pageIndex - 1) * pageSize).Find(&notifications).Error
+	return notifications, err
+}
+
+// CountNotification 通知总数
+func CountNotification() (count int64, err error) {
+	DB := dao.GetMysqlDB()
+	err = DB.Model(&Notification{}).Count(&count).Error
+	return
+}
+
+// SetNotificationRead 标记通知为已读
+func SetNotificationRead(id uint) error {
+	DB := dao.GetMysqlDB()
+	return DB.Model(&Notification{}).Where("id = ? AND read_at IS NULL", id).Update("read_at", time.Now()).Error
+}
+
+// SetAllNotificationRead 标记全部通知为已读
+func SetAllNotificationRead() error {
+	DB := dao.GetMysqlDB()
+	return DB.Model(&Notification{}).Where("read_at IS NULL").Update("read_at", time.Now()).Error
+}
@@ -10,26 +10,44 @@ type Page struct {
 	ID          uint       `gorm:"primarykey"`
 	CreatedAt   *time.Time `gorm:"autoCreateTime"`
 	UpdatedAt   *time.Time `gorm:"autoUpdateTime"`
-	Title       string     `gorm:"type:text"`     // title
-	Body        string     `gorm:"type:longtext"` // body
+	Title       string     `gorm:"type:text"`                                  // title
+	Slug        string     `gorm:"type:varchar(191);uniqueIndex;default:null"` // url slug
+	Body        string     `gorm:"type:longtext"`                              // body
 	View        int        // view count
 	IsPublished bool       // published or not
+	PublishAt   *time.Time `gorm:"index"` // scheduled publish time
 }
 
 func (page *Page) Insert() error {
 	DB := dao.GetMysqlDB()
-	return DB.Create(page).Error
+	page.Slug = assignSlug(SlugKindPage, 0, page.Slug, page.Title)
+	if err := DB.Create(page).Error; err != nil {
+		return err
+	}
+	if page.Slug == "" {
+		page.Slug = fallbackSlug(SlugKindPage, page.ID)
+		return DB.Model(page).UpdateColumn("slug", page.Slug).Error
+	}
+	return nil
 }
 
 func (page *Page) Update() error {
 	DB := dao.GetMysqlDB()
+	page.Slug = assignSlug(SlugKindPage, page.ID, page.Slug, page.Title)
 	return DB.Model(page).Updates(map[string]any{
 		"title":        page.Title,
+		"slug":         page.Slug,
 		"body":         page.Body,
 		"is_published": page.IsPublished,
+		"publish_at":   page.PublishAt,
 	}).Error
 }
 
+// URL 页面的访问路径
+func (page *Page) URL() string {
+	return slugPath(SlugKindPage, page.Slug, page.ID)
+}
+
 func (page *Page) UpdateView() error {
 	DB := dao.GetMysqlDB()
 	return DB.Model(page).Updates(map[string]any{
@@ -49,6 +67,14 @@ func GetPageById(id uint) (*Page, error) {
 	return &page, err
 }
 
+// GetPageIdBySlug 根据 slug 查询页面ID
+func GetPageIdBySlug(slug string) (uint, error) {
+	var page Page
+	DB := dao.GetMysqlDB()
+	err := DB.Select("id").First(&page, "slug = ?", slug).Error
+	return page.ID, err
+}
+
 func ListPublishedPage() ([]*Page, error) {
 	return _listPage(true)
 }
@@ -69,6 +95,51 @@ func _listPage(published bool) ([]*Page, error) {
 	return pages, err
 }
 
+// ListPageByFilter 按发布状态分页列出页面，published 为 nil 时列出全部
+func ListPageByFilter(published *bool, pageIndex, pageSize int) ([]*Page, int64, error) {
+	var (
+		pages []*Page
+		total int64
+	)
+	DB := dao.GetMysqlDB()
+	db := DB.Model(&Page{})
+	if published != nil {
+		db = db.Where("is_published = ?", *published)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("created_at desc").Limit(pageSize).Offset((pageIndex - 1) * pageSize).Find(&pages).Error
+	return pages, total, err
+}
+
+// ListDuePages 列出已到定时发布时间但尚未发布的页面
+func ListDuePages(now time.Time) ([]*Page, error) {
+	var pages []*Page
+	DB := dao.GetMysqlDB()
+	err := DB.Where("is_published = ? and publish_at is not null and publish_at <= ?", false, now).Find(&pages).Error
+	return pages, err
+}
+
+// PublishScheduled 发布到期的定时页面，返回是否由本次调用完成发布。
+// 页面已被其他实例发布或取消定时时不修改 page
+func (page *Page) PublishScheduled() (bool, error) {
+	DB := dao.GetMysqlDB()
+	// 带条件更新，避免多实例重复发布
+	result := DB.Model(&Page{}).
+		Where("id = ? and is_published = ? and publish_at is not null", page.ID, false).
+		UpdateColumns(map[string]any{
+			"is_published": true,
+			"publish_at":   nil,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	page.IsPublished = true
+	page.PublishAt = nil
+	return true, nil
+}
+
 func CountPage() int64 {
 	var count int64
 	DB := dao.GetMysqlDB()
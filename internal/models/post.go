@@ -12,6 +12,7 @@ import (
 	"github.com/russross/blackfriday"
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"gorm.io/gorm"
 )
 
 type Post struct {
@@ -19,9 +20,13 @@ type Post struct {
 	CreatedAt    *time.Time `gorm:"autoCreateTime"`
 	UpdatedAt    *time.Time `gorm:"autoUpdateTime"`
 	Title        string     `gorm:"type:text"`
+	Slug         string     `gorm:"type:varchar(191);uniqueIndex;default:null"` // URL 别名
 	Body         string     `gorm:"type:longtext"`
 	View         int
 	IsPublished  bool
+	PublishAt    *time.Time `gorm:"index"` // 定时发布时间，为空表示不定时
+	AuthorID     uint       `gorm:"index"` // 作者ID
+	Pending      bool       // 投稿者提交、等待编辑审核发布
 	Tags         []*Tag     `gorm:"-"`
 	Comments     []*Comment `gorm:"-"`
 	CommentTotal int        `gorm:"->"`
@@ -36,7 +41,24 @@ type QrArchive struct {
 
 func (post *Post) Insert() error {
 	DB := dao.GetMysqlDB()
-	err := DB.Create(post).Error
+	post.Slug = assignSlug(SlugKindPost, 0, post.Slug, post.Title)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if post.Slug == "" {
+			// 标题无法生成 slug 时使用ID兜底
+			post.Slug = fallbackSlug(SlugKindPost, post.ID)
+			if err := tx.Model(post).UpdateColumn("slug", post.Slug).Error; err != nil {
+				return err
+			}
+		}
+		// 搜索索引任务和 webhook 投递与博文一起提交
+		if err := EnqueueSearchSync(tx, SearchSyncIndex, post.ID); err != nil {
+			return err
+		}
+		return enqueuePostWebhooks(tx, post, WebhookPostCreated, false)
+	})
 	if err != nil {
 		return err
 	}
@@ -48,15 +70,6 @@ func (post *Post) Insert() error {
 		}
 	}()
 
-	// 同步到ES索引（使用批量队列）
-	go func() {
-		AddESTask(ESTask{
-			Action: "index",
-			PostID: post.ID,
-			Post:   post,
-		})
-	}()
-
 	// 清除相关缓存
 	go post.ClearRelatedCache()
 
@@ -70,30 +83,45 @@ func (post *Post) Update() error {
 		log.Error("Failed to initiate delayed double delete", "id", post.ID, "error", err)
 	}
 
-	// 更新数据库
-	err := DB.Model(post).Updates(map[string]interface{}{
-		"title":        post.Title,
-		"body":         post.Body,
-		"is_published": post.IsPublished,
-	}).Error
+	post.Slug = assignSlug(SlugKindPost, post.ID, post.Slug, post.Title)
+
+	// 更新数据库，搜索索引任务和 webhook 投递在同一事务中提交
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// 修改前的发布状态，用于判断是否触发发布或取消发布事件
+		var previous Post
+		if err := tx.Select("is_published").Limit(1).Find(&previous, post.ID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(post).Updates(map[string]interface{}{
+			"title":        post.Title,
+			"slug":         post.Slug,
+			"body":         post.Body,
+			"is_published": post.IsPublished,
+			"publish_at":   post.PublishAt,
+			"pending":      post.Pending,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := EnqueueSearchSync(tx, SearchSyncIndex, post.ID); err != nil {
+			return err
+		}
+		return enqueuePostWebhooks(tx, post, WebhookPostUpdated, previous.IsPublished)
+	})
 	if err != nil {
 		return err
 	}
 
-	// 同步到ES索引（使用批量队列）
-	go func() {
-		AddESTask(ESTask{
-			Action: "index",
-			PostID: post.ID,
-			Post:   post,
-		})
-	}()
-
 	// 注意：延迟双删已经处理了缓存清理，不需要再调用ClearRelatedCache
 
 	return nil
 }
 
+// URL 博文的访问路径
+func (post *Post) URL() string {
+	return slugPath(SlugKindPost, post.Slug, post.ID)
+}
+
 func (post *Post) UpdateView() error {
 	DB := dao.GetMysqlDB()
 	return DB.Model(post).Updates(map[string]interface{}{
@@ -103,20 +131,28 @@ func (post *Post) UpdateView() error {
 
 func (post *Post) Delete() error {
 	DB := dao.GetMysqlDB()
-	err := DB.Delete(post).Error
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// 调用方通常只设置了ID，webhook 需要删除前的标题和地址
+		var deleted Post
+		if err := tx.Limit(1).Find(&deleted, post.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(post).Error; err != nil {
+			return err
+		}
+		// 从搜索索引中删除
+		if err := EnqueueSearchSync(tx, SearchSyncDelete, post.ID); err != nil {
+			return err
+		}
+		if deleted.ID == 0 {
+			return nil
+		}
+		return EnqueueWebhookEvent(tx, WebhookPostDeleted, newWebhookPost(&deleted))
+	})
 	if err != nil {
 		return err
 	}
 
-	// 从ES索引中删除（使用批量队列）
-	go func() {
-		AddESTask(ESTask{
-			Action: "delete",
-			PostID: post.ID,
-			Post:   nil,
-		})
-	}()
-
 	// 清除相关缓存
 	go post.ClearRelatedCache()
 
@@ -233,6 +269,7 @@ func (post *Post) ClearRelatedCache() error {
 	if err := post.DelCache(); err != nil {
 		log.Error("Failed to clear post cache", "id", post.ID, "error", err)
 	}
+	clearRelatedPostCache(post.ID)
 
 	// 清除列表缓存（影响首页、归档等）
 	patterns := []string{
@@ -261,7 +298,8 @@ func (post *Post) DelayedDoubleDel() error {
 
 	// 收集需要删除的缓存键
 	keys := []string{
-		post.CacheKey(), // 自身缓存
+		post.CacheKey(),              // 自身缓存
+		relatedPostCacheKey(post.ID), // 相关博文缓存
 	}
 
 	// 收集相关缓存模式的具体键
@@ -392,9 +430,9 @@ func LoadPostRelations(post *Post) error {
 		post.Tags = tags
 	}
 
-	// 加载评论
+	// 加载评论（树形结构）
 	if comments, err := ListCommentByPostID(post.ID); err == nil {
-		post.Comments = comments
+		post.Comments = BuildCommentTree(comments)
 		post.CommentTotal = len(comments)
 	}
 
@@ -421,6 +459,14 @@ func ListAllPost(tag string) ([]*Post, error) {
 	return _listPost(tag, false, 0, 0)
 }
 
+// ListAllPostByAuthor 列出作者的全部博文
+func ListAllPostByAuthor(authorID uint) ([]*Post, error) {
+	var posts []*Post
+	DB := dao.GetMysqlDB()
+	err := DB.Where("author_id = ?", authorID).Order("created_at desc").Find(&posts).Error
+	return posts, err
+}
+
 func _listPost(tagId string, published bool, pageIndex, pageSize int) ([]*Post, error) {
 	var posts []*Post
 	var err error
@@ -459,6 +505,84 @@ func _listPost(tagId string, published bool, pageIndex, pageSize int) ([]*Post,
 	return posts, err
 }
 
+// PostFilter 博文列表筛选条件，零值表示不筛选
+type PostFilter struct {
+	TagID     uint   // 标签ID
+	Published *bool  // 发布状态
+	Keyword   string // 标题关键字
+	AuthorID  uint   // 作者ID
+}
+
+// ListPostByFilter 按条件分页列出博文，同时返回满足条件的总数
+func ListPostByFilter(filter PostFilter, pageIndex, pageSize int) ([]*Post, int64, error) {
+	var (
+		posts []*Post
+		total int64
+	)
+	DB := dao.GetMysqlDB()
+	db := DB.Model(&Post{})
+	if filter.TagID > 0 {
+		db = db.Where("id in (select post_id from post_tags where tag_id = ?)", filter.TagID)
+	}
+	if filter.Published != nil {
+		db = db.Where("is_published = ?", *filter.Published)
+	}
+	if filter.Keyword != "" {
+		db = db.Where("title like ?", "%"+filter.Keyword+"%")
+	}
+	if filter.AuthorID > 0 {
+		db = db.Where("author_id = ?", filter.AuthorID)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("created_at desc").Limit(pageSize).Offset((pageIndex - 1) * pageSize).Find(&posts).Error
+	return posts, total, err
+}
+
+// ListDuePosts 列出已到定时发布时间但尚未发布的博文
+func ListDuePosts(now time.Time) ([]*Post, error) {
+	var posts []*Post
+	DB := dao.GetMysqlDB()
+	err := DB.Where("is_published = ? and publish_at is not null and publish_at <= ?", false, now).Find(&posts).Error
+	return posts, err
+}
+
+// PublishScheduled 发布到期的定时博文，返回是否由本次调用完成发布
+func (post *Post) PublishScheduled() (bool, error) {
+	DB := dao.GetMysqlDB()
+	published := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// 带条件更新，避免多实例重复发布
+		result := tx.Model(&Post{}).
+			Where("id = ? and is_published = ? and publish_at is not null", post.ID, false).
+			UpdateColumns(map[string]interface{}{
+				"is_published": true,
+				"publish_at":   nil,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		published = true
+		if err := EnqueueSearchSync(tx, SearchSyncIndex, post.ID); err != nil {
+			return err
+		}
+		scheduled := *post
+		scheduled.IsPublished = true
+		scheduled.PublishAt = nil
+		return EnqueueWebhookEvent(tx, WebhookPostPublished, newWebhookPost(&scheduled))
+	})
+	if err != nil || !published {
+		return false, err
+	}
+	post.IsPublished = true
+	post.PublishAt = nil
+
+	// 清除列表缓存
+	post.ClearRelatedCache()
+	return true, nil
+}
+
 func MustListMaxReadPost() (posts []*Post) {
 	posts, _ = ListMaxReadPost()
 	return
@@ -480,7 +604,7 @@ func ListMaxCommentPost() (posts []*Post, err error) {
 		rows *sql.Rows
 	)
 	DB := dao.GetMysqlDB()
-	rows, err = DB.Raw("select p.*,c.total comment_total from posts p inner join (select post_id,count(*) total from comments group by post_id) c on p.id = c.post_id order by c.total desc limit 5").Rows()
+	rows, err = DB.Raw("select p.*,c.total comment_total from posts p inner join (select post_id,count(*) total from comments where status = 'approved' group by post_id) c on p.id = c.post_id order by c.total desc limit 5").Rows()
 	if err != nil {
 		return
 	}
@@ -510,6 +634,14 @@ func CountPost() int64 {
 	return count
 }
 
+// GetPostIdBySlug 根据 slug 查询博文ID
+func GetPostIdBySlug(slug string) (uint, error) {
+	var post Post
+	DB := dao.GetMysqlDB()
+	err := DB.Select("id").First(&post, "slug = ?", slug).Error
+	return post.ID, err
+}
+
 func GetPostById(id uint) (*Post, error) {
 	var post Post
 	DB := dao.GetMysqlDB()
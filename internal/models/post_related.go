@@ -0,0 +1,463 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/fulltext"
+)
+
+// 相关博文缓存相关常量
+const (
+	RelatedPostLimit             = 5              // 博文页显示的相关博文数
+	RelatedPostCachePrefix       = "related_post" // 相关博文ID缓存key前缀
+	RelatedPostCacheExpiration   = 6 * time.Hour  // 共同浏览随时间变化，缓存过期后重新计算
+	RelatedPostCacheRandomOffset = time.Hour      // 防雪崩：±1小时
+
+	CoViewCachePrefix = "post_coview"       // 共同浏览计数key前缀，每篇博文一个有序集合
+	CoViewExpiration  = 30 * 24 * time.Hour // 超过30天没有新的共同浏览时清除
+)
+
+const (
+	relatedCandidateSize = 20   // 每种信号取的候选博文数
+	relatedMaxQueryTerms = 25   // 内容相似度最多使用的关键词数，与 ES more_like_this 的默认值一致
+	relatedCorpusSize    = 1000 // 数据库计算 TF-IDF 时参与比较的最近博文数
+	relatedTitleWeight   = 3    // 标题中的词条按 3 倍计入，与搜索时标题的权重一致
+	relatedLocalSize     = 1000 // 进程内缓存最多保存的博文数
+
+	// 各信号归一化到 0~1 后的权重
+	relatedContentWeight = 0.5
+	relatedTagWeight     = 0.3
+	relatedCoViewWeight  = 0.2
+)
+
+// MustListRelatedPosts 与博文相关的博文，出错时返回空
+func MustListRelatedPosts(post *Post) []*Post {
+	posts, _ := ListRelatedPosts(post)
+	return posts
+}
+
+// ListRelatedPosts 与博文相关的已发布博文，综合内容相似度、共同标签和共同浏览排序。
+// 缓存中只保存博文ID，标题等信息每次从数据库读取，相关博文修改或下线后不会显示旧内容
+func ListRelatedPosts(post *Post) ([]*Post, error) {
+	ids, err := getRelatedPostCache(post.ID)
+	if err != nil {
+		ids = computeRelatedPostIDs(post, RelatedPostLimit)
+		setRelatedPostCache(post.ID, ids)
+	}
+	return listPublishedPostsByIDs(ids)
+}
+
+// relatedPostCacheKey 相关博文缓存key
+func relatedPostCacheKey(postID uint) string {
+	return dao.GenerateKey(RelatedPostCachePrefix, postID)
+}
+
+// relatedLocalEntry 进程内缓存的相关博文ID
+type relatedLocalEntry struct {
+	ids       []uint
+	expiresAt time.Time
+}
+
+// relatedLocalCache Redis 不可用时在进程内缓存相关博文ID，避免每次访问都重新计算内容相似度
+var relatedLocalCache = struct {
+	sync.Mutex
+	entries map[uint]relatedLocalEntry
+}{entries: make(map[uint]relatedLocalEntry)}
+
+// getRelatedLocalCache 从进程内缓存读取相关博文ID
+func getRelatedLocalCache(postID uint) ([]uint, error) {
+	relatedLocalCache.Lock()
+	defer relatedLocalCache.Unlock()
+	entry, ok := relatedLocalCache.entries[postID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, fmt.Errorf("cache miss")
+	}
+	return entry.ids, nil
+}
+
+// setRelatedLocalCache 写入进程内缓存，超过容量时先清理过期的记录，仍然超过则全部清空
+func setRelatedLocalCache(postID uint, ids []uint, expiration time.Duration) {
+	relatedLocalCache.Lock()
+	defer relatedLocalCache.Unlock()
+	now := time.Now()
+	if len(relatedLocalCache.entries) >= relatedLocalSize {
+		for id, entry := range relatedLocalCache.entries {
+			if now.After(entry.expiresAt) {
+				delete(relatedLocalCache.entries, id)
+			}
+		}
+		if len(relatedLocalCache.entries) >= relatedLocalSize {
+			clear(relatedLocalCache.entries)
+		}
+	}
+	relatedLocalCache.entries[postID] = relatedLocalEntry{ids: ids, expiresAt: now.Add(expiration)}
+}
+
+// getRelatedPostCache 从缓存读取相关博文ID，Redis 不可用时读取进程内缓存
+func getRelatedPostCache(postID uint) ([]uint, error) {
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() {
+		return getRelatedLocalCache(postID)
+	}
+	var ids []uint
+	if err := Redis.Get(relatedPostCacheKey(postID), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// setRelatedPostCache 缓存相关博文ID，没有相关博文时也缓存空列表，避免每次访问都重新计算。
+// Redis 不可用时写入进程内缓存
+func setRelatedPostCache(postID uint, ids []uint) {
+	if ids == nil {
+		ids = []uint{}
+	}
+	// 使用随机过期时间防止缓存雪崩
+	randomExpiration := getRandomExpiration(RelatedPostCacheExpiration, RelatedPostCacheRandomOffset)
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() {
+		setRelatedLocalCache(postID, ids, randomExpiration)
+		return
+	}
+	if err := Redis.Set(relatedPostCacheKey(postID), ids, randomExpiration); err != nil {
+		log.Error("Failed to cache related posts", "id", postID, "error", err)
+	}
+}
+
+// clearRelatedPostCache 删除博文的相关博文缓存，进程内缓存一并删除
+func clearRelatedPostCache(postIDs ...uint) {
+	relatedLocalCache.Lock()
+	for _, id := range postIDs {
+		delete(relatedLocalCache.entries, id)
+	}
+	relatedLocalCache.Unlock()
+
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() || len(postIDs) == 0 {
+		return
+	}
+	keys := make([]string, len(postIDs))
+	for i, id := range postIDs {
+		keys[i] = relatedPostCacheKey(id)
+	}
+	if err := Redis.Del(keys...); err != nil {
+		log.Error("Failed to clear related post cache", "ids", postIDs, "error", err)
+	}
+}
+
+// computeRelatedPostIDs 计算相关博文：各信号的分数按最高分归一化后加权求和，取已发布的前 limit 篇
+func computeRelatedPostIDs(post *Post, limit int) []uint {
+	engine := CurrentSearchEngine()
+	content, err := engine.MoreLikeThis(post, relatedCandidateSize)
+	if err != nil && engine.Name() != SearchEngineDatabase {
+		log.Warn("搜索引擎计算相似博文失败，降级到数据库计算", "engine", engine.Name(), "post_id", post.ID, "error", err)
+		content, err = tfidfMoreLikeThis(post, relatedCandidateSize)
+	}
+	if err != nil {
+		log.Error("计算相似博文失败", "post_id", post.ID, "error", err)
+	}
+	tags, err := sharedTagPostScores(post.ID, relatedCandidateSize)
+	if err != nil {
+		log.Error("查询共同标签的博文失败", "post_id", post.ID, "error", err)
+	}
+	coViews, err := coViewedPostScores(post.ID, relatedCandidateSize)
+	if err != nil {
+		log.Warn("读取共同浏览记录失败", "post_id", post.ID, "error", err)
+	}
+
+	scores := make(map[uint]float64)
+	addNormalizedScores(scores, content, relatedContentWeight)
+	addNormalizedScores(scores, tags, relatedTagWeight)
+	addNormalizedScores(scores, coViews, relatedCoViewWeight)
+	delete(scores, post.ID)
+	if len(scores) == 0 {
+		return nil
+	}
+
+	// 共同浏览记录中可能有已下线或删除的博文
+	candidates := make([]uint, 0, len(scores))
+	for id := range scores {
+		candidates = append(candidates, id)
+	}
+	var ids []uint
+	DB := dao.GetMysqlDB()
+	if err := DB.Model(&Post{}).Where("id IN ? AND is_published = ?", candidates, true).Pluck("id", &ids).Error; err != nil {
+		log.Error("查询相关博文失败", "post_id", post.ID, "error", err)
+		return nil
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids
+}
+
+// addNormalizedScores 把一种信号的分数除以其中的最高分后按权重累加
+func addNormalizedScores(scores map[uint]float64, signal map[uint]float64, weight float64) {
+	best := 0.0
+	for _, score := range signal {
+		best = math.Max(best, score)
+	}
+	if best <= 0 {
+		return
+	}
+	for id, score := range signal {
+		scores[id] += weight * score / best
+	}
+}
+
+// listPublishedPostsByIDs 按给定顺序读取已发布的博文，只读取列表显示需要的字段，
+// updated_at 用于静态站点判断相关博文是否变化
+func listPublishedPostsByIDs(ids []uint) ([]*Post, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var found []*Post
+	DB := dao.GetMysqlDB()
+	err := DB.Select("id", "title", "slug", "view", "created_at", "updated_at").
+		Where("id IN ? AND is_published = ?", ids, true).
+		Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+	posts := make([]*Post, 0, len(found))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+// ========== 共同标签 ==========
+
+// sharedTagPostScores 与博文有共同标签的已发布博文及共同标签数
+func sharedTagPostScores(postID uint, limit int) (map[uint]float64, error) {
+	var rows []struct {
+		PostID uint
+		Shared int
+	}
+	DB := dao.GetMysqlDB()
+	err := DB.Raw(`select pt2.post_id, count(*) shared from post_tags pt1
+		inner join post_tags pt2 on pt2.tag_id = pt1.tag_id and pt2.post_id <> pt1.post_id
+		inner join posts p on p.id = pt2.post_id
+		where pt1.post_id = ? and p.is_published = ?
+		group by pt2.post_id order by shared desc, pt2.post_id desc limit ?`, postID, true, limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		scores[row.PostID] = float64(row.Shared)
+	}
+	return scores, nil
+}
+
+// ========== 共同浏览 ==========
+
+// coViewCacheKey 博文的共同浏览计数key
+func coViewCacheKey(postID uint) string {
+	return dao.GenerateKey(CoViewCachePrefix, postID)
+}
+
+// RecordPostCoViews 记录同一访客先后浏览的博文，viewed 是该访客在本次会话中此前浏览过的博文。
+// 两篇博文的共同浏览计数各自加一，Redis 不可用时不记录
+func RecordPostCoViews(postID uint, viewed []uint) {
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() {
+		return
+	}
+	for _, other := range viewed {
+		if other == postID {
+			continue
+		}
+		if err := Redis.ZIncrBy(coViewCacheKey(postID), strconv.FormatUint(uint64(other), 10), 1, CoViewExpiration); err != nil {
+			return
+		}
+		if err := Redis.ZIncrBy(coViewCacheKey(other), strconv.FormatUint(uint64(postID), 10), 1, CoViewExpiration); err != nil {
+			return
+		}
+	}
+}
+
+// coViewedPostScores 与博文共同浏览次数最多的博文及次数
+func coViewedPostScores(postID uint, limit int) (map[uint]float64, error) {
+	Redis := dao.GetRedis()
+	if Redis == nil || !Redis.IsAvailable() {
+		return nil, nil
+	}
+	members, err := Redis.ZTopScores(coViewCacheKey(postID), limit)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64, len(members))
+	for member, score := range members {
+		if id, err := strconv.ParseUint(member, 10, 64); err == nil {
+			scores[uint(id)] = score
+		}
+	}
+	return scores, nil
+}
+
+// ========== 内容相似度 ==========
+
+// esMoreLikeThis 使用 ES more_like_this 查询按标题和正文找出相似的博文
+func esMoreLikeThis(post *Post, limit int) (map[uint]float64, error) {
+	if !dao.IsESAvailable() {
+		return nil, fmt.Errorf("ES不可用")
+	}
+
+	// 使用博文当前内容作为查询文档，不依赖索引中的文档是否已同步
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"more_like_this": map[string]interface{}{
+						"fields": []string{"title", "body"},
+						"like": []map[string]interface{}{
+							{"doc": map[string]interface{}{"title": post.Title, "body": post.Body}},
+						},
+						"min_term_freq":   1,
+						"min_doc_freq":    1,
+						"max_query_terms": relatedMaxQueryTerms,
+					},
+				},
+				"filter":   []map[string]interface{}{{"term": map[string]interface{}{"is_published": true}}},
+				"must_not": []map[string]interface{}{{"ids": map[string]interface{}{"values": []string{strconv.FormatUint(uint64(post.ID), 10)}}}},
+			},
+		},
+		"_source": []string{"id"},
+		"size":    limit,
+	}
+
+	queryJSON, _ := json.Marshal(query)
+	indexName := config.GetConfiguration().GetElasticsearchIndexName()
+	res, err := dao.ESClient.Search(
+		dao.ESClient.Search.WithIndex(indexName),
+		dao.ESClient.Search.WithBody(strings.NewReader(string(queryJSON))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("ES相似博文查询返回错误: %s", res.Status())
+	}
+
+	var esResp ESResponse
+	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		return nil, fmt.Errorf("解析ES响应失败: %w", err)
+	}
+	scores := make(map[uint]float64, len(esResp.Hits.Hits))
+	for _, hit := range esResp.Hits.Hits {
+		scores[hit.Source.ID] = hit.Score
+	}
+	return scores, nil
+}
+
+// tfidfMoreLikeThis 没有搜索索引时在数据库中计算内容相似度：读取最近发布的博文，
+// 以标题和正文的 TF-IDF 向量的余弦相似度作为分数
+func tfidfMoreLikeThis(post *Post, limit int) (map[uint]float64, error) {
+	var corpus []*Post
+	DB := dao.GetMysqlDB()
+	err := DB.Select("id", "title", "body").
+		Where("is_published = ? AND id <> ?", true, post.ID).
+		Order("id desc").
+		Limit(relatedCorpusSize).
+		Find(&corpus).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询博文失败: %w", err)
+	}
+
+	target := postTermFrequencies(post)
+	vectors := make([]map[string]float64, len(corpus))
+	df := make(map[string]int)
+	for term := range target {
+		df[term]++
+	}
+	for i, other := range corpus {
+		vectors[i] = postTermFrequencies(other)
+		for term := range vectors[i] {
+			df[term]++
+		}
+	}
+
+	total := float64(len(corpus) + 1)
+	idf := func(term string) float64 {
+		return math.Log(total / float64(df[term]))
+	}
+	weigh := func(tf map[string]float64) float64 {
+		norm := 0.0
+		for term, freq := range tf {
+			tf[term] = (1 + math.Log(freq)) * idf(term)
+			norm += tf[term] * tf[term]
+		}
+		return math.Sqrt(norm)
+	}
+
+	targetNorm := weigh(target)
+	if targetNorm == 0 {
+		return nil, nil
+	}
+	type similarity struct {
+		id    uint
+		score float64
+	}
+	similarities := make([]similarity, 0, len(corpus))
+	for i, other := range corpus {
+		norm := weigh(vectors[i])
+		if norm == 0 {
+			continue
+		}
+		dot := 0.0
+		for term, weight := range target {
+			dot += weight * vectors[i][term]
+		}
+		if dot > 0 {
+			similarities = append(similarities, similarity{other.ID, dot / (targetNorm * norm)})
+		}
+	}
+	sort.Slice(similarities, func(i, j int) bool {
+		return similarities[i].score > similarities[j].score
+	})
+	if len(similarities) > limit {
+		similarities = similarities[:limit]
+	}
+	scores := make(map[uint]float64, len(similarities))
+	for _, s := range similarities {
+		scores[s.id] = s.score
+	}
+	return scores, nil
+}
+
+// postTermFrequencies 博文标题和正文的词频，标题中的词条按 relatedTitleWeight 倍计入
+func postTermFrequencies(post *Post) map[string]float64 {
+	tf := make(map[string]float64)
+	for term, count := range fulltext.TermFrequencies(post.Title) {
+		tf[term] += float64(count * relatedTitleWeight)
+	}
+	for term, count := range fulltext.TermFrequencies(post.Body) {
+		tf[term] += float64(count)
+	}
+	return tf
+}
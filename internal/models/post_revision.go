@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+)
+
+// PostRevision 博文修订记录，每次保存博文时生成一份快照
+type PostRevision struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	PostID    uint       `gorm:"index"` // post id
+	UserID    uint       // 保存该版本的用户
+	Author    string     // 保存该版本的用户名称（快照）
+	Title     string     `gorm:"type:text"`         // title
+	Body      string     `gorm:"type:longtext"`     // body
+	TagIds    string     `gorm:"type:varchar(512)"` // 标签ID，逗号分隔
+	TagNames  string     `gorm:"type:text"`         // 标签名称，逗号分隔
+}
+
+func (revision *PostRevision) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(revision).Error
+}
+
+// ListPostRevisions 按时间倒序列出博文的所有修订
+func ListPostRevisions(postID uint) ([]*PostRevision, error) {
+	var revisions []*PostRevision
+	DB := dao.GetMysqlDB()
+	err := DB.Where("post_id = ?", postID).Order("id desc").Find(&revisions).Error
+	return revisions, err
+}
+
+func GetPostRevisionById(id uint) (*PostRevision, error) {
+	var revision PostRevision
+	DB := dao.GetMysqlDB()
+	err := DB.First(&revision, "id = ?", id).Error
+	return &revision, err
+}
+
+func CountPostRevisions(postID uint) int64 {
+	var count int64
+	DB := dao.GetMysqlDB()
+	DB.Model(&PostRevision{}).Where("post_id = ?", postID).Count(&count)
+	return count
+}
+
+func DeletePostRevisionsByPostId(postID uint) error {
+	DB := dao.GetMysqlDB()
+	return DB.Delete(&PostRevision{}, "post_id = ?", postID).Error
+}
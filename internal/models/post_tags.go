@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
 )
 
 type PostTag struct {
@@ -24,3 +25,20 @@ func DeletePostTagByPostId(postId uint) error {
 	DB := dao.GetMysqlDB()
 	return DB.Delete(&PostTag{}, "post_id = ?", postId).Error
 }
+
+// SetPostTags 重置博文标签，并在同一事务中记录搜索同步任务，索引中的标签随之更新
+func SetPostTags(postId uint, tagIds []uint) error {
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&PostTag{}, "post_id = ?", postId).Error; err != nil {
+			return err
+		}
+		for _, tagId := range tagIds {
+			pt := &PostTag{PostId: postId, TagId: tagId}
+			if err := tx.FirstOrCreate(pt, "post_id = ? and tag_id = ?", postId, tagId).Error; err != nil {
+				return err
+			}
+		}
+		return EnqueueSearchSync(tx, SearchSyncIndex, postId)
+	})
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeCount 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// RecoveryCode 两步验证恢复码，丢失验证器时用于登录，每个只能使用一次
+type RecoveryCode struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	UserID    uint       `gorm:"index"`
+	CodeHash  string     `gorm:"type:char(64);index"` // sha256(规范化后的恢复码)
+	UsedAt    *time.Time
+}
+
+// hashRecoveryCode 忽略大小写、空格和连字符后计算哈希
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateRecoveryCodes 作废用户原有的恢复码并生成一组新的，返回的明文只在生成时可见
+func GenerateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	rows := make([]*RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		plain := hex.EncodeToString(buf)
+		code := plain[:5] + "-" + plain[5:]
+		codes = append(codes, code)
+		rows = append(rows, &RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+	DB := dao.GetMysqlDB()
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode 使用恢复码，成功时将其标记为已使用
+func UseRecoveryCode(userID uint, code string) (bool, error) {
+	DB := dao.GetMysqlDB()
+	now := time.Now()
+	result := DB.Model(&RecoveryCode{}).
+		Where("user_id = ? and code_hash = ? and used_at is null", userID, hashRecoveryCode(code)).
+		Limit(1).
+		Update("used_at", &now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnusedRecoveryCodes 用户剩余可用的恢复码数量
+func CountUnusedRecoveryCodes(userID uint) int64 {
+	var count int64
+	DB := dao.GetMysqlDB()
+	DB.Model(&RecoveryCode{}).Where("user_id = ? and used_at is null", userID).Count(&count)
+	return count
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"gorm.io/gorm"
+)
+
+// 用户角色，权限依次递减
+const (
+	RoleAdmin       = "admin"       // 管理员：全部权限
+	RoleEditor      = "editor"      // 编辑：管理并发布所有内容
+	RoleAuthor      = "author"      // 作者：撰写并发布自己的博文
+	RoleContributor = "contributor" // 投稿者：撰写博文草稿并提交审核
+	RoleReader      = "reader"      // 读者：只能评论
+)
+
+// 权限
+const (
+	PermAccessAdmin    = "admin:access"     // 进入管理后台
+	PermWritePost      = "post:write"       // 撰写、修改自己的博文
+	PermPublishPost    = "post:publish"     // 发布自己的博文
+	PermEditOthersPost = "post:edit_others" // 修改、发布、删除他人的博文
+	PermManageContent  = "content:manage"   // 管理页面、标签、评论、友情链接、订阅者和导入导出
+	PermManageSystem   = "system:manage"    // 管理用户、角色、备份、邮件等系统功能
+)
+
+// Roles 全部角色，按权限从高到低排列
+var Roles = []string{RoleAdmin, RoleEditor, RoleAuthor, RoleContributor, RoleReader}
+
+// RoleNames 角色的显示名称
+var RoleNames = map[string]string{
+	RoleAdmin:       "管理员",
+	RoleEditor:      "编辑",
+	RoleAuthor:      "作者",
+	RoleContributor: "投稿者",
+	RoleReader:      "读者",
+}
+
+// rolePermissions 权限矩阵
+var rolePermissions = map[string][]string{
+	RoleAdmin:       {PermAccessAdmin, PermWritePost, PermPublishPost, PermEditOthersPost, PermManageContent, PermManageSystem},
+	RoleEditor:      {PermAccessAdmin, PermWritePost, PermPublishPost, PermEditOthersPost, PermManageContent},
+	RoleAuthor:      {PermAccessAdmin, PermWritePost, PermPublishPost},
+	RoleContributor: {PermAccessAdmin, PermWritePost},
+	RoleReader:      {},
+}
+
+// IsValidRole 判断角色是否合法
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission 判断角色是否拥有指定权限
+func RoleHasPermission(role, perm string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Can 判断用户是否拥有指定权限
+func (user *User) Can(perm string) bool {
+	return RoleHasPermission(user.Role, perm)
+}
+
+// RoleName 用户角色的显示名称
+func (user *User) RoleName() string {
+	if name, ok := RoleNames[user.Role]; ok {
+		return name
+	}
+	return user.Role
+}
+
+// CanEditPost 判断用户能否修改、删除博文
+// 作者和投稿者只能修改自己的博文，投稿者的博文发布后不能再修改
+func (user *User) CanEditPost(post *Post) bool {
+	if user.Can(PermEditOthersPost) {
+		return true
+	}
+	if !user.Can(PermWritePost) || post.AuthorID != user.ID {
+		return false
+	}
+	return !post.IsPublished || user.Can(PermPublishPost)
+}
+
+// CanPublishPost 判断用户能否发布或撤回博文
+func (user *User) CanPublishPost(post *Post) bool {
+	if user.Can(PermEditOthersPost) {
+		return true
+	}
+	return user.Can(PermPublishPost) && post.AuthorID == user.ID
+}
+
+// migrateRoles 兼容引入角色之前的数据，没有作者的博文归属于最早的管理员
+func migrateRoles(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&User{}).Where("role = ?", RoleAdmin).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := promoteInitialAdmin(db); err != nil {
+			return err
+		}
+	}
+	var admin User
+	err := db.Where("role = ?", RoleAdmin).Order("id").Limit(1).Find(&admin).Error
+	if err != nil || admin.ID == 0 {
+		return err
+	}
+	return db.Model(&Post{}).Where("author_id = ?", 0).UpdateColumn("author_id", admin.ID).Error
+}
+
+// promoteInitialAdmin 还没有管理员时指定一个管理员。旧版本注册时所有用户都带有 is_admin 标记，
+// 不能据此判断，只升级 admin_email 指定的账号，未配置时升级最早注册的用户，其余用户都是读者
+func promoteInitialAdmin(db *gorm.DB) error {
+	var admin User
+	query := db.Where("deleted_at is null").Order("id").Limit(1)
+	email := config.GetConfiguration().AdminEmail
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if err := query.Find(&admin).Error; err != nil {
+		return err
+	}
+	if admin.ID == 0 {
+		if email != "" {
+			log.Warn("admin_email 对应的用户不存在，没有设置管理员", "email", email)
+		}
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).
+			Where("id <> ?", admin.ID).
+			Updates(map[string]interface{}{"role": RoleReader, "is_admin": false}).Error
+		if err != nil {
+			return err
+		}
+		log.Info("已设置管理员", "id", admin.ID, "email", admin.Email)
+		return tx.Model(&admin).Updates(map[string]interface{}{"role": RoleAdmin, "is_admin": true}).Error
+	})
+}
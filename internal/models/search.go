@@ -3,15 +3,17 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"html/template"
 
 	"strings"
-	"sync"
 	"time"
+	"unicode"
 
 	"github.com/elastic/go-elasticsearch/v8/esapi"
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"gorm.io/gorm"
 )
 
 // 增量同步状态表
@@ -37,6 +39,7 @@ type PostDocument struct {
 	ViewCount    int       `json:"view_count"`
 	CommentCount int       `json:"comment_count"`
 	Excerpt      string    `json:"excerpt"`
+	Checksum     string    `json:"checksum"` // 参与索引的内容的校验和，用于一致性检查
 }
 
 // SearchRequest 搜索请求结构
@@ -48,15 +51,20 @@ type SearchRequest struct {
 	SortBy   string   `json:"sort_by"` // relevance, date, views
 	DateFrom string   `json:"date_from,omitempty"`
 	DateTo   string   `json:"date_to,omitempty"`
+	Month    string   `json:"month,omitempty"` // 按月份筛选，格式 2006-01
+	Views    string   `json:"views,omitempty"` // 按浏览量区间筛选，取值见 ViewRanges
 }
 
 // SearchResponse 搜索响应结构
 type SearchResponse struct {
-	Posts       []*Post  `json:"posts"` // 直接使用Post模型
-	Total       int64    `json:"total"`
-	MaxScore    float64  `json:"max_score"`
-	Took        int      `json:"took"`
-	Suggestions []string `json:"suggestions,omitempty"`
+	Posts       []*Post       `json:"posts"` // 直接使用Post模型
+	Total       int64         `json:"total"`
+	MaxScore    float64       `json:"max_score"`
+	Took        int           `json:"took"`
+	Suggestions []string      `json:"suggestions,omitempty"`
+	Facets      *SearchFacets `json:"facets,omitempty"` // 当前结果的标签、月份和浏览量分布
+	// Highlights 博文正文中命中关键词的片段，已转义并用 <mark> 标记关键词
+	Highlights map[uint]template.HTML `json:"highlights,omitempty"`
 }
 
 // ESResponse ES原始响应结构（用于解析）
@@ -74,6 +82,20 @@ type ESResponse struct {
 			Highlight map[string][]string `json:"highlight,omitempty"`
 		} `json:"hits"`
 	} `json:"hits"`
+	Aggregations struct {
+		Tags   esBucketAgg `json:"tags"`
+		Months esBucketAgg `json:"months"`
+		Views  esBucketAgg `json:"views"`
+	} `json:"aggregations"`
+}
+
+// esBucketAgg ES 分桶聚合结果
+type esBucketAgg struct {
+	Buckets []struct {
+		Key         interface{} `json:"key"`
+		KeyAsString string      `json:"key_as_string"`
+		DocCount    int64       `json:"doc_count"`
+	} `json:"buckets"`
 }
 
 // IndexPost 将博文索引到ES
@@ -100,30 +122,26 @@ func IndexPost(post *Post) error {
 	}
 
 	// 获取标签
-	if tags, err := ListTagByPostId(post.ID); err == nil {
-		tagNames := make([]string, len(tags))
-		for i, tag := range tags {
-			tagNames[i] = tag.Name
-		}
-		doc.Tags = tagNames
-	}
+	doc.Tags = postTagNames(post.ID)
+	doc.Checksum = postChecksum(post, doc.Tags)
 
 	docJSON, err := json.Marshal(doc)
 	if err != nil {
 		return err
 	}
 
-	indexName := config.GetConfiguration().GetElasticsearchIndexName()
-	_, err = dao.ESClient.Index(
-		indexName,
-		strings.NewReader(string(docJSON)),
-		dao.ESClient.Index.WithDocumentID(fmt.Sprintf("%d", post.ID)),
-		dao.ESClient.Index.WithRefresh("true"),
-	)
+	for _, indexName := range postWriteIndices() {
+		_, err = dao.ESClient.Index(
+			indexName,
+			strings.NewReader(string(docJSON)),
+			dao.ESClient.Index.WithDocumentID(fmt.Sprintf("%d", post.ID)),
+			dao.ESClient.Index.WithRefresh("true"),
+		)
 
-	if err != nil {
-		log.Error("博文索引失败", "post_id", post.ID, "error", err)
-		return err
+		if err != nil {
+			log.Error("博文索引失败", "post_id", post.ID, "index", indexName, "error", err)
+			return err
+		}
 	}
 
 	log.Debug("博文索引成功", "post_id", post.ID)
@@ -141,16 +159,18 @@ func DeletePostFromIndex(postID uint) error {
 		return nil
 	}
 
-	indexName := config.GetConfiguration().GetElasticsearchIndexName()
-	_, err := dao.ESClient.Delete(
-		indexName,
-		fmt.Sprintf("%d", postID),
-		dao.ESClient.Delete.WithRefresh("true"),
-	)
+	rememberRebuildDeletes(postID)
+	for _, indexName := range postWriteIndices() {
+		_, err := dao.ESClient.Delete(
+			indexName,
+			fmt.Sprintf("%d", postID),
+			dao.ESClient.Delete.WithRefresh("true"),
+		)
 
-	if err != nil {
-		log.Error("博文删除失败", "post_id", postID, "error", err)
-		return err
+		if err != nil {
+			log.Error("博文删除失败", "post_id", postID, "index", indexName, "error", err)
+			return err
+		}
 	}
 
 	log.Debug("博文删除成功", "post_id", postID)
@@ -165,15 +185,21 @@ func generateExcerpt(body string, maxLen int) string {
 	return body[:maxLen] + "..."
 }
 
-// SearchPosts 搜索博文
+// SearchPosts 使用当前搜索引擎搜索博文，引擎出错时降级到数据库搜索
 func SearchPosts(req *SearchRequest) (*SearchResponse, error) {
-	if !config.GetConfiguration().Elasticsearch.Enabled {
-		return searchPostsFromDB(req) // 降级到数据库搜索
+	engine := CurrentSearchEngine()
+	resp, err := engine.Search(req)
+	if err != nil && engine.Name() != SearchEngineDatabase {
+		log.Error("搜索失败，降级到数据库搜索", "engine", engine.Name(), "error", err)
+		return searchPostsFromDB(req)
 	}
+	return resp, err
+}
 
+// searchPostsFromES 使用ES搜索博文
+func searchPostsFromES(req *SearchRequest) (*SearchResponse, error) {
 	if !dao.IsESAvailable() {
-		log.Warn("ES不可用，降级到数据库搜索")
-		return searchPostsFromDB(req)
+		return nil, fmt.Errorf("ES不可用")
 	}
 
 	// 构建ES查询
@@ -188,10 +214,12 @@ func SearchPosts(req *SearchRequest) (*SearchResponse, error) {
 	)
 
 	if err != nil {
-		log.Error("ES搜索失败，降级到数据库搜索", "error", err)
-		return searchPostsFromDB(req)
+		return nil, err
 	}
 	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("ES搜索返回错误: %s", res.Status())
+	}
 
 	// 解析ES响应
 	return parseSearchResponse(res)
@@ -226,34 +254,37 @@ func buildSearchQuery(req *SearchRequest) string {
 			},
 			"pre_tags":  []string{"<mark>"},
 			"post_tags": []string{"</mark>"},
+			"encoder":   "html", // 转义正文中的 HTML，片段可以直接输出到页面
 		},
 	}
 
 	// 添加搜索条件
 	if req.Query != "" {
 		mustQueries := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["must"].([]map[string]interface{})
+		multiMatch := map[string]interface{}{
+			"query":    req.Query,
+			"fields":   []string{"title^3", "body^1", "tags^2"},
+			"type":     "best_fields",
+			"operator": "and",
+		}
+		// 模糊匹配按字符编辑距离计算，对切分后的中文词条只会带来误匹配
+		if !containsCJK(req.Query) {
+			multiMatch["fuzziness"] = "AUTO"
+		}
 		mustQueries = append(mustQueries, map[string]interface{}{
-			"multi_match": map[string]interface{}{
-				"query":     req.Query,
-				"fields":    []string{"title^3", "body^1", "tags^2"},
-				"type":      "best_fields",
-				"operator":  "and",
-				"fuzziness": "AUTO",
-			},
+			"multi_match": multiMatch,
 		})
 		query["query"].(map[string]interface{})["bool"].(map[string]interface{})["must"] = mustQueries
 	}
 
-	// 添加标签过滤
-	if len(req.Tags) > 0 {
-		filterQueries := []map[string]interface{}{
-			{
-				"terms": map[string]interface{}{
-					"tags": req.Tags,
-				},
+	// 添加标签过滤，选中多个标签时要求同时包含
+	var filters []map[string]interface{}
+	for _, tag := range req.Tags {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{
+				"tags": tag,
 			},
-		}
-		query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"] = filterQueries
+		})
 	}
 
 	// 添加日期范围过滤
@@ -265,21 +296,37 @@ func buildSearchQuery(req *SearchRequest) string {
 		if req.DateTo != "" {
 			dateRange["lte"] = req.DateTo
 		}
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				"created_at": dateRange,
+			},
+		})
+	}
 
-		if len(dateRange) > 0 {
-			existingFilters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"]
-			if existingFilters == nil {
-				existingFilters = []map[string]interface{}{}
-			}
-			filters := existingFilters.([]map[string]interface{})
-			filters = append(filters, map[string]interface{}{
-				"range": map[string]interface{}{
-					"created_at": dateRange,
+	// 添加月份和浏览量过滤
+	if start, end, ok := parseSearchMonth(req.Month); ok {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				"created_at": map[string]interface{}{
+					"gte": start.Format(time.RFC3339),
+					"lt":  end.Format(time.RFC3339),
 				},
-			})
-			query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"] = filters
-		}
+			},
+		})
+	}
+	if viewRange, ok := GetViewRange(req.Views); ok {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{
+				"view_count": viewRange.esRange(),
+			},
+		})
 	}
+	if len(filters) > 0 {
+		query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"] = filters
+	}
+
+	// 分面统计
+	query["aggs"] = searchFacetAggs()
 
 	// 添加排序
 	switch req.SortBy {
@@ -310,6 +357,7 @@ func parseSearchResponse(res *esapi.Response) (*SearchResponse, error) {
 	}
 
 	posts := make([]*Post, 0, len(esResp.Hits.Hits))
+	highlights := make(map[uint]template.HTML)
 
 	for _, hit := range esResp.Hits.Hits {
 		// 从数据库获取完整的Post对象（确保数据一致性）
@@ -324,20 +372,21 @@ func parseSearchResponse(res *esapi.Response) (*SearchResponse, error) {
 			log.Warn("加载博文关联数据失败", "id", post.ID, "error", err)
 		}
 
-		// 添加高亮信息（如果需要的话）
-		if len(hit.Highlight) > 0 {
-			// 可以在这里处理高亮信息，比如设置到Post的某个字段
-			log.Debug("搜索高亮", "post_id", post.ID, "highlights", hit.Highlight)
+		// 正文高亮片段
+		if fragments := hit.Highlight["body"]; len(fragments) > 0 {
+			highlights[post.ID] = template.HTML(strings.Join(fragments, " … "))
 		}
 
 		posts = append(posts, post)
 	}
 
 	return &SearchResponse{
-		Posts:    posts,
-		Total:    esResp.Hits.Total.Value,
-		MaxScore: esResp.Hits.MaxScore,
-		Took:     esResp.Took,
+		Posts:      posts,
+		Total:      esResp.Hits.Total.Value,
+		MaxScore:   esResp.Hits.MaxScore,
+		Took:       esResp.Took,
+		Facets:     parseSearchFacets(&esResp),
+		Highlights: highlights,
 	}, nil
 }
 
@@ -347,29 +396,13 @@ func searchPostsFromDB(req *SearchRequest) (*SearchResponse, error) {
 
 	var posts []*Post
 	var total int64
-	DB := dao.GetMysqlDB()
 
-	query := DB.Model(&Post{}).Where("is_published = ?", true)
-
-	// 添加关键词搜索
 	if req.Query != "" {
-		searchPattern := "%" + req.Query + "%"
-		query = query.Where("title LIKE ? OR body LIKE ?", searchPattern, searchPattern)
-		log.Info("数据库搜索", "keyword", req.Query, "pattern", searchPattern)
-		fmt.Printf("添加关键词搜索: pattern='%s'\n", searchPattern)
+		log.Info("数据库搜索", "keyword", req.Query)
 	} else {
 		fmt.Printf("空关键词搜索，显示所有文章\n")
 	}
-
-	// 添加标签过滤
-	if len(req.Tags) > 0 {
-		// 通过关联表查询
-		subQuery := DB.Table("post_tags pt").
-			Select("pt.post_id").
-			Joins("JOIN tags t ON t.id = pt.tag_id").
-			Where("t.name IN ?", req.Tags)
-		query = query.Where("id IN (?)", subQuery)
-	}
+	query := searchPostQuery(req)
 
 	// 计算总数
 	query.Count(&total)
@@ -405,30 +438,150 @@ func searchPostsFromDB(req *SearchRequest) (*SearchResponse, error) {
 		}
 	}
 
+	facets, err := searchFacetsFromDB(req)
+	if err != nil {
+		log.Warn("统计搜索分面失败", "error", err)
+	}
+
 	return &SearchResponse{
-		Posts: posts,
-		Total: total,
-		Took:  0, // 数据库查询不统计时间
+		Posts:  posts,
+		Total:  total,
+		Took:   0, // 数据库查询不统计时间
+		Facets: facets,
 	}, nil
 }
 
-// GetSearchSuggestions 获取搜索建议
+// searchPostQuery 按搜索条件过滤已发布博文的数据库查询，每次调用返回新的查询
+func searchPostQuery(req *SearchRequest) *gorm.DB {
+	DB := dao.GetMysqlDB()
+	query := DB.Model(&Post{}).Where("is_published = ?", true)
+
+	// 添加关键词搜索
+	if req.Query != "" {
+		searchPattern := "%" + req.Query + "%"
+		query = query.Where("title LIKE ? OR body LIKE ?", searchPattern, searchPattern)
+	}
+
+	// 添加标签过滤，选中多个标签时要求同时包含
+	if len(req.Tags) > 0 {
+		// 通过关联表查询
+		subQuery := DB.Table("post_tags pt").
+			Select("pt.post_id").
+			Joins("JOIN tags t ON t.id = pt.tag_id").
+			Where("t.name IN ?", req.Tags).
+			Group("pt.post_id").
+			Having("count(distinct t.name) = ?", len(req.Tags))
+		query = query.Where("id IN (?)", subQuery)
+	}
+
+	// 添加日期范围过滤
+	if from, err := time.ParseInLocation("2006-01-02", req.DateFrom, time.Local); err == nil {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to, err := time.ParseInLocation("2006-01-02", req.DateTo, time.Local); err == nil {
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	// 添加月份和浏览量过滤
+	if start, end, ok := parseSearchMonth(req.Month); ok {
+		query = query.Where("created_at >= ? AND created_at < ?", start, end)
+	}
+	if viewRange, ok := GetViewRange(req.Views); ok {
+		query = query.Where("view >= ?", viewRange.From)
+		if viewRange.To > 0 {
+			query = query.Where("view < ?", viewRange.To)
+		}
+	}
+	return query
+}
+
+// defaultSuggestMinCount 未配置时，至少有两个不同访客搜索过才作为建议
+const defaultSuggestMinCount = 2
+
+// GetSearchSuggestions 获取搜索建议，热门搜索在前，不足时补充标题匹配的博文
 func GetSearchSuggestions(prefix string, limit int) ([]string, error) {
-	if !config.GetConfiguration().Elasticsearch.Enabled || !dao.IsESAvailable() {
+	cfg := config.GetConfiguration().Search
+	var suggestions []string
+	if cfg.LogEnabled {
+		days, minCount := cfg.SuggestDays, int64(cfg.SuggestMinCount)
+		if days <= 0 {
+			days = 30
+		}
+		if minCount <= 0 {
+			minCount = defaultSuggestMinCount
+		}
+		popular, err := ListPopularSearches(prefix, time.Now().AddDate(0, 0, -days), minCount, limit)
+		if err != nil {
+			log.Warn("获取热门搜索失败", "error", err, "prefix", prefix)
+		}
+		suggestions = appendSuggestions(suggestions, popular, limit)
+	}
+	if len(suggestions) >= limit {
+		return suggestions, nil
+	}
+
+	titles, err := getTitleSuggestions(prefix, limit)
+	return appendSuggestions(suggestions, titles, limit), err
+}
+
+// appendSuggestions 追加不重复的建议，最多 limit 条
+func appendSuggestions(suggestions, more []string, limit int) []string {
+	for _, suggestion := range more {
+		if len(suggestions) >= limit {
+			break
+		}
+		duplicated := false
+		for _, existing := range suggestions {
+			if strings.EqualFold(existing, suggestion) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// getTitleSuggestions 使用当前搜索引擎按博文标题获取搜索建议，引擎出错时降级到数据库
+func getTitleSuggestions(prefix string, limit int) ([]string, error) {
+	engine := CurrentSearchEngine()
+	suggestions, err := engine.Suggest(prefix, limit)
+	if err != nil && engine.Name() != SearchEngineDatabase {
+		log.Warn("搜索建议查询失败，降级到数据库", "engine", engine.Name(), "error", err)
 		return getSearchSuggestionsFromDB(prefix, limit)
 	}
+	return suggestions, err
+}
+
+// getSearchSuggestionsFromES 从ES获取搜索建议
+func getSearchSuggestionsFromES(prefix string, limit int) ([]string, error) {
+	if !dao.IsESAvailable() {
+		return nil, fmt.Errorf("ES不可用")
+	}
 
-	// ES建议查询
+	// ES建议查询：标题前缀匹配，启用拼音时同时匹配全拼和首字母
+	should := []map[string]interface{}{
+		{"match_phrase_prefix": map[string]interface{}{"title": prefix}},
+	}
+	if config.GetConfiguration().Elasticsearch.Pinyin && !containsCJK(prefix) {
+		should = append(should, map[string]interface{}{
+			"prefix": map[string]interface{}{
+				"title.pinyin": strings.ToLower(strings.Join(strings.Fields(prefix), "")),
+			},
+		})
+	}
 	query := map[string]interface{}{
-		"suggest": map[string]interface{}{
-			"title_suggest": map[string]interface{}{
-				"prefix": prefix,
-				"term": map[string]interface{}{
-					"field": "title",
-					"size":  limit,
-				},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter":               []map[string]interface{}{{"term": map[string]interface{}{"is_published": true}}},
+				"should":               should,
+				"minimum_should_match": 1,
 			},
 		},
+		"_source": []string{"title"},
+		"size":    limit,
 	}
 
 	queryJSON, _ := json.Marshal(query)
@@ -439,13 +592,32 @@ func GetSearchSuggestions(prefix string, limit int) ([]string, error) {
 		dao.ESClient.Search.WithBody(strings.NewReader(string(queryJSON))),
 	)
 	if err != nil {
-		log.Warn("ES建议查询失败，降级到数据库", "error", err)
-		return getSearchSuggestionsFromDB(prefix, limit)
+		return nil, err
 	}
 	defer res.Body.Close()
+	if res.IsError() {
+		return nil, fmt.Errorf("ES建议查询返回错误: %s", res.Status())
+	}
 
-	// 简化处理，这里可以根据实际需要解析建议响应
-	return getSearchSuggestionsFromDB(prefix, limit)
+	var esResp ESResponse
+	if err := json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		return nil, fmt.Errorf("解析ES响应失败: %w", err)
+	}
+	suggestions := make([]string, 0, len(esResp.Hits.Hits))
+	for _, hit := range esResp.Hits.Hits {
+		suggestions = append(suggestions, hit.Source.Title)
+	}
+	return suggestions, nil
+}
+
+// containsCJK 是否包含中日韩文字
+func containsCJK(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
 }
 
 // getSearchSuggestionsFromDB 从数据库获取搜索建议
@@ -517,13 +689,22 @@ func SyncAllPostsToES() error {
 	return nil
 }
 
-// bulkIndexPosts 批量索引博文到ES
+// bulkIndexPosts 批量索引博文到ES，重建索引期间同时写入新索引
 func bulkIndexPosts(posts []*Post) error {
+	for _, indexName := range postWriteIndices() {
+		if err := bulkIndexPostsTo(indexName, posts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bulkIndexPostsTo 批量索引博文到指定索引
+func bulkIndexPostsTo(indexName string, posts []*Post) error {
 	if len(posts) == 0 {
 		return nil
 	}
 
-	indexName := config.GetConfiguration().GetElasticsearchIndexName()
 	var bulkBody strings.Builder
 
 	for _, post := range posts {
@@ -541,13 +722,8 @@ func bulkIndexPosts(posts []*Post) error {
 		}
 
 		// 获取标签
-		if tags, err := ListTagByPostId(post.ID); err == nil {
-			tagNames := make([]string, len(tags))
-			for i, tag := range tags {
-				tagNames[i] = tag.Name
-			}
-			doc.Tags = tagNames
-		}
+		doc.Tags = postTagNames(post.ID)
+		doc.Checksum = postChecksum(post, doc.Tags)
 
 		// 构建批量请求的action行
 		action := map[string]interface{}{
@@ -601,67 +777,23 @@ func bulkIndexPosts(posts []*Post) error {
 		return fmt.Errorf("解析批量响应失败: %w", err)
 	}
 
-	// 检查是否有错误
+	// 检查是否有错误，部分失败时返回错误，由同步任务重试
 	if errors, exists := bulkResp["errors"]; exists && errors.(bool) {
 		log.Warn("批量操作中有部分失败", "batch_size", len(posts))
-		// 可以进一步解析具体的错误信息
 		if items, exists := bulkResp["items"]; exists {
 			log.Debug("批量操作详情", "items", items)
 		}
+		return fmt.Errorf("批量索引部分失败")
 	}
 
 	log.Debug("批量索引成功", "batch_size", len(posts))
 	return nil
 }
 
-// ESTaskQueue 批量ES任务队列
-type ESTaskQueue struct {
-	tasks  []ESTask
-	mutex  sync.Mutex
-	ticker *time.Ticker
-	quit   chan bool
-}
-
-type ESTask struct {
-	Action string // "index", "delete"
-	PostID uint
-	Post   *Post
-}
-
-var esTaskQueue *ESTaskQueue
-
-// InitESTaskQueue 初始化ES任务队列
-func InitESTaskQueue() {
-	esTaskQueue = &ESTaskQueue{
-		tasks:  make([]ESTask, 0),
-		ticker: time.NewTicker(5 * time.Second), // 每5秒处理一次批量任务
-		quit:   make(chan bool),
-	}
-
-	// 启动后台处理协程
-	go esTaskQueue.processLoop()
-
-	// 启动时进行增量同步检查
-	go performIncrementalSync()
-
-	log.Info("ES批量任务队列已启动")
-}
-
-// StopESTaskQueue 停止ES任务队列
-func StopESTaskQueue() {
-	if esTaskQueue != nil {
-		esTaskQueue.quit <- true
-		esTaskQueue.ticker.Stop()
-
-		// 处理剩余任务
-		esTaskQueue.processRemaining()
-		log.Info("ES批量任务队列已停止")
-	}
-}
-
 // performIncrementalSync 执行增量同步
 func performIncrementalSync() {
 	log.Info("开始ES增量同步检查...")
+	started := time.Now()
 
 	// 获取上次同步状态
 	var syncStatus ESSyncStatus
@@ -687,12 +819,11 @@ func performIncrementalSync() {
 
 	// 查询需要同步的博文
 	var posts []*Post
-	query := DB.Where("status = ? AND is_published = ?", "published", true)
+	query := DB.Where("is_published = ?", true)
 
 	if !lastSyncTime.IsZero() {
-		// 增量同步：只同步新增或更新的博文
-		query = query.Where("(created_at > ? OR updated_at > ?) AND id > ?",
-			lastSyncTime, lastSyncTime, lastPostID)
+		// 增量同步：只同步上次同步后新增或更新的博文，更新的博文ID可能小于上次记录的ID
+		query = query.Where("updated_at > ?", lastSyncTime)
 	}
 
 	if err := query.Order("id asc").Find(&posts).Error; err != nil {
@@ -729,7 +860,7 @@ func performIncrementalSync() {
 
 	// 更新同步状态
 	newSyncStatus := ESSyncStatus{
-		LastSyncTime: time.Now(),
+		LastSyncTime: started,                // 同步期间修改的博文留到下次同步
 		LastPostID:   posts[len(posts)-1].ID, // 最后一个博文ID
 		TotalSynced:  successCount,
 	}
@@ -760,166 +891,30 @@ func GetESIndexStatus() (*ESSyncStatus, error) {
 	return &syncStatus, nil
 }
 
-// ForceFullReindex 强制全量重建索引
+// ForceFullReindex 强制全量重建当前搜索引擎的索引，ES新索引写入完成后才切换别名，重建期间搜索不受影响
 func ForceFullReindex() error {
-	log.Info("开始强制全量重建ES索引...")
-
-	// 删除现有索引
-	if err := DeleteESIndex(); err != nil {
-		log.Error("删除ES索引失败", "error", err)
-		// 继续执行，可能索引不存在
-	}
-
-	// 重建索引
-	if err := CreateESIndex(); err != nil {
-		return fmt.Errorf("创建ES索引失败: %w", err)
-	}
-
-	// 清除同步状态
-	DB := dao.GetMysqlDB()
-	if err := DB.Where("1 = 1").Delete(&ESSyncStatus{}).Error; err != nil {
-		log.Error("清除同步状态失败", "error", err)
-	}
-
-	// 重新执行增量同步（此时会变成全量同步）
-	go performIncrementalSync()
-
-	return nil
-}
-
-// CreateESIndex 创建ES索引
-func CreateESIndex() error {
-	if !config.GetConfiguration().Elasticsearch.Enabled {
-		return fmt.Errorf("ES未启用")
-	}
-
-	// 这里应该调用system包中的创建索引函数
-	// 或者实现具体的索引创建逻辑
-	log.Info("创建ES索引")
-	return nil
+	engine := CurrentSearchEngine()
+	log.Info("开始强制全量重建搜索索引...", "engine", engine.Name())
+	return engine.Rebuild()
 }
 
-// DeleteESIndex 删除ES索引
-func DeleteESIndex() error {
-	if !config.GetConfiguration().Elasticsearch.Enabled {
-		return fmt.Errorf("ES未启用")
-	}
-
-	// 这里应该调用system包中的删除索引函数
-	// 或者实现具体的索引删除逻辑
-	log.Info("删除ES索引")
-	return nil
-}
-
-// StopESTaskQueue 停止ES任务队列
-
-// AddESTask 添加ES任务到队列
-func AddESTask(task ESTask) {
-	if esTaskQueue == nil {
-		// 如果队列未初始化，直接同步处理
-		processSingleTask(task)
-		return
-	}
-
-	esTaskQueue.mutex.Lock()
-	defer esTaskQueue.mutex.Unlock()
-
-	esTaskQueue.tasks = append(esTaskQueue.tasks, task)
-
-	// 如果队列过大，立即处理
-	if len(esTaskQueue.tasks) >= 50 {
-		go esTaskQueue.processBatch()
-	}
-}
-
-// processLoop 后台处理循环
-func (q *ESTaskQueue) processLoop() {
-	for {
-		select {
-		case <-q.ticker.C:
-			q.processBatch()
-		case <-q.quit:
-			return
-		}
-	}
-}
-
-// processBatch 处理当前批次
-func (q *ESTaskQueue) processBatch() {
-	q.mutex.Lock()
-	if len(q.tasks) == 0 {
-		q.mutex.Unlock()
-		return
-	}
-
-	// 复制当前任务并清空队列
-	batch := make([]ESTask, len(q.tasks))
-	copy(batch, q.tasks)
-	q.tasks = q.tasks[:0]
-	q.mutex.Unlock()
-
-	// 分离索引和删除任务
-	var indexTasks []*Post
-	var deleteTasks []uint
-
-	for _, task := range batch {
-		switch task.Action {
-		case "index":
-			if task.Post != nil {
-				indexTasks = append(indexTasks, task.Post)
-			}
-		case "delete":
-			deleteTasks = append(deleteTasks, task.PostID)
-		}
-	}
-
-	// 批量处理索引任务
-	if len(indexTasks) > 0 {
-		if err := bulkIndexPosts(indexTasks); err != nil {
-			log.Error("批量索引任务失败", "count", len(indexTasks), "error", err)
-		} else {
-			log.Debug("批量索引任务完成", "count", len(indexTasks))
-		}
-	}
-
-	// 批量处理删除任务
-	if len(deleteTasks) > 0 {
-		if err := bulkDeletePosts(deleteTasks); err != nil {
-			log.Error("批量删除任务失败", "count", len(deleteTasks), "error", err)
-		} else {
-			log.Debug("批量删除任务完成", "count", len(deleteTasks))
-		}
-	}
-}
-
-// processRemaining 处理剩余任务
-func (q *ESTaskQueue) processRemaining() {
-	q.processBatch()
-}
-
-// processSingleTask 单个任务同步处理（降级方案）
-func processSingleTask(task ESTask) {
-	switch task.Action {
-	case "index":
-		if task.Post != nil {
-			if err := IndexPost(task.Post); err != nil {
-				log.Error("同步索引失败", "post_id", task.PostID, "error", err)
-			}
-		}
-	case "delete":
-		if err := DeletePostFromIndex(task.PostID); err != nil {
-			log.Error("同步删除失败", "post_id", task.PostID, "error", err)
+// bulkDeletePosts 批量删除博文索引，重建索引期间同时从新索引删除
+func bulkDeletePosts(postIDs []uint) error {
+	rememberRebuildDeletes(postIDs...)
+	for _, indexName := range postWriteIndices() {
+		if err := bulkDeletePostsFrom(indexName, postIDs); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-// bulkDeletePosts 批量删除博文索引
-func bulkDeletePosts(postIDs []uint) error {
+// bulkDeletePostsFrom 从指定索引批量删除博文
+func bulkDeletePostsFrom(indexName string, postIDs []uint) error {
 	if len(postIDs) == 0 {
 		return nil
 	}
 
-	indexName := config.GetConfiguration().GetElasticsearchIndexName()
 	var bulkBody strings.Builder
 
 	for _, postID := range postIDs {
@@ -963,33 +958,3 @@ func bulkDeletePosts(postIDs []uint) error {
 	log.Debug("批量删除成功", "count", len(postIDs))
 	return nil
 }
-
-// ReindexAllPosts 重建所有博文的ES索引
-func ReindexAllPosts() error {
-	cfg := config.GetConfiguration()
-	if !cfg.Elasticsearch.Enabled {
-		log.Info("ES未启用，跳过重建索引")
-		return nil
-	}
-
-	if !dao.IsESAvailable() {
-		return fmt.Errorf("ES不可用")
-	}
-
-	log.Info("开始重建ES索引")
-
-	// 先删除现有索引
-	indexName := config.GetConfiguration().GetElasticsearchIndexName()
-	_, err := dao.ESClient.Indices.Delete([]string{indexName})
-	if err != nil {
-		log.Warn("删除现有索引失败", "error", err)
-	}
-
-	// 重新创建索引
-	if err := dao.InitElasticsearch(); err != nil {
-		return fmt.Errorf("重新创建索引失败: %w", err)
-	}
-
-	// 同步所有数据
-	return SyncAllPostsToES()
-}
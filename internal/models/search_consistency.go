@@ -0,0 +1,183 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+)
+
+// 一致性检查每批读取的博文数
+const consistencyBatchSize = 200
+
+// ErrSearchIndexUnsupported 数据库搜索没有索引，不需要检查
+var ErrSearchIndexUnsupported = errors.New("当前使用数据库搜索，没有需要检查的索引")
+
+// SearchConsistencyReport 数据库与搜索索引的一致性检查结果
+type SearchConsistencyReport struct {
+	Engine    string
+	CheckedAt time.Time
+	Took      time.Duration
+	Posts     int    // 数据库中已发布的博文数
+	Documents int    // 索引中的文档数
+	Missing   []uint // 索引中缺少的博文
+	Stale     []uint // 索引内容与数据库不一致的博文
+	Extra     []uint // 已删除或未发布但仍在索引中的博文
+	Repaired  bool   // 是否已为差异创建同步任务
+}
+
+// Consistent 索引与数据库是否一致
+func (report *SearchConsistencyReport) Consistent() bool {
+	return len(report.Missing) == 0 && len(report.Stale) == 0 && len(report.Extra) == 0
+}
+
+// lastSearchConsistency 最近一次检查的结果，供后台页面显示
+var lastSearchConsistency struct {
+	sync.Mutex
+	report *SearchConsistencyReport
+}
+
+// LastSearchConsistencyReport 最近一次一致性检查的结果，还没有检查过时返回 nil
+func LastSearchConsistencyReport() *SearchConsistencyReport {
+	lastSearchConsistency.Lock()
+	defer lastSearchConsistency.Unlock()
+	return lastSearchConsistency.report
+}
+
+// CheckSearchConsistency 比较数据库中已发布博文与当前搜索引擎索引的文档数和内容校验和，
+// repair 为 true 时为缺失、过期的博文创建索引任务，为多余的文档创建删除任务，由同步任务修复
+func CheckSearchConsistency(repair bool) (*SearchConsistencyReport, error) {
+	engine := CurrentSearchEngine()
+	started := time.Now()
+	actual, err := engine.Checksums()
+	if err != nil {
+		return nil, err
+	}
+	expected, err := publishedPostChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SearchConsistencyReport{
+		Engine:    engine.Name(),
+		CheckedAt: started,
+		Posts:     len(expected),
+		Documents: len(actual),
+	}
+	for id, checksum := range expected {
+		indexed, ok := actual[id]
+		if !ok {
+			report.Missing = append(report.Missing, id)
+		} else if indexed != checksum {
+			report.Stale = append(report.Stale, id)
+		}
+	}
+	for id := range actual {
+		if _, ok := expected[id]; !ok {
+			report.Extra = append(report.Extra, id)
+		}
+	}
+	slices.Sort(report.Missing)
+	slices.Sort(report.Stale)
+	slices.Sort(report.Extra)
+
+	if repair && !report.Consistent() {
+		DB := dao.GetMysqlDB()
+		if err := EnqueueSearchSync(DB, SearchSyncIndex, append(report.Missing, report.Stale...)...); err != nil {
+			return nil, fmt.Errorf("创建修复任务失败: %w", err)
+		}
+		if err := EnqueueSearchSync(DB, SearchSyncDelete, report.Extra...); err != nil {
+			return nil, fmt.Errorf("创建修复任务失败: %w", err)
+		}
+		report.Repaired = true
+	}
+	report.Took = time.Since(started)
+
+	lastSearchConsistency.Lock()
+	lastSearchConsistency.report = report
+	lastSearchConsistency.Unlock()
+	if !report.Consistent() {
+		log.Warn("搜索索引与数据库不一致", "engine", report.Engine, "posts", report.Posts, "documents", report.Documents,
+			"missing", len(report.Missing), "stale", len(report.Stale), "extra", len(report.Extra), "repaired", report.Repaired)
+	}
+	return report, nil
+}
+
+// publishedPostChecksums 分批计算全部已发布博文的内容校验和
+func publishedPostChecksums() (map[uint]string, error) {
+	checksums := make(map[uint]string)
+	DB := dao.GetMysqlDB()
+	var lastID uint
+	for {
+		var posts []*Post
+		err := DB.Select("id", "title", "body", "created_at").
+			Where("is_published = ? AND id > ?", true, lastID).
+			Order("id asc").
+			Limit(consistencyBatchSize).
+			Find(&posts).Error
+		if err != nil {
+			return nil, fmt.Errorf("查询博文失败: %w", err)
+		}
+		if len(posts) == 0 {
+			return checksums, nil
+		}
+
+		ids := make([]uint, len(posts))
+		for i, post := range posts {
+			ids[i] = post.ID
+		}
+		var rows []struct {
+			PostID uint
+			Name   string
+		}
+		err = DB.Table("post_tags pt").
+			Select("pt.post_id, t.name").
+			Joins("JOIN tags t ON t.id = pt.tag_id").
+			Where("pt.post_id IN ?", ids).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("查询博文标签失败: %w", err)
+		}
+		tags := make(map[uint][]string, len(posts))
+		for _, row := range rows {
+			tags[row.PostID] = append(tags[row.PostID], row.Name)
+		}
+
+		for _, post := range posts {
+			checksums[post.ID] = postChecksum(post, tags[post.ID])
+		}
+		lastID = posts[len(posts)-1].ID
+	}
+}
+
+// postTagNames 博文的标签名
+func postTagNames(postID uint) []string {
+	tags, err := ListTagByPostId(postID)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// postChecksum 博文中参与索引的标题、正文、标签和发布时间的校验和，浏览量等频繁变化的字段不参与
+func postChecksum(post *Post, tags []string) string {
+	var createdAt int64
+	if post.CreatedAt != nil {
+		createdAt = post.CreatedAt.Unix()
+	}
+	sorted := slices.Sorted(slices.Values(tags))
+	hash := sha1.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%d", post.Title, post.Body, strings.Join(sorted, "\x1f"), createdAt)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// 搜索引擎名称，对应配置 [search] engine
+const (
+	SearchEngineAuto          = "auto" // 启用了 ES 时使用 ES，否则使用内置引擎
+	SearchEngineElasticsearch = "elasticsearch"
+	SearchEngineLocal         = "local"
+	SearchEngineDatabase      = "database"
+)
+
+// SearchEngine 博文全文搜索引擎
+type SearchEngine interface {
+	// Name 引擎名称
+	Name() string
+	// Index 写入或更新一篇博文，未发布的博文会从索引中移除
+	Index(post *Post) error
+	// Delete 从索引中删除博文
+	Delete(postID uint) error
+	// Bulk 批量写入和删除
+	Bulk(posts []*Post, deleteIDs []uint) error
+	// Search 搜索博文
+	Search(req *SearchRequest) (*SearchResponse, error)
+	// Suggest 按标题前缀获取搜索建议
+	Suggest(prefix string, limit int) ([]string, error)
+	// MoreLikeThis 按标题和正文找出内容相近的已发布博文及相似度，不包含博文本身
+	MoreLikeThis(post *Post, limit int) (map[uint]float64, error)
+	// Rebuild 按数据库内容全量重建索引
+	Rebuild() error
+	// Checksums 索引中全部博文的内容校验和，用于一致性检查
+	Checksums() (map[uint]string, error)
+}
+
+var (
+	searchEngineMutex sync.RWMutex
+	searchEngine      SearchEngine = databaseEngine{}
+)
+
+// CurrentSearchEngine 当前使用的搜索引擎，未初始化时使用数据库查询
+func CurrentSearchEngine() SearchEngine {
+	searchEngineMutex.RLock()
+	defer searchEngineMutex.RUnlock()
+	return searchEngine
+}
+
+// setSearchEngine 切换搜索引擎
+func setSearchEngine(engine SearchEngine) {
+	searchEngineMutex.Lock()
+	searchEngine = engine
+	searchEngineMutex.Unlock()
+}
+
+// InitSearchEngine 按配置初始化搜索引擎并启动搜索同步任务，初始化失败时降级为数据库查询。
+// reindex 为 true 表示调用方随后会执行全量重建，此时不在后台自动重建
+func InitSearchEngine(reindex bool) {
+	cfg := config.GetConfiguration()
+	name := cfg.Search.Engine
+	if name == "" || name == SearchEngineAuto {
+		name = SearchEngineLocal
+		if cfg.Elasticsearch.Enabled {
+			name = SearchEngineElasticsearch
+		}
+	}
+
+	var engine SearchEngine
+	var err error
+	switch name {
+	case SearchEngineElasticsearch:
+		engine, err = openESEngine(reindex)
+	case SearchEngineLocal:
+		engine, err = openLocalEngine(cfg.Search.IndexPath, reindex)
+	case SearchEngineDatabase:
+		engine = databaseEngine{}
+	default:
+		err = fmt.Errorf("未知的搜索引擎: %s", name)
+	}
+	if err != nil {
+		log.Error("搜索引擎初始化失败，降级到数据库搜索", "engine", name, "error", err)
+		engine = databaseEngine{}
+	}
+	setSearchEngine(engine)
+	log.Info("搜索引擎已启用", "engine", engine.Name())
+
+	StartSearchSyncWorker()
+}
+
+// ========== Elasticsearch ==========
+
+// esEngine 使用 Elasticsearch 的搜索引擎
+type esEngine struct{}
+
+// openESEngine 连接 ES 并检查索引，映射过期时在后台重建，完成前继续使用旧索引
+func openESEngine(reindex bool) (SearchEngine, error) {
+	if !config.GetConfiguration().Elasticsearch.Enabled {
+		return nil, fmt.Errorf("ES未启用")
+	}
+	if err := dao.InitElasticsearch(); err != nil {
+		return nil, err
+	}
+	outdated, err := EnsurePostIndex()
+	if err != nil {
+		log.Error("ElasticSearch index check failed", "err", err)
+	} else if outdated && !reindex {
+		go func() {
+			if err := RebuildPostIndex(); err != nil {
+				log.Error("ElasticSearch reindex failed", "err", err)
+			}
+		}()
+	}
+	// 启动时进行增量同步检查
+	go performIncrementalSync()
+	return esEngine{}, nil
+}
+
+func (esEngine) Name() string {
+	return SearchEngineElasticsearch
+}
+
+func (esEngine) Index(post *Post) error {
+	if !post.IsPublished {
+		return DeletePostFromIndex(post.ID)
+	}
+	return IndexPost(post)
+}
+
+func (esEngine) Delete(postID uint) error {
+	return DeletePostFromIndex(postID)
+}
+
+func (esEngine) Bulk(posts []*Post, deleteIDs []uint) error {
+	if !dao.IsESAvailable() {
+		return fmt.Errorf("ES不可用")
+	}
+	// 未发布的博文从索引中移除
+	published := make([]*Post, 0, len(posts))
+	for _, post := range posts {
+		if post.IsPublished {
+			published = append(published, post)
+		} else {
+			deleteIDs = append(deleteIDs, post.ID)
+		}
+	}
+	if len(published) > 0 {
+		if err := bulkIndexPosts(published); err != nil {
+			return err
+		}
+	}
+	if len(deleteIDs) > 0 {
+		return bulkDeletePosts(deleteIDs)
+	}
+	return nil
+}
+
+func (esEngine) Search(req *SearchRequest) (*SearchResponse, error) {
+	return searchPostsFromES(req)
+}
+
+func (esEngine) Suggest(prefix string, limit int) ([]string, error) {
+	return getSearchSuggestionsFromES(prefix, limit)
+}
+
+func (esEngine) MoreLikeThis(post *Post, limit int) (map[uint]float64, error) {
+	return esMoreLikeThis(post, limit)
+}
+
+func (esEngine) Rebuild() error {
+	return RebuildPostIndex()
+}
+
+func (esEngine) Checksums() (map[uint]string, error) {
+	return esPostChecksums()
+}
+
+// ========== 数据库 ==========
+
+// databaseEngine 直接查询数据库，没有索引，用于未配置搜索引擎或引擎不可用时
+type databaseEngine struct{}
+
+func (databaseEngine) Name() string {
+	return SearchEngineDatabase
+}
+
+func (databaseEngine) Index(post *Post) error {
+	return nil
+}
+
+func (databaseEngine) Delete(postID uint) error {
+	return nil
+}
+
+func (databaseEngine) Bulk(posts []*Post, deleteIDs []uint) error {
+	return nil
+}
+
+func (databaseEngine) Search(req *SearchRequest) (*SearchResponse, error) {
+	return searchPostsFromDB(req)
+}
+
+func (databaseEngine) Suggest(prefix string, limit int) ([]string, error) {
+	return getSearchSuggestionsFromDB(prefix, limit)
+}
+
+func (databaseEngine) MoreLikeThis(post *Post, limit int) (map[uint]float64, error) {
+	return tfidfMoreLikeThis(post, limit)
+}
+
+func (databaseEngine) Rebuild() error {
+	return nil
+}
+
+func (databaseEngine) Checksums() (map[uint]string, error) {
+	return nil, ErrSearchIndexUnsupported
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+)
+
+// 标签分面最多返回的标签数
+const facetTagSize = 20
+
+// SearchFacets 搜索结果的分面统计，统计范围是当前条件下的全部结果
+type SearchFacets struct {
+	Tags   []*FacetBucket `json:"tags"`
+	Months []*FacetBucket `json:"months"`
+	Views  []*FacetBucket `json:"views"`
+}
+
+// FacetBucket 分面中的一项
+type FacetBucket struct {
+	Key   string `json:"key"` // 作为筛选参数的取值
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// ViewRange 浏览量区间，包含 From 不包含 To，To 为 0 表示不设上限
+type ViewRange struct {
+	Key   string
+	Label string
+	From  int
+	To    int
+}
+
+// ViewRanges 浏览量分面的区间
+var ViewRanges = []ViewRange{
+	{Key: "0-100", Label: "100 次以下", From: 0, To: 100},
+	{Key: "100-500", Label: "100-500 次", From: 100, To: 500},
+	{Key: "500-1000", Label: "500-1000 次", From: 500, To: 1000},
+	{Key: "1000-", Label: "1000 次以上", From: 1000},
+}
+
+// GetViewRange 按取值查找浏览量区间
+func GetViewRange(key string) (ViewRange, bool) {
+	for _, viewRange := range ViewRanges {
+		if viewRange.Key == key {
+			return viewRange, true
+		}
+	}
+	return ViewRange{}, false
+}
+
+// Contains 浏览量是否在区间内
+func (viewRange ViewRange) Contains(views int) bool {
+	return views >= viewRange.From && (viewRange.To == 0 || views < viewRange.To)
+}
+
+// esRange ES range 查询和聚合使用的区间
+func (viewRange ViewRange) esRange() map[string]interface{} {
+	r := map[string]interface{}{"gte": viewRange.From}
+	if viewRange.To > 0 {
+		r["lt"] = viewRange.To
+	}
+	return r
+}
+
+// parseSearchMonth 解析月份筛选参数，返回该月的起止时间
+func parseSearchMonth(month string) (start, end time.Time, ok bool) {
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return start, end, false
+	}
+	return start, start.AddDate(0, 1, 0), true
+}
+
+// monthLabel 月份分面的显示文字
+func monthLabel(month string) string {
+	if t, err := time.Parse("2006-01", month); err == nil {
+		return fmt.Sprintf("%d 年 %d 月", t.Year(), t.Month())
+	}
+	return month
+}
+
+// searchFacetAggs ES 分面聚合
+func searchFacetAggs() map[string]interface{} {
+	ranges := make([]map[string]interface{}, 0, len(ViewRanges))
+	for _, viewRange := range ViewRanges {
+		r := map[string]interface{}{"key": viewRange.Key, "from": viewRange.From}
+		if viewRange.To > 0 {
+			r["to"] = viewRange.To
+		}
+		ranges = append(ranges, r)
+	}
+	return map[string]interface{}{
+		"tags": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "tags",
+				"size":  facetTagSize,
+			},
+		},
+		"months": map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":             "created_at",
+				"calendar_interval": "month",
+				"format":            "yyyy-MM",
+				"time_zone":         time.Now().Format("-07:00"),
+				"min_doc_count":     1,
+				"order":             map[string]string{"_key": "desc"},
+			},
+		},
+		"views": map[string]interface{}{
+			"range": map[string]interface{}{
+				"field":  "view_count",
+				"ranges": ranges,
+			},
+		},
+	}
+}
+
+// parseSearchFacets 解析 ES 分面聚合结果
+func parseSearchFacets(esResp *ESResponse) *SearchFacets {
+	facets := &SearchFacets{}
+	aggs := esResp.Aggregations
+	for _, bucket := range aggs.Tags.Buckets {
+		name := fmt.Sprint(bucket.Key)
+		facets.Tags = append(facets.Tags, &FacetBucket{Key: name, Label: name, Count: bucket.DocCount})
+	}
+	for _, bucket := range aggs.Months.Buckets {
+		facets.Months = append(facets.Months, &FacetBucket{Key: bucket.KeyAsString, Label: monthLabel(bucket.KeyAsString), Count: bucket.DocCount})
+	}
+	counts := make(map[string]int64, len(aggs.Views.Buckets))
+	for _, bucket := range aggs.Views.Buckets {
+		counts[fmt.Sprint(bucket.Key)] = bucket.DocCount
+	}
+	facets.Views = viewFacets(counts)
+	return facets
+}
+
+// viewFacets 按 ViewRanges 的顺序生成浏览量分面，省略没有结果的区间
+func viewFacets(counts map[string]int64) []*FacetBucket {
+	var buckets []*FacetBucket
+	for _, viewRange := range ViewRanges {
+		if count := counts[viewRange.Key]; count > 0 {
+			buckets = append(buckets, &FacetBucket{Key: viewRange.Key, Label: viewRange.Label, Count: count})
+		}
+	}
+	return buckets
+}
+
+// viewRangeCase 把浏览量映射到区间取值的 SQL 表达式，ViewRanges 按从小到大排列
+func viewRangeCase() string {
+	var sql strings.Builder
+	sql.WriteString("CASE")
+	for _, viewRange := range ViewRanges {
+		if viewRange.To > 0 {
+			fmt.Fprintf(&sql, " WHEN view < %d THEN '%s'", viewRange.To, viewRange.Key)
+		} else {
+			fmt.Fprintf(&sql, " ELSE '%s'", viewRange.Key)
+		}
+	}
+	sql.WriteString(" END")
+	return sql.String()
+}
+
+// searchFacetsFromDB 数据库搜索的分面统计
+func searchFacetsFromDB(req *SearchRequest) (*SearchFacets, error) {
+	facets := &SearchFacets{}
+	DB := dao.GetMysqlDB()
+	ids := searchPostQuery(req).Select("id")
+
+	var tags []struct {
+		Name  string
+		Count int64
+	}
+	err := DB.Table("post_tags pt").
+		Select("t.name as name, count(*) as count").
+		Joins("JOIN tags t ON t.id = pt.tag_id").
+		Where("pt.post_id IN (?)", ids).
+		Group("t.name").
+		Order("count desc, t.name").
+		Limit(facetTagSize).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		facets.Tags = append(facets.Tags, &FacetBucket{Key: tag.Name, Label: tag.Name, Count: tag.Count})
+	}
+
+	var months []struct {
+		Month string
+		Count int64
+	}
+	err = searchPostQuery(req).
+		Select("DATE_FORMAT(created_at, '%Y-%m') as month, count(*) as count").
+		Group("month").
+		Order("month desc").
+		Scan(&months).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, month := range months {
+		facets.Months = append(facets.Months, &FacetBucket{Key: month.Month, Label: monthLabel(month.Month), Count: month.Count})
+	}
+
+	var views []struct {
+		Bucket string
+		Count  int64
+	}
+	err = searchPostQuery(req).
+		Select(viewRangeCase() + " as bucket, count(*) as count").
+		Group("bucket").
+		Scan(&views).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(views))
+	for _, view := range views {
+		counts[view.Bucket] = view.Count
+	}
+	facets.Views = viewFacets(counts)
+	return facets, nil
+}
@@ -0,0 +1,267 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// 重建索引时每批写入的博文数
+const reindexBatchSize = 100
+
+// postIndexRebuild 正在进行的索引重建，重建期间的写入同时发往新索引
+var postIndexRebuild struct {
+	sync.Mutex
+	running bool
+	target  string
+	deleted []uint // 重建期间删除的博文，复制时可能已读到旧数据，切换后需要再删除一次
+}
+
+// postWriteIndices 博文写入的索引：别名，以及重建中的新索引
+func postWriteIndices() []string {
+	indices := []string{config.GetConfiguration().GetElasticsearchIndexName()}
+	postIndexRebuild.Lock()
+	defer postIndexRebuild.Unlock()
+	if postIndexRebuild.target != "" {
+		indices = append(indices, postIndexRebuild.target)
+	}
+	return indices
+}
+
+// rememberRebuildDeletes 重建期间记录删除的博文
+func rememberRebuildDeletes(postIDs ...uint) {
+	postIndexRebuild.Lock()
+	defer postIndexRebuild.Unlock()
+	if postIndexRebuild.target != "" {
+		postIndexRebuild.deleted = append(postIndexRebuild.deleted, postIDs...)
+	}
+}
+
+// EnsurePostIndex 启动时检查博文索引：不存在时创建并写入全部博文，
+// 返回 true 表示现有索引的映射已过期或是没有别名的旧版索引，需要调用 RebuildPostIndex
+func EnsurePostIndex() (bool, error) {
+	cfg := config.GetConfiguration()
+	alias := cfg.GetElasticsearchIndexName()
+	spec := dao.ResolvePostIndexSpec(cfg.Elasticsearch)
+
+	indices, err := dao.AliasIndices(alias)
+	if err != nil {
+		return false, err
+	}
+	if len(indices) > 0 {
+		current, err := dao.GetPostIndexSpec(indices[0])
+		if err != nil {
+			return false, err
+		}
+		if current != spec {
+			log.Warn("ES索引映射已过期，需要重建", "index", indices[0], "current", current, "expected", spec)
+			return true, nil
+		}
+		return false, nil
+	}
+
+	// 没有别名但存在同名索引，是自动创建的旧版索引
+	exists, err := dao.IndexExists(alias)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		log.Warn("ES索引没有使用别名，需要重建", "index", alias)
+		return true, nil
+	}
+
+	indexName := dao.NewPostIndexName(alias)
+	if err := dao.CreatePostIndex(indexName, spec); err != nil {
+		return false, err
+	}
+	if err := dao.SwitchAlias(alias, indexName, nil, false); err != nil {
+		return false, err
+	}
+	go func() {
+		if err := SyncAllPostsToES(); err != nil {
+			log.Error("初始化ES索引数据失败", "error", err)
+		}
+	}()
+	return false, nil
+}
+
+// RebuildPostIndex 零停机重建博文索引：按当前配置创建新索引并写入全部博文，
+// 完成后原子地切换别名并删除旧索引，重建期间搜索仍使用旧索引
+func RebuildPostIndex() error {
+	if !config.GetConfiguration().Elasticsearch.Enabled {
+		return fmt.Errorf("ES未启用")
+	}
+	if !dao.IsESAvailable() {
+		return fmt.Errorf("ES不可用")
+	}
+
+	postIndexRebuild.Lock()
+	if postIndexRebuild.running {
+		postIndexRebuild.Unlock()
+		return fmt.Errorf("索引正在重建")
+	}
+	postIndexRebuild.running = true
+	postIndexRebuild.Unlock()
+	defer func() {
+		postIndexRebuild.Lock()
+		postIndexRebuild.running = false
+		postIndexRebuild.target = ""
+		postIndexRebuild.deleted = nil
+		postIndexRebuild.Unlock()
+	}()
+
+	cfg := config.GetConfiguration()
+	alias := cfg.GetElasticsearchIndexName()
+	spec := dao.ResolvePostIndexSpec(cfg.Elasticsearch)
+	oldIndices, err := dao.AliasIndices(alias)
+	if err != nil {
+		return err
+	}
+	legacy := false
+	if len(oldIndices) == 0 {
+		if legacy, err = dao.IndexExists(alias); err != nil {
+			return err
+		}
+	}
+
+	indexName := dao.NewPostIndexName(alias)
+	if err := dao.CreatePostIndex(indexName, spec); err != nil {
+		return err
+	}
+	started := time.Now()
+	// 先开启双写再读取博文，重建期间的修改不会丢失
+	postIndexRebuild.Lock()
+	postIndexRebuild.target = indexName
+	postIndexRebuild.Unlock()
+	log.Info("开始重建ES索引", "alias", alias, "index", indexName, "analyzer", spec.Analyzer, "pinyin", spec.Pinyin)
+
+	total, err := copyPostsToIndex(indexName)
+	if err == nil {
+		err = dao.RefreshIndex(indexName)
+	}
+	if err == nil {
+		err = dao.SwitchAlias(alias, indexName, oldIndices, legacy)
+	}
+	if err != nil {
+		if cleanupErr := dao.DeleteIndices(indexName); cleanupErr != nil {
+			log.Warn("删除未完成的ES索引失败", "index", indexName, "error", cleanupErr)
+		}
+		return fmt.Errorf("重建索引失败: %w", err)
+	}
+
+	// 复制期间读到旧数据的博文可能覆盖了双写的新数据，切换后按修改时间补写一次，
+	// 期间取消发布和删除的博文从新索引中再删除一次
+	postIndexRebuild.Lock()
+	removed := append([]uint(nil), postIndexRebuild.deleted...)
+	postIndexRebuild.Unlock()
+	var changed, published []*Post
+	DB := dao.GetMysqlDB()
+	if err := DB.Where("updated_at >= ?", started).Find(&changed).Error; err != nil {
+		log.Warn("查询重建期间修改的博文失败", "error", err)
+	}
+	for _, post := range changed {
+		if post.IsPublished {
+			published = append(published, post)
+		} else {
+			removed = append(removed, post.ID)
+		}
+	}
+	if err := bulkIndexPostsTo(indexName, published); err != nil {
+		log.Warn("补写重建期间修改的博文失败", "count", len(published), "error", err)
+	}
+	if err := bulkDeletePostsFrom(indexName, removed); err != nil {
+		log.Warn("删除重建期间取消发布的博文失败", "count", len(removed), "error", err)
+	}
+
+	if err := dao.DeleteIndices(oldIndices...); err != nil {
+		log.Warn("删除旧ES索引失败", "indices", oldIndices, "error", err)
+	}
+	log.Info("ES索引重建完成", "alias", alias, "index", indexName, "posts", total, "took", time.Since(started))
+	return nil
+}
+
+// esPostChecksums 按ID顺序分页读取ES中全部博文文档的校验和
+func esPostChecksums() (map[uint]string, error) {
+	if !dao.IsESAvailable() {
+		return nil, fmt.Errorf("ES不可用")
+	}
+	indexName := config.GetConfiguration().GetElasticsearchIndexName()
+	checksums := make(map[uint]string)
+	var searchAfter []interface{}
+	for {
+		query := map[string]interface{}{
+			"query":   map[string]interface{}{"match_all": map[string]interface{}{}},
+			"_source": []string{"id", "checksum"},
+			"sort":    []map[string]string{{"id": "asc"}},
+			"size":    consistencyBatchSize,
+		}
+		if searchAfter != nil {
+			query["search_after"] = searchAfter
+		}
+		queryJSON, _ := json.Marshal(query)
+		res, err := dao.ESClient.Search(
+			dao.ESClient.Search.WithIndex(indexName),
+			dao.ESClient.Search.WithBody(bytes.NewReader(queryJSON)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		var esResp struct {
+			Hits struct {
+				Hits []struct {
+					Source PostDocument  `json:"_source"`
+					Sort   []interface{} `json:"sort"`
+				} `json:"hits"`
+			} `json:"hits"`
+		}
+		if res.IsError() {
+			res.Body.Close()
+			return nil, fmt.Errorf("ES查询返回错误: %s", res.Status())
+		}
+		err = json.NewDecoder(res.Body).Decode(&esResp)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析ES响应失败: %w", err)
+		}
+
+		hits := esResp.Hits.Hits
+		for _, hit := range hits {
+			checksums[hit.Source.ID] = hit.Source.Checksum
+		}
+		if len(hits) < consistencyBatchSize {
+			return checksums, nil
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+}
+
+// copyPostsToIndex 分批把已发布的博文写入指定索引
+func copyPostsToIndex(indexName string) (int, error) {
+	DB := dao.GetMysqlDB()
+	total := 0
+	var lastID uint
+	for {
+		var posts []*Post
+		err := DB.Where("is_published = ? AND id > ?", true, lastID).
+			Order("id asc").
+			Limit(reindexBatchSize).
+			Find(&posts).Error
+		if err != nil {
+			return total, fmt.Errorf("查询博文失败: %w", err)
+		}
+		if len(posts) == 0 {
+			return total, nil
+		}
+		if err := bulkIndexPostsTo(indexName, posts); err != nil {
+			return total, err
+		}
+		total += len(posts)
+		lastID = posts[len(posts)-1].ID
+	}
+}
@@ -0,0 +1,279 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/fulltext"
+)
+
+// 内置搜索引擎默认的索引文件
+const defaultLocalIndexPath = "data/search.idx"
+
+// 保存索引时把同步时间往前留出的余量，下次启动补同步时宁可多处理几篇博文
+const localIndexSyncMargin = time.Minute
+
+// 搜索结果摘要的长度（字符数）
+const highlightSize = 200
+
+// localEngine 内置搜索引擎，倒排索引保存在本地文件中，适合没有部署 ES 的单机博客
+type localEngine struct {
+	index *fulltext.Index
+}
+
+// openLocalEngine 读取本地索引，启动后在后台补同步上次退出后修改的博文；
+// 索引文件不存在或版本不一致时在后台全量重建
+func openLocalEngine(path string, reindex bool) (SearchEngine, error) {
+	if path == "" {
+		path = defaultLocalIndexPath
+	}
+	engine := &localEngine{index: fulltext.New(path)}
+	err := engine.index.Load()
+	if reindex {
+		return engine, nil
+	}
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn("读取搜索索引失败，将重建索引", "path", path, "error", err)
+		}
+		go func() {
+			if err := engine.Rebuild(); err != nil {
+				log.Error("重建搜索索引失败", "error", err)
+			}
+		}()
+		return engine, nil
+	}
+	go func() {
+		if err := engine.catchUp(); err != nil {
+			log.Error("搜索索引补同步失败", "error", err)
+		}
+	}()
+	return engine, nil
+}
+
+func (engine *localEngine) Name() string {
+	return SearchEngineLocal
+}
+
+func (engine *localEngine) Index(post *Post) error {
+	return engine.Bulk([]*Post{post}, nil)
+}
+
+func (engine *localEngine) Delete(postID uint) error {
+	return engine.Bulk(nil, []uint{postID})
+}
+
+func (engine *localEngine) Bulk(posts []*Post, deleteIDs []uint) error {
+	started := time.Now()
+	docs := make([]*fulltext.Document, 0, len(posts))
+	for _, post := range posts {
+		if post.IsPublished {
+			docs = append(docs, postFulltextDocument(post))
+		} else {
+			deleteIDs = append(deleteIDs, post.ID)
+		}
+	}
+	engine.index.Delete(deleteIDs...)
+	engine.index.Put(docs...)
+	return engine.index.Save(started.Add(-localIndexSyncMargin))
+}
+
+func (engine *localEngine) Search(req *SearchRequest) (*SearchResponse, error) {
+	started := time.Now()
+	hits := engine.index.Search(req.Query, localSearchFilter(req))
+	resp := &SearchResponse{
+		Total:      int64(len(hits)),
+		Facets:     searchFacetsFromHits(hits),
+		Highlights: make(map[uint]template.HTML),
+	}
+	if len(hits) > 0 {
+		resp.MaxScore = hits[0].Score
+	}
+
+	switch req.SortBy {
+	case "date":
+		sort.SliceStable(hits, func(i, j int) bool {
+			return hits[i].Doc.CreatedAt.After(hits[j].Doc.CreatedAt)
+		})
+	case "views":
+		sort.SliceStable(hits, func(i, j int) bool {
+			return hits[i].Doc.Views > hits[j].Doc.Views
+		})
+	}
+
+	from := min((req.Page-1)*req.Size, len(hits))
+	to := min(from+req.Size, len(hits))
+	for _, hit := range hits[from:to] {
+		post, err := GetPostById(hit.Doc.ID)
+		if err != nil {
+			log.Warn("无法从数据库获取博文", "id", hit.Doc.ID, "error", err)
+			continue
+		}
+		if err := LoadPostRelations(post); err != nil {
+			log.Warn("加载博文关联数据失败", "id", post.ID, "error", err)
+		}
+		if fragment := fulltext.Highlight(post.Body, req.Query, highlightSize); fragment != "" {
+			resp.Highlights[post.ID] = template.HTML(fragment)
+		}
+		resp.Posts = append(resp.Posts, post)
+	}
+	resp.Took = int(time.Since(started).Milliseconds())
+	return resp, nil
+}
+
+func (engine *localEngine) Suggest(prefix string, limit int) ([]string, error) {
+	return engine.index.Suggest(prefix, limit), nil
+}
+
+func (engine *localEngine) MoreLikeThis(post *Post, limit int) (map[uint]float64, error) {
+	hits := engine.index.MoreLikeThis(post.Title+"\n"+post.Body, post.ID, relatedMaxQueryTerms)
+	scores := make(map[uint]float64, min(len(hits), limit))
+	for _, hit := range hits[:min(len(hits), limit)] {
+		scores[hit.Doc.ID] = hit.Score
+	}
+	return scores, nil
+}
+
+func (engine *localEngine) Checksums() (map[uint]string, error) {
+	return engine.index.Checksums(), nil
+}
+
+// Rebuild 读取全部已发布的博文重建索引
+func (engine *localEngine) Rebuild() error {
+	started := time.Now()
+	var posts []*Post
+	DB := dao.GetMysqlDB()
+	if err := DB.Where("is_published = ?", true).Find(&posts).Error; err != nil {
+		return fmt.Errorf("查询博文失败: %w", err)
+	}
+	docs := make([]*fulltext.Document, 0, len(posts))
+	for _, post := range posts {
+		docs = append(docs, postFulltextDocument(post))
+	}
+	engine.index.Reset(docs)
+	if err := engine.index.Save(started.Add(-localIndexSyncMargin)); err != nil {
+		return fmt.Errorf("保存搜索索引失败: %w", err)
+	}
+	log.Info("搜索索引重建完成", "posts", len(docs), "took", time.Since(started))
+	return nil
+}
+
+// catchUp 补同步上次保存索引之后修改的博文，并移除已删除或下线的博文
+func (engine *localEngine) catchUp() error {
+	started := time.Now()
+	DB := dao.GetMysqlDB()
+	var changed []*Post
+	if err := DB.Where("is_published = ? AND updated_at >= ?", true, engine.index.SyncedAt()).Find(&changed).Error; err != nil {
+		return fmt.Errorf("查询博文失败: %w", err)
+	}
+	var ids []uint
+	if err := DB.Model(&Post{}).Where("is_published = ?", true).Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("查询博文失败: %w", err)
+	}
+	published := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		published[id] = true
+	}
+
+	var removed []uint
+	for _, id := range engine.index.IDs() {
+		if !published[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := engine.Bulk(changed, removed); err != nil {
+		return err
+	}
+	log.Info("搜索索引补同步完成", "updated", len(changed), "removed", len(removed), "took", time.Since(started))
+	return nil
+}
+
+// postFulltextDocument 把博文转换为内置引擎的索引文档
+func postFulltextDocument(post *Post) *fulltext.Document {
+	doc := &fulltext.Document{
+		ID:    post.ID,
+		Title: post.Title,
+		Body:  post.Body,
+		Views: post.View,
+	}
+	if post.CreatedAt != nil {
+		doc.CreatedAt = *post.CreatedAt
+	}
+	doc.Tags = postTagNames(post.ID)
+	doc.Checksum = postChecksum(post, doc.Tags)
+	return doc
+}
+
+// localSearchFilter 按标签、日期、月份和浏览量过滤文档，条件与 searchPostQuery 一致
+func localSearchFilter(req *SearchRequest) func(*fulltext.Document) bool {
+	from, fromErr := time.ParseInLocation("2006-01-02", req.DateFrom, time.Local)
+	to, toErr := time.ParseInLocation("2006-01-02", req.DateTo, time.Local)
+	monthStart, monthEnd, monthOK := parseSearchMonth(req.Month)
+	viewRange, viewOK := GetViewRange(req.Views)
+	return func(doc *fulltext.Document) bool {
+		for _, tag := range req.Tags {
+			if !slices.Contains(doc.Tags, tag) {
+				return false
+			}
+		}
+		if fromErr == nil && doc.CreatedAt.Before(from) {
+			return false
+		}
+		if toErr == nil && !doc.CreatedAt.Before(to.AddDate(0, 0, 1)) {
+			return false
+		}
+		if monthOK && (doc.CreatedAt.Before(monthStart) || !doc.CreatedAt.Before(monthEnd)) {
+			return false
+		}
+		return !viewOK || viewRange.Contains(doc.Views)
+	}
+}
+
+// searchFacetsFromHits 内置引擎的分面统计
+func searchFacetsFromHits(hits []*fulltext.Hit) *SearchFacets {
+	facets := &SearchFacets{}
+	tagCounts := make(map[string]int64)
+	monthCounts := make(map[string]int64)
+	viewCounts := make(map[string]int64)
+	for _, hit := range hits {
+		for _, tag := range hit.Doc.Tags {
+			tagCounts[tag]++
+		}
+		monthCounts[hit.Doc.CreatedAt.Local().Format("2006-01")]++
+		for _, viewRange := range ViewRanges {
+			if viewRange.Contains(hit.Doc.Views) {
+				viewCounts[viewRange.Key]++
+				break
+			}
+		}
+	}
+
+	for tag, count := range tagCounts {
+		facets.Tags = append(facets.Tags, &FacetBucket{Key: tag, Label: tag, Count: count})
+	}
+	sort.Slice(facets.Tags, func(i, j int) bool {
+		if facets.Tags[i].Count != facets.Tags[j].Count {
+			return facets.Tags[i].Count > facets.Tags[j].Count
+		}
+		return facets.Tags[i].Key < facets.Tags[j].Key
+	})
+	if len(facets.Tags) > facetTagSize {
+		facets.Tags = facets.Tags[:facetTagSize]
+	}
+
+	for month, count := range monthCounts {
+		facets.Months = append(facets.Months, &FacetBucket{Key: month, Label: monthLabel(month), Count: count})
+	}
+	sort.Slice(facets.Months, func(i, j int) bool {
+		return facets.Months[i].Key > facets.Months[j].Key
+	})
+	facets.Views = viewFacets(viewCounts)
+	return facets
+}
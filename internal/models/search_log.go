@@ -0,0 +1,182 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm/clause"
+)
+
+// likeEscaper 转义 LIKE 通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// SearchLog 访客的一次搜索
+type SearchLog struct {
+	ID          uint       `gorm:"primarykey"`
+	CreatedAt   *time.Time `gorm:"autoCreateTime;index"`
+	Keyword     string     `gorm:"type:varchar(255)"`       // 访客输入的原始关键词
+	Normalized  string     `gorm:"type:varchar(255);index"` // 归一化后的关键词，用于合并统计
+	ResultCount int
+	SortBy      string `gorm:"type:varchar(16)"`
+	Tags        string `gorm:"type:varchar(255)"` // 筛选的标签，逗号分隔
+	Visitor     string `gorm:"type:varchar(16)"`  // 访客IP的摘要，只用于统计不同访客数，不保存原始IP
+}
+
+// SearchStat 按天汇总的搜索统计
+type SearchStat struct {
+	ID           uint      `gorm:"primarykey"`
+	Day          time.Time `gorm:"type:date;uniqueIndex:idx_search_stat_day_query"`
+	Normalized   string    `gorm:"type:varchar(255);uniqueIndex:idx_search_stat_day_query;index"`
+	Keyword      string    `gorm:"type:varchar(255)"` // 展示用的原始写法
+	Searches     int64
+	ZeroResults  int64 // 没有结果的次数
+	TotalResults int64 // 结果数之和，用于计算平均结果数
+	Visitors     int64 // 搜索过的不同访客数
+	UpdatedAt    time.Time
+}
+
+// SearchQueryStat 一段时间内某个关键词的统计
+type SearchQueryStat struct {
+	Normalized   string
+	Keyword      string
+	Searches     int64
+	ZeroResults  int64
+	TotalResults int64
+	LastDay      time.Time
+}
+
+// AvgResults 平均结果数
+func (stat *SearchQueryStat) AvgResults() int64 {
+	if stat.Searches == 0 {
+		return 0
+	}
+	return stat.TotalResults / stat.Searches
+}
+
+// SearchTrend 某一天的搜索总量
+type SearchTrend struct {
+	Day         time.Time
+	Searches    int64
+	ZeroResults int64
+	Queries     int64 // 不同关键词数
+}
+
+// Percent 搜索量占峰值的百分比，用于绘制趋势条
+func (trend *SearchTrend) Percent(peak int64) int64 {
+	if peak == 0 {
+		return 0
+	}
+	return trend.Searches * 100 / peak
+}
+
+// NormalizeSearchKeyword 归一化搜索关键词：全角转半角、转小写、合并空白
+func NormalizeSearchKeyword(keyword string) string {
+	folded := strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			r -= 0xfee0
+		}
+		return unicode.ToLower(r)
+	}, keyword)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// Insert 保存搜索记录
+func (searchLog *SearchLog) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(searchLog).Error
+}
+
+// AggregateSearchStats 汇总指定日期的搜索记录，重复执行会覆盖当天的统计
+func AggregateSearchStats(day time.Time) (int, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	var stats []*SearchStat
+	DB := dao.GetMysqlDB()
+	// 同一关键词的不同写法取字典序最大的一个展示
+	err := DB.Model(&SearchLog{}).
+		Select("normalized, max(keyword) as keyword, count(*) as searches, "+
+			"sum(case when result_count = 0 then 1 else 0 end) as zero_results, sum(result_count) as total_results, "+
+			"count(distinct visitor) as visitors").
+		Where("created_at >= ? and created_at < ?", start, start.AddDate(0, 0, 1)).
+		Group("normalized").
+		Scan(&stats).Error
+	if err != nil || len(stats) == 0 {
+		return 0, err
+	}
+	for _, stat := range stats {
+		stat.Day = start
+	}
+	err = DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "day"}, {Name: "normalized"}},
+		DoUpdates: clause.AssignmentColumns([]string{"keyword", "searches", "zero_results", "total_results", "visitors", "updated_at"}),
+	}).CreateInBatches(stats, 200).Error
+	return len(stats), err
+}
+
+// DeleteSearchLogsBefore 删除指定时间之前的搜索明细
+func DeleteSearchLogsBefore(before time.Time) (int64, error) {
+	DB := dao.GetMysqlDB()
+	result := DB.Where("created_at < ?", before).Delete(&SearchLog{})
+	return result.RowsAffected, result.Error
+}
+
+// ListTopSearchQueries 指定日期之后搜索次数最多的关键词
+func ListTopSearchQueries(since time.Time, limit int) ([]*SearchQueryStat, error) {
+	return listSearchQueries(since, "", limit)
+}
+
+// ListZeroResultQueries 指定日期之后没有结果次数最多的关键词，通常意味着缺少相关内容
+func ListZeroResultQueries(since time.Time, limit int) ([]*SearchQueryStat, error) {
+	return listSearchQueries(since, "sum(zero_results) > 0", limit)
+}
+
+// listSearchQueries 按关键词汇总统计
+func listSearchQueries(since time.Time, having string, limit int) ([]*SearchQueryStat, error) {
+	var stats []*SearchQueryStat
+	DB := dao.GetMysqlDB()
+	query := DB.Model(&SearchStat{}).
+		Select("normalized, max(keyword) as keyword, sum(searches) as searches, sum(zero_results) as zero_results, "+
+			"sum(total_results) as total_results, max(day) as last_day").
+		Where("day >= ?", since).
+		Group("normalized")
+	order := "sum(searches) desc"
+	if having != "" {
+		query = query.Having(having)
+		order = "sum(zero_results) desc, sum(searches) desc"
+	}
+	err := query.Order(order).Limit(limit).Scan(&stats).Error
+	return stats, err
+}
+
+// ListSearchTrends 指定日期之后每天的搜索量
+func ListSearchTrends(since time.Time) ([]*SearchTrend, error) {
+	var trends []*SearchTrend
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&SearchStat{}).
+		Select("day, sum(searches) as searches, sum(zero_results) as zero_results, count(*) as queries").
+		Where("day >= ?", since).
+		Group("day").
+		Order("day").
+		Scan(&trends).Error
+	return trends, err
+}
+
+// ListPopularSearches 以 prefix 开头、有结果的热门搜索，按搜索次数排序
+// 访客数按天去重后累加，同一访客在一天内反复搜索只计一次
+func ListPopularSearches(prefix string, since time.Time, minVisitors int64, limit int) ([]string, error) {
+	var keywords []string
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&SearchStat{}).
+		Select("max(keyword) as keyword").
+		Where("day >= ? and normalized like ?", since, likeEscaper.Replace(NormalizeSearchKeyword(prefix))+"%").
+		Group("normalized").
+		Having("sum(visitors) >= ? and sum(searches) > sum(zero_results)", minVisitors).
+		Order("sum(searches) desc").
+		Limit(limit).
+		Pluck("keyword", &keywords).Error
+	return keywords, err
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"gorm.io/gorm"
+)
+
+// 搜索同步任务的动作
+const (
+	SearchSyncIndex  = "index"
+	SearchSyncDelete = "delete"
+)
+
+const (
+	searchSyncInterval    = 2 * time.Second  // 后台检查待处理任务的间隔
+	searchSyncBatchSize   = 100              // 每批领取的任务数
+	searchSyncLease       = time.Minute      // 领取后的处理时限，超时未完成的任务可被重新领取
+	searchSyncMaxAttempts = 8                // 超过后进入死信，需要在后台手动重试
+	searchSyncBaseBackoff = 10 * time.Second // 第一次失败后的重试间隔，之后每次翻倍
+	searchSyncMaxBackoff  = time.Hour
+)
+
+// SearchOutbox 待同步到搜索引擎的博文修改。与博文修改在同一事务中写入，
+// 由后台任务读取博文的最新内容写入索引，成功后删除，进程崩溃也不会丢失
+type SearchOutbox struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	PostID      uint       `gorm:"index"`
+	Action      string     `gorm:"type:varchar(16)"`
+	Attempts    int        // 已失败的次数
+	AvailableAt time.Time  `gorm:"index"`                  // 可以处理的时间，失败后按退避时间推迟
+	ClaimToken  string     `gorm:"type:varchar(32);index"` // 领取任务的批次，多实例部署时避免重复处理
+	LastError   string     `gorm:"type:text"`
+	DeadAt      *time.Time `gorm:"index"` // 进入死信的时间，不为空时不再自动重试
+}
+
+// searchSyncFailureHandler 任务进入死信时的回调
+var searchSyncFailureHandler func(task *SearchOutbox, cause error)
+
+// SetSearchSyncFailureHandler 设置任务多次失败、进入死信时的回调，用于发送通知
+func SetSearchSyncFailureHandler(handler func(task *SearchOutbox, cause error)) {
+	searchSyncFailureHandler = handler
+}
+
+// EnqueueSearchSync 记录博文的索引任务，在事务中调用时随事务一起提交
+func EnqueueSearchSync(tx *gorm.DB, action string, postIDs ...uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	tasks := make([]*SearchOutbox, 0, len(postIDs))
+	for _, postID := range postIDs {
+		tasks = append(tasks, &SearchOutbox{
+			PostID:      postID,
+			Action:      action,
+			AvailableAt: now,
+		})
+	}
+	return tx.Create(&tasks).Error
+}
+
+// searchSyncWorker 后台处理搜索同步任务
+type searchSyncWorker struct {
+	quit chan struct{}
+	done chan struct{}
+}
+
+var searchSync *searchSyncWorker
+
+// StartSearchSyncWorker 启动搜索同步任务的后台处理
+func StartSearchSyncWorker() {
+	if searchSync != nil {
+		return
+	}
+	searchSync = &searchSyncWorker{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go searchSync.loop()
+	log.Info("搜索同步任务已启动")
+}
+
+// StopSearchSyncWorker 停止后台处理，等待正在处理的批次完成，未处理的任务留在数据库中下次启动继续
+func StopSearchSyncWorker() {
+	if searchSync == nil {
+		return
+	}
+	close(searchSync.quit)
+	<-searchSync.done
+	searchSync = nil
+	log.Info("搜索同步任务已停止")
+}
+
+// loop 定时领取到期的任务，一批处理满时立即处理下一批
+func (w *searchSyncWorker) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(searchSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for {
+				count, err := ProcessSearchOutbox()
+				if err != nil {
+					log.DaemonError("search_sync", "outbox", "处理搜索同步任务失败", "err", err)
+				}
+				if err != nil || count < searchSyncBatchSize {
+					break
+				}
+				select {
+				case <-w.quit:
+					return
+				default:
+				}
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// ProcessSearchOutbox 领取一批到期的任务写入当前搜索引擎，返回领取的任务数
+func ProcessSearchOutbox() (int, error) {
+	token, err := newClaimToken()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	DB := dao.GetMysqlDB()
+	result := DB.Model(&SearchOutbox{}).
+		Where("dead_at IS NULL AND available_at <= ?", now).
+		Order("id").
+		Limit(searchSyncBatchSize).
+		Updates(map[string]interface{}{
+			"claim_token":  token,
+			"available_at": now.Add(searchSyncLease),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0, result.Error
+	}
+
+	var tasks []*SearchOutbox
+	if err := DB.Where("claim_token = ?", token).Find(&tasks).Error; err != nil {
+		return 0, err
+	}
+	engine := CurrentSearchEngine()
+	if err := syncSearchTasks(engine, tasks); err != nil {
+		for _, task := range tasks {
+			task.fail(err)
+		}
+		return len(tasks), nil
+	}
+	// 处理超时后被其他实例重新领取的任务，ClaimToken 已经改变，不会被误删
+	if err := DB.Where("claim_token = ?", token).Delete(&SearchOutbox{}).Error; err != nil {
+		return len(tasks), err
+	}
+	// 索引更新后内容相似度随之变化，重新计算这些博文的相关博文
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.PostID
+	}
+	clearRelatedPostCache(ids...)
+	log.DaemonDebug("search_sync", "outbox", "搜索同步任务完成", "engine", engine.Name(), "count", len(tasks))
+	return len(tasks), nil
+}
+
+// syncSearchTasks 按博文的最新状态写入索引：已发布的写入，未发布或已删除的移除
+func syncSearchTasks(engine SearchEngine, tasks []*SearchOutbox) error {
+	ids := make([]uint, 0, len(tasks))
+	seen := make(map[uint]bool, len(tasks))
+	for _, task := range tasks {
+		if !seen[task.PostID] {
+			seen[task.PostID] = true
+			ids = append(ids, task.PostID)
+		}
+	}
+
+	var posts []*Post
+	DB := dao.GetMysqlDB()
+	if err := DB.Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		return err
+	}
+	published := make([]*Post, 0, len(posts))
+	for _, post := range posts {
+		if post.IsPublished {
+			published = append(published, post)
+			delete(seen, post.ID)
+		}
+	}
+	deleteIDs := make([]uint, 0, len(seen))
+	for _, id := range ids {
+		if seen[id] {
+			deleteIDs = append(deleteIDs, id)
+		}
+	}
+	return engine.Bulk(published, deleteIDs)
+}
+
+// fail 记录失败并按指数退避推迟重试，超过最大次数后进入死信
+func (task *SearchOutbox) fail(cause error) {
+	task.Attempts++
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":    task.Attempts,
+		"claim_token": "",
+		"last_error":  cause.Error(),
+	}
+	if task.Attempts >= searchSyncMaxAttempts {
+		updates["dead_at"] = now
+		log.DaemonError("search_sync", "outbox", "搜索同步任务多次失败，已转入死信", "post_id", task.PostID, "action", task.Action, "err", cause)
+	} else {
+		backoff := min(searchSyncBaseBackoff<<(task.Attempts-1), searchSyncMaxBackoff)
+		updates["available_at"] = now.Add(backoff)
+		log.DaemonWarn("search_sync", "outbox", "搜索同步任务失败，稍后重试", "post_id", task.PostID, "attempts", task.Attempts, "retry_in", backoff, "err", cause)
+	}
+	DB := dao.GetMysqlDB()
+	if err := DB.Model(task).Updates(updates).Error; err != nil {
+		log.DaemonError("search_sync", "outbox", "记录搜索同步失败状态出错", "id", task.ID, "err", err)
+	}
+	if task.Attempts >= searchSyncMaxAttempts && searchSyncFailureHandler != nil {
+		searchSyncFailureHandler(task, cause)
+	}
+}
+
+// newClaimToken 生成领取批次的随机标识
+func newClaimToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// CountSearchOutbox 等待处理和已进入死信的任务数
+func CountSearchOutbox() (pending, dead int64, err error) {
+	DB := dao.GetMysqlDB()
+	if err = DB.Model(&SearchOutbox{}).Where("dead_at IS NULL").Count(&pending).Error; err != nil {
+		return
+	}
+	err = DB.Model(&SearchOutbox{}).Where("dead_at IS NOT NULL").Count(&dead).Error
+	return
+}
+
+// ListDeadSearchTasks 最近进入死信的任务
+func ListDeadSearchTasks(limit int) ([]*SearchOutbox, error) {
+	var tasks []*SearchOutbox
+	DB := dao.GetMysqlDB()
+	err := DB.Where("dead_at IS NOT NULL").Order("dead_at desc, id desc").Limit(limit).Find(&tasks).Error
+	return tasks, err
+}
+
+// RetryDeadSearchTasks 把死信任务放回队列立即重试，ids 为空时重试全部
+func RetryDeadSearchTasks(ids ...uint) (int64, error) {
+	DB := dao.GetMysqlDB()
+	query := DB.Model(&SearchOutbox{}).Where("dead_at IS NOT NULL")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Updates(map[string]interface{}{
+		"dead_at":      nil,
+		"attempts":     0,
+		"claim_token":  "",
+		"available_at": time.Now(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteDeadSearchTasks 丢弃死信任务，ids 为空时丢弃全部
+func DeleteDeadSearchTasks(ids ...uint) (int64, error) {
+	DB := dao.GetMysqlDB()
+	query := DB.Where("dead_at IS NOT NULL")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Delete(&SearchOutbox{})
+	return result.RowsAffected, result.Error
+}
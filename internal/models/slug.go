@@ -0,0 +1,227 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"gorm.io/gorm"
+)
+
+// slug 所属内容类型
+const (
+	SlugKindPost = "post"
+	SlugKindPage = "page"
+)
+
+// slug 最大长度（按字符计）
+const maxSlugLength = 80
+
+// SlugHistory 旧 slug 记录，slug 修改后旧链接仍可 301 跳转到新地址
+type SlugHistory struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	Kind      string     `gorm:"type:varchar(16);uniqueIndex:idx_slug_kind_slug"`  // post or page
+	Slug      string     `gorm:"type:varchar(191);uniqueIndex:idx_slug_kind_slug"` // 旧 slug
+	TargetID  uint       `gorm:"index"`                                            // 对应的博文或页面ID
+}
+
+// 常见拉丁字母音标转写
+var latinTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a",
+	'æ': "ae", 'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ǖ': "u", 'ǘ': "u", 'ǚ': "u", 'ǜ': "u",
+	'ý': "y", 'ÿ': "y", 'ß': "ss", 'œ': "oe", 'ð': "d", 'þ': "th",
+}
+
+// 汉字转拼音的参数：不带声调，多音字取常用读音
+var pinyinArgs = pinyin.NewArgs()
+
+// Slugify 将标题转换为 URL 友好的 slug
+// 拉丁字母转为小写并去掉音标，汉字转为不带声调的拼音，每个字之间用连字符分隔，
+// 假名、谚文等没有转写规则的文字保留原字符，其余字符替换为连字符
+func Slugify(title string) string {
+	var b strings.Builder
+	count := 0
+	// separate 为 true 时下一个字符前需要先写入连字符
+	separate := false
+	for _, r := range strings.ToLower(strings.TrimSpace(title)) {
+		var part string
+		word := false
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			part = string(r)
+		case latinTransliterations[r] != "":
+			part = latinTransliterations[r]
+		case unicode.Is(unicode.Han, r):
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				part = py[0]
+				word = true
+			} else {
+				// 拼音字典中没有的生僻字保留原字符
+				part = string(r)
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// 假名、谚文等直接保留，浏览器地址栏可正常显示
+			part = string(r)
+		default:
+			separate = count > 0
+			continue
+		}
+		// 拼音与前后的字符之间都需要分隔
+		if count > 0 && (separate || word) {
+			part = "-" + part
+		}
+		if count+len([]rune(part)) > maxSlugLength {
+			break
+		}
+		b.WriteString(part)
+		count += len([]rune(part))
+		separate = word
+	}
+	return b.String()
+}
+
+// slugTable 返回 slug 类型对应的数据表
+func slugTable(kind string) string {
+	if kind == SlugKindPage {
+		return "pages"
+	}
+	return "posts"
+}
+
+func slugTaken(kind, slug string, excludeID uint) bool {
+	var count int64
+	DB := dao.GetMysqlDB()
+	DB.Table(slugTable(kind)).Where("slug = ? and id <> ?", slug, excludeID).Count(&count)
+	return count > 0
+}
+
+// UniqueSlug 在 base 基础上生成不与其他内容冲突的 slug，冲突时追加数字后缀
+func UniqueSlug(kind, base string, excludeID uint) string {
+	if base == "" {
+		return ""
+	}
+	slug := base
+	for i := 2; slugTaken(kind, slug, excludeID); i++ {
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return slug
+}
+
+// fallbackSlug slug 无法从标题生成时使用的默认值，同样需要避开其他内容已使用的 slug
+func fallbackSlug(kind string, id uint) string {
+	return UniqueSlug(kind, fmt.Sprintf("%s-%d", kind, id), id)
+}
+
+// slugPath 生成 slug 形式的访问路径
+func slugPath(kind, slug string, id uint) string {
+	if slug == "" {
+		return fmt.Sprintf("/%s/%d", kind, id)
+	}
+	return fmt.Sprintf("/%ss/%s", kind, url.PathEscape(slug))
+}
+
+// currentSlug 查询数据库中当前的 slug
+func currentSlug(kind string, id uint) string {
+	var slugs []string
+	DB := dao.GetMysqlDB()
+	DB.Table(slugTable(kind)).Where("id = ?", id).Pluck("slug", &slugs)
+	if len(slugs) == 0 {
+		return ""
+	}
+	return slugs[0]
+}
+
+// RecordSlugHistory 记录旧 slug，以便旧链接继续跳转
+func RecordSlugHistory(kind, slug string, targetID uint) error {
+	if slug == "" {
+		return nil
+	}
+	DB := dao.GetMysqlDB()
+	history := &SlugHistory{Kind: kind, Slug: slug, TargetID: targetID}
+	return DB.Where(SlugHistory{Kind: kind, Slug: slug}).
+		Assign(SlugHistory{TargetID: targetID}).
+		FirstOrCreate(history).Error
+}
+
+// releaseSlugHistory slug 被重新占用时删除对应的历史记录
+func releaseSlugHistory(kind, slug string) {
+	DB := dao.GetMysqlDB()
+	if err := DB.Where("kind = ? and slug = ?", kind, slug).Delete(&SlugHistory{}).Error; err != nil {
+		log.Warn("release slug history failed", "kind", kind, "slug", slug, "err", err)
+	}
+}
+
+// FindSlugRedirect 根据旧 slug 查找对应内容的ID
+func FindSlugRedirect(kind, slug string) (uint, error) {
+	var history SlugHistory
+	DB := dao.GetMysqlDB()
+	err := DB.First(&history, "kind = ? and slug = ?", kind, slug).Error
+	return history.TargetID, err
+}
+
+// DeleteSlugHistory 删除内容的所有旧 slug 记录
+func DeleteSlugHistory(kind string, targetID uint) error {
+	DB := dao.GetMysqlDB()
+	return DB.Delete(&SlugHistory{}, "kind = ? and target_id = ?", kind, targetID).Error
+}
+
+// assignSlug 为即将保存的内容确定最终 slug，并在 slug 变化时记录历史
+func assignSlug(kind string, id uint, slug, title string) string {
+	// 用户输入的 slug 同样需要规范化
+	slug = Slugify(slug)
+	if slug == "" {
+		slug = Slugify(title)
+	}
+	slug = UniqueSlug(kind, slug, id)
+	if id == 0 {
+		return slug
+	}
+	if slug == "" {
+		slug = fallbackSlug(kind, id)
+	}
+	if old := currentSlug(kind, id); old != "" && old != slug {
+		if err := RecordSlugHistory(kind, old, id); err != nil {
+			log.Warn("record slug history failed", "kind", kind, "id", id, "slug", old, "err", err)
+		}
+	}
+	releaseSlugHistory(kind, slug)
+	return slug
+}
+
+// BackfillSlugs 为尚未设置 slug 的历史博文和页面生成 slug
+func BackfillSlugs(db *gorm.DB) error {
+	var posts []*Post
+	if err := db.Select("id", "title").Where("slug is null or slug = ''").Find(&posts).Error; err != nil {
+		return err
+	}
+	for _, post := range posts {
+		slug := assignSlug(SlugKindPost, post.ID, "", post.Title)
+		if err := db.Model(post).UpdateColumn("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+
+	var pages []*Page
+	if err := db.Select("id", "title").Where("slug is null or slug = ''").Find(&pages).Error; err != nil {
+		return err
+	}
+	for _, page := range pages {
+		slug := assignSlug(SlugKindPage, page.ID, "", page.Title)
+		if err := db.Model(page).UpdateColumn("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	if len(posts)+len(pages) > 0 {
+		log.Info("slug backfilled", "posts", len(posts), "pages", len(pages))
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
 )
 
 type Subscriber struct {
@@ -35,6 +36,22 @@ func (s *Subscriber) Update() error {
 	}).Error
 }
 
+// Activate 激活订阅，webhook 投递在同一事务中提交
+func (s *Subscriber) Activate() error {
+	s.VerifyState = true
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(s).UpdateColumns(map[string]interface{}{
+			"verify_state": true,
+			"out_time":     s.OutTime,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return enqueueSubscriberWebhook(tx, s)
+	})
+}
+
 func ListSubscriber(valid bool) ([]*Subscriber, error) {
 	var subscribers []*Subscriber
 	DB := dao.GetMysqlDB()
@@ -46,6 +63,32 @@ func ListSubscriber(valid bool) ([]*Subscriber, error) {
 	return subscribers, err
 }
 
+// ListSubscriberByFilter 按验证和订阅状态分页列出订阅者，条件为 nil 时不筛选
+func ListSubscriberByFilter(verified, subscribed *bool, pageIndex, pageSize int) ([]*Subscriber, int64, error) {
+	var (
+		subscribers []*Subscriber
+		total       int64
+	)
+	DB := dao.GetMysqlDB()
+	db := DB.Model(&Subscriber{})
+	if verified != nil {
+		db = db.Where("verify_state = ?", *verified)
+	}
+	if subscribed != nil {
+		db = db.Where("subscribe_state = ?", *subscribed)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Order("id desc").Limit(pageSize).Offset((pageIndex - 1) * pageSize).Find(&subscribers).Error
+	return subscribers, total, err
+}
+
+func (s *Subscriber) Delete() error {
+	DB := dao.GetMysqlDB()
+	return DB.Delete(s).Error
+}
+
 func CountSubscriber() (int64, error) {
 	var count int64
 	DB := dao.GetMysqlDB()
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
 )
 
 type Tag struct {
@@ -63,8 +64,80 @@ func CountTag() int64 {
 	return count
 }
 
-/*func ListAllTag() ([]*Tag, error) {
+// ListAllTag 列出全部标签及其关联的博文数（含未发布博文）
+func ListAllTag() ([]*Tag, error) {
 	var tags []*Tag
-	err := DB.Model(&Tag{}).Find(&tags).Error
-	return tags, err
-}*/
+	DB := dao.GetMysqlDB()
+	rows, err := DB.Raw("select t.*,count(pt.id) total from tags t left join post_tags pt on t.id = pt.tag_id group by t.id order by t.id").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag Tag
+		DB.ScanRows(rows, &tag)
+		tags = append(tags, &tag)
+	}
+	return tags, nil
+}
+
+func GetTagById(id uint) (*Tag, error) {
+	var tag Tag
+	DB := dao.GetMysqlDB()
+	err := DB.First(&tag, "id = ?", id).Error
+	return &tag, err
+}
+
+func (tag *Tag) Update() error {
+	DB := dao.GetMysqlDB()
+	var postIDs []uint
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(tag).Update("name", tag.Name).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&PostTag{}).Where("tag_id = ?", tag.ID).Pluck("post_id", &postIDs).Error; err != nil {
+			return err
+		}
+		// 标签名变化后重新索引相关博文
+		return EnqueueSearchSync(tx, SearchSyncIndex, postIDs...)
+	})
+	if err != nil {
+		return err
+	}
+	clearTagPostsCache(postIDs)
+	return nil
+}
+
+// Delete 删除标签及其与博文的关联
+func (tag *Tag) Delete() error {
+	DB := dao.GetMysqlDB()
+	var postIDs []uint
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&PostTag{}).Where("tag_id = ?", tag.ID).Pluck("post_id", &postIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&PostTag{}, "tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(tag).Error; err != nil {
+			return err
+		}
+		return EnqueueSearchSync(tx, SearchSyncIndex, postIDs...)
+	})
+	if err != nil {
+		return err
+	}
+	clearTagPostsCache(postIDs)
+	return nil
+}
+
+// clearTagPostsCache 标签变化后清除相关博文的缓存
+func clearTagPostsCache(postIDs []uint) {
+	for _, id := range postIDs {
+		post, err := GetPostById(id)
+		if err != nil {
+			continue
+		}
+		go post.ClearRelatedCache()
+	}
+}
@@ -7,6 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// 邮箱验证状态
+const (
+	VerifyStateUnverified = "0"
+	VerifyStateVerified   = "1"
+)
+
 type User struct {
 	ID            uint       `gorm:"primarykey"`
 	CreatedAt     *time.Time `gorm:"autoCreateTime"`
@@ -15,19 +21,28 @@ type User struct {
 	Email         string     `gorm:"uniqueindex;index:idx_email_password_lockstate"`
 	Telephone     string
 	Password      string `gorm:"index:idx_email_password_lockstate"`
-	VerifyState   string `gorm:"default:'0'"`
+	VerifyState   string `gorm:"default:'0'"` // 邮箱是否已验证，未验证的邮箱不接收通知
 	SecretKey     string
 	OutTime       time.Time
 	GithubLoginId string `gorm:"uniqueIndex;default:null"`
 	GithubUrl     string
-	IsAdmin       bool
+	IsAdmin       bool   // 与 Role == RoleAdmin 保持一致，兼容旧数据
+	Role          string `gorm:"type:varchar(16);index;default:'reader'"`
 	AvatarUrl     string
 	NickName      string
-	LockState     bool `gorm:"index:idx_email_password_lockstate;default:false"`
+	LockState     bool       `gorm:"index:idx_email_password_lockstate;default:false"`
+	TotpSecret    string     `gorm:"type:varchar(64)"` // 两步验证密钥，未启用时为待确认的密钥
+	TotpEnabled   bool       // 是否已启用两步验证
+	TotpLastStep  int64      // 最近一次使用的验证码时间步，防止重放
+	TotpResetAt   *time.Time // 最近一次通过两步验证的时间，Redis 不可用时此前的失败不再计入限流
 }
 
 func (user *User) Insert() error {
 	DB := dao.GetMysqlDB()
+	if user.Role == "" {
+		user.Role = RoleReader
+	}
+	user.IsAdmin = user.Role == RoleAdmin
 	return DB.Create(user).Error
 }
 
@@ -43,6 +58,7 @@ func (user *User) Update() error {
 		"GithubLoginId": user.GithubLoginId,
 		"GithubUrl":     user.GithubUrl,
 		"IsAdmin":       user.IsAdmin,
+		"Role":          user.Role,
 		"AvatarUrl":     user.AvatarUrl,
 		"NickName":      user.NickName,
 		"LockState":     user.LockState,
@@ -59,7 +75,7 @@ func GetUserByUsername(username string) (*User, error) {
 func GetUserForLogin(email string) (*User, error) {
 	var user User
 	DB := dao.GetMysqlDB()
-	err := DB.Select("id, email, password, lock_state").
+	err := DB.Select("id, email, password, lock_state, role, totp_enabled").
 		Where("email = ?", email).
 		First(&user).Error
 	return &user, err
@@ -90,13 +106,60 @@ func (user *User) UpdateProfile(avatarUrl, nickName string) error {
 	return DB.Model(user).Updates(User{AvatarUrl: avatarUrl, NickName: nickName}).Error
 }
 
+// UpdateEmail 修改邮箱，新邮箱需要重新验证
 func (user *User) UpdateEmail(email string) error {
-	DB := dao.GetMysqlDB()
-	if len(email) > 0 {
-		return DB.Model(user).Update("email", email).Error
-	} else {
-		return DB.Model(user).Update("email", gorm.Expr("NULL")).Error
+	var value interface{} = email
+	if len(email) == 0 {
+		value = gorm.Expr("NULL")
 	}
+	user.Email = email
+	user.VerifyState = VerifyStateUnverified
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).UpdateColumns(map[string]interface{}{
+			"email":        value,
+			"verify_state": VerifyStateUnverified,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// 发往旧邮箱的链接全部作废
+		return deleteLinkSecrets(tx, user.ID)
+	})
+}
+
+// EmailVerified 邮箱是否已通过验证
+func (user *User) EmailVerified() bool {
+	return user.Email != "" && user.VerifyState == VerifyStateVerified
+}
+
+// ConfirmEmail 邮箱验证通过，同时作废验证邮箱的链接
+func (user *User) ConfirmEmail() error {
+	user.VerifyState = VerifyStateVerified
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).UpdateColumn("verify_state", VerifyStateVerified).Error; err != nil {
+			return err
+		}
+		return deleteLinkSecrets(tx, user.ID, LinkPurposeVerify)
+	})
+}
+
+// ResetPassword 通过邮件链接重置密码，能收到邮件说明邮箱属于该用户，同时视为已验证
+func (user *User) ResetPassword(hashedPassword string) error {
+	user.Password = hashedPassword
+	user.VerifyState = VerifyStateVerified
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).UpdateColumns(map[string]interface{}{
+			"password":     hashedPassword,
+			"verify_state": VerifyStateVerified,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return deleteLinkSecrets(tx, user.ID, LinkPurposeReset)
+	})
 }
 
 func (user *User) UpdateGithubUserInfo() error {
@@ -121,9 +184,94 @@ func (user *User) Lock() error {
 	}).Error
 }
 
+// UpdateRole 修改用户角色
+func (user *User) UpdateRole(role string) error {
+	user.Role = role
+	user.IsAdmin = role == RoleAdmin
+	DB := dao.GetMysqlDB()
+	return DB.Model(user).UpdateColumns(map[string]interface{}{
+		"role":     user.Role,
+		"is_admin": user.IsAdmin,
+	}).Error
+}
+
+// CountUsers 用户总数
+func CountUsers() (int64, error) {
+	var count int64
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&User{}).Count(&count).Error
+	return count, err
+}
+
+// SetTOTPSecret 保存待确认的两步验证密钥，验证通过后再调用 EnableTOTP 启用
+func (user *User) SetTOTPSecret(secret string) error {
+	user.TotpSecret = secret
+	user.TotpEnabled = false
+	DB := dao.GetMysqlDB()
+	return DB.Model(user).UpdateColumns(map[string]interface{}{
+		"totp_secret":  secret,
+		"totp_enabled": false,
+	}).Error
+}
+
+// EnableTOTP 启用两步验证，step 为确认时使用的验证码时间步
+func (user *User) EnableTOTP(step int64) error {
+	user.TotpEnabled = true
+	user.TotpLastStep = step
+	DB := dao.GetMysqlDB()
+	return DB.Model(user).UpdateColumns(map[string]interface{}{
+		"totp_enabled":   true,
+		"totp_last_step": step,
+	}).Error
+}
+
+// DisableTOTP 关闭两步验证并作废恢复码
+func (user *User) DisableTOTP() error {
+	user.TotpSecret = ""
+	user.TotpEnabled = false
+	user.TotpLastStep = 0
+	DB := dao.GetMysqlDB()
+	err := DB.Model(user).UpdateColumns(map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return DB.Where("user_id = ?", user.ID).Delete(&RecoveryCode{}).Error
+}
+
+// ResetTOTPFailures 通过两步验证后记录时间，Redis 不可用时此前的验证失败不再计入限流
+func (user *User) ResetTOTPFailures() error {
+	now := time.Now()
+	DB := dao.GetMysqlDB()
+	if err := DB.Model(&User{}).Where("id = ?", user.ID).UpdateColumn("totp_reset_at", now).Error; err != nil {
+		return err
+	}
+	user.TotpResetAt = &now
+	return nil
+}
+
+// UseTOTPStep 记录已使用的验证码时间步，时间步不晚于上次使用的视为重放，返回 false
+func (user *User) UseTOTPStep(step int64) (bool, error) {
+	DB := dao.GetMysqlDB()
+	result := DB.Model(&User{}).
+		Where("id = ? and totp_last_step < ?", user.ID, step).
+		UpdateColumn("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	user.TotpLastStep = step
+	return true, nil
+}
+
 func ListUsers() ([]*User, error) {
 	var users []*User
 	DB := dao.GetMysqlDB()
-	err := DB.Find(&users, "is_admin = ?", false).Error
+	err := DB.Order("id").Find(&users).Error
 	return users, err
 }
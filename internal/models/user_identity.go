@@ -0,0 +1,176 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// IdentityProviderGithub 兼容旧版 GitHub 登录的提供方名称
+const IdentityProviderGithub = "github"
+
+// legacyGithubSubjectPrefix 旧数据只保存了 GitHub 用户名，迁移后首次登录时替换为用户ID
+const legacyGithubSubjectPrefix = "legacy:"
+
+// UserIdentity 第三方登录账号，一个用户在每个提供方最多绑定一个账号
+type UserIdentity struct {
+	ID          uint       `gorm:"primarykey"`
+	CreatedAt   *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   *time.Time `gorm:"autoUpdateTime"`
+	UserID      uint       `gorm:"index"`
+	Provider    string     `gorm:"type:varchar(32);uniqueIndex:idx_provider_subject"`  // 配置中的提供方名称
+	Subject     string     `gorm:"type:varchar(191);uniqueIndex:idx_provider_subject"` // 提供方内不变的用户ID
+	Login       string     `gorm:"type:varchar(128)"`                                  // 用户名，仅用于显示
+	Email       string     `gorm:"type:varchar(191)"`
+	Name        string     `gorm:"type:varchar(128)"`
+	AvatarUrl   string     `gorm:"type:varchar(512)"`
+	ProfileUrl  string     `gorm:"type:varchar(512)"`
+	LastLoginAt *time.Time
+}
+
+// DisplayName 显示用的账号名
+func (identity *UserIdentity) DisplayName() string {
+	if identity.Login != "" {
+		return identity.Login
+	}
+	if identity.Name != "" {
+		return identity.Name
+	}
+	if identity.Email != "" {
+		return identity.Email
+	}
+	return identity.Subject
+}
+
+// GetUserIdentity 根据提供方和用户ID查询绑定关系
+func GetUserIdentity(provider, subject string) (*UserIdentity, error) {
+	var identity UserIdentity
+	DB := dao.GetMysqlDB()
+	err := DB.First(&identity, "provider = ? and subject = ?", provider, subject).Error
+	return &identity, err
+}
+
+// GetLegacyGithubIdentity 查询迁移自旧版 users.github_login_id 的 GitHub 绑定
+func GetLegacyGithubIdentity(login string) (*UserIdentity, error) {
+	return GetUserIdentity(IdentityProviderGithub, legacyGithubSubjectPrefix+login)
+}
+
+// GetUserIdentityByUser 查询用户在某个提供方绑定的账号
+func GetUserIdentityByUser(userID uint, provider string) (*UserIdentity, error) {
+	var identity UserIdentity
+	DB := dao.GetMysqlDB()
+	err := DB.First(&identity, "user_id = ? and provider = ?", userID, provider).Error
+	return &identity, err
+}
+
+// ListUserIdentities 用户绑定的全部第三方账号
+func ListUserIdentities(userID uint) ([]*UserIdentity, error) {
+	var identities []*UserIdentity
+	DB := dao.GetMysqlDB()
+	err := DB.Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// ListIdentitiesByUser 全部用户的第三方账号，按用户ID分组
+func ListIdentitiesByUser() (map[uint][]*UserIdentity, error) {
+	var identities []*UserIdentity
+	DB := dao.GetMysqlDB()
+	if err := DB.Order("id").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uint][]*UserIdentity)
+	for _, identity := range identities {
+		result[identity.UserID] = append(result[identity.UserID], identity)
+	}
+	return result, nil
+}
+
+// Insert 保存绑定关系
+func (identity *UserIdentity) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(identity).Error
+}
+
+// Touch 登录后更新账号资料和最后登录时间
+func (identity *UserIdentity) Touch() error {
+	now := time.Now()
+	identity.LastLoginAt = &now
+	DB := dao.GetMysqlDB()
+	return DB.Model(identity).UpdateColumns(map[string]interface{}{
+		"subject":       identity.Subject,
+		"login":         identity.Login,
+		"email":         identity.Email,
+		"name":          identity.Name,
+		"avatar_url":    identity.AvatarUrl,
+		"profile_url":   identity.ProfileUrl,
+		"last_login_at": now,
+	}).Error
+}
+
+// CreateUserWithIdentity 通过第三方账号首次登录时创建用户
+// 邮箱为空时不写入该列，避免唯一索引冲突
+func CreateUserWithIdentity(user *User, identity *UserIdentity) error {
+	if user.Role == "" {
+		user.Role = RoleReader
+	}
+	user.IsAdmin = user.Role == RoleAdmin
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		db := tx
+		if user.Email == "" {
+			db = db.Omit("Email")
+		}
+		if err := db.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// DeleteUserIdentity 解除用户在某个提供方的绑定
+// 解绑 GitHub 时同时清除旧版字段，避免按用户名重新关联
+func DeleteUserIdentity(userID uint, provider string) error {
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? and provider = ?", userID, provider).Delete(&UserIdentity{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if provider != IdentityProviderGithub {
+			return nil
+		}
+		return tx.Model(&User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"github_login_id": gorm.Expr("NULL"),
+			"github_url":      "",
+		}).Error
+	})
+}
+
+// migrateIdentities 将旧版 users.github_login_id 迁移为 GitHub 绑定关系
+func migrateIdentities(db *gorm.DB) error {
+	var users []*User
+	err := db.Select("id, github_login_id, github_url, avatar_url").
+		Where("github_login_id is not null and github_login_id <> ''").
+		Where("not exists (select 1 from user_identities i where i.user_id = users.id and i.provider = ?)", IdentityProviderGithub).
+		Find(&users).Error
+	if err != nil || len(users) == 0 {
+		return err
+	}
+	identities := make([]*UserIdentity, 0, len(users))
+	for _, user := range users {
+		identities = append(identities, &UserIdentity{
+			UserID:     user.ID,
+			Provider:   IdentityProviderGithub,
+			Subject:    legacyGithubSubjectPrefix + user.GithubLoginId,
+			Login:      user.GithubLoginId,
+			AvatarUrl:  user.AvatarUrl,
+			ProfileUrl: user.GithubUrl,
+		})
+	}
+	return db.Create(&identities).Error
+}
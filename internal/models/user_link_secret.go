@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 邮件链接用途
+const (
+	LinkPurposeReset  = "reset"  // 重置密码
+	LinkPurposeVerify = "verify" // 验证邮箱
+)
+
+// UserLinkSecret 邮件链接的一次性随机数，每个用户每种用途一条，
+// 新链接只使同用途的旧链接失效，重置密码和验证邮箱的链接互不影响
+type UserLinkSecret struct {
+	ID        uint       `gorm:"primarykey"`
+	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
+	UserID    uint       `gorm:"uniqueIndex:uk_user_link_purpose"`
+	Purpose   string     `gorm:"type:varchar(16);uniqueIndex:uk_user_link_purpose"`
+	Nonce     string     `gorm:"type:varchar(64)"`
+	ExpiresAt time.Time
+}
+
+// SetLinkSecret 保存邮件链接的一次性随机数和过期时间，新链接使之前发出的同用途链接失效
+func (user *User) SetLinkSecret(purpose, nonce string, expiresAt time.Time) error {
+	DB := dao.GetMysqlDB()
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "purpose"}},
+		DoUpdates: clause.AssignmentColumns([]string{"nonce", "expires_at", "updated_at"}),
+	}).Create(&UserLinkSecret{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// MatchLinkSecret 判断邮件链接中的随机数是否为该用途最近一次发出且未使用
+func (user *User) MatchLinkSecret(purpose, nonce string) bool {
+	if nonce == "" {
+		return false
+	}
+	var secret UserLinkSecret
+	DB := dao.GetMysqlDB()
+	err := DB.Where("user_id = ? and purpose = ?", user.ID, purpose).Limit(1).Find(&secret).Error
+	if err != nil {
+		log.Error("Query link secret failed", "user_id", user.ID, "purpose", purpose, "err", err)
+		return false
+	}
+	return secret.ID > 0 && secret.Nonce == nonce && time.Now().Before(secret.ExpiresAt)
+}
+
+// deleteLinkSecrets 作废用户的邮件链接，未指定用途时作废全部
+func deleteLinkSecrets(tx *gorm.DB, userID uint, purposes ...string) error {
+	db := tx.Where("user_id = ?", userID)
+	if len(purposes) > 0 {
+		db = db.Where("purpose in ?", purposes)
+	}
+	return db.Delete(&UserLinkSecret{}).Error
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"gorm.io/gorm"
+)
+
+// UserSessionMaxAge 登录会话有效期，与 session cookie 的 MaxAge 一致
+const UserSessionMaxAge = 7 * 24 * time.Hour
+
+// 最后活跃时间的更新间隔，避免每次请求都写数据库
+const sessionTouchInterval = time.Minute
+
+// UserSession 服务端记录的登录会话，cookie 中只保存会话ID，撤销后立即失效
+type UserSession struct {
+	ID         uint       `gorm:"primarykey"`
+	CreatedAt  *time.Time `gorm:"autoCreateTime"`
+	UserID     uint       `gorm:"index"`
+	TokenHash  string     `gorm:"type:char(64);uniqueIndex"` // sha256(会话ID)
+	Device     string     `gorm:"type:varchar(64)"`          // 根据 User-Agent 识别的系统和浏览器
+	IP         string     `gorm:"type:varchar(64)"`
+	UserAgent  string     `gorm:"type:varchar(255)"`
+	LastSeenAt *time.Time
+	ExpiresAt  *time.Time `gorm:"index"`
+	RevokedAt  *time.Time
+}
+
+// IsActive 会话是否未撤销且未过期
+func (session *UserSession) IsActive() bool {
+	return session.RevokedAt == nil && session.ExpiresAt != nil && time.Now().Before(*session.ExpiresAt)
+}
+
+// CreateUserSession 登录成功后创建会话，返回写入 cookie 的会话ID
+func CreateUserSession(userID uint, ip, userAgent string) (string, *UserSession, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	sid := hex.EncodeToString(buf)
+	now := time.Now()
+	expires := now.Add(UserSessionMaxAge)
+	session := &UserSession{
+		UserID:     userID,
+		TokenHash:  HashAccessToken(sid),
+		Device:     deviceName(userAgent),
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: &now,
+		ExpiresAt:  &expires,
+	}
+	DB := dao.GetMysqlDB()
+	// 顺便清理该用户已过期的会话
+	DB.Where("user_id = ? and expires_at < ?", userID, now).Delete(&UserSession{})
+	if err := DB.Create(session).Error; err != nil {
+		return "", nil, err
+	}
+	return sid, session, nil
+}
+
+// GetUserSession 根据会话ID查询未撤销、未过期的会话
+func GetUserSession(sid string) (*UserSession, error) {
+	var session UserSession
+	DB := dao.GetMysqlDB()
+	err := DB.First(&session, "token_hash = ? and revoked_at is null and expires_at > ?", HashAccessToken(sid), time.Now()).Error
+	return &session, err
+}
+
+// ListUserSessions 用户当前有效的会话，最近活跃的在前
+func ListUserSessions(userID uint) ([]*UserSession, error) {
+	var list []*UserSession
+	DB := dao.GetMysqlDB()
+	err := DB.Where("user_id = ? and revoked_at is null and expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").Find(&list).Error
+	return list, err
+}
+
+// RevokeUserSession 撤销用户的某个会话
+func RevokeUserSession(userID, id uint) error {
+	DB := dao.GetMysqlDB()
+	now := time.Now()
+	result := DB.Model(&UserSession{}).Where("id = ? and user_id = ? and revoked_at is null", id, userID).Update("revoked_at", &now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions 撤销用户的全部会话，返回撤销的数量
+func RevokeUserSessions(userID uint) (int64, error) {
+	DB := dao.GetMysqlDB()
+	now := time.Now()
+	result := DB.Model(&UserSession{}).Where("user_id = ? and revoked_at is null", userID).Update("revoked_at", &now)
+	return result.RowsAffected, result.Error
+}
+
+// Touch 记录会话的最后活跃时间和IP
+func (session *UserSession) Touch(ip string) error {
+	now := time.Now()
+	if session.LastSeenAt != nil && now.Sub(*session.LastSeenAt) < sessionTouchInterval && session.IP == ip {
+		return nil
+	}
+	session.LastSeenAt = &now
+	session.IP = ip
+	DB := dao.GetMysqlDB()
+	return DB.Model(session).UpdateColumns(map[string]interface{}{
+		"last_seen_at": now,
+		"ip":           ip,
+	}).Error
+}
+
+// 按顺序匹配，靠前的规则优先（Edge、Opera 的 UA 中同时包含 Chrome）
+var (
+	deviceOSRules = [][2]string{
+		{"Windows", "Windows"},
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+	deviceBrowserRules = [][2]string{
+		{"MicroMessenger", "微信"},
+		{"Edg", "Edge"},
+		{"OPR", "Opera"},
+		{"Firefox", "Firefox"},
+		{"Chrome", "Chrome"},
+		{"Safari", "Safari"},
+		{"curl", "curl"},
+	}
+)
+
+// deviceName 根据 User-Agent 粗略识别系统和浏览器，用于会话列表显示
+func deviceName(userAgent string) string {
+	match := func(rules [][2]string) string {
+		for _, rule := range rules {
+			if strings.Contains(userAgent, rule[0]) {
+				return rule[1]
+			}
+		}
+		return ""
+	}
+	system, browser := match(deviceOSRules), match(deviceBrowserRules)
+	switch {
+	case system != "" && browser != "":
+		return browser + " / " + system
+	case system != "" || browser != "":
+		return system + browser
+	default:
+		return "未知设备"
+	}
+}
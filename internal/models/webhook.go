@@ -0,0 +1,280 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"gorm.io/gorm"
+)
+
+// webhook 事件，对应 X-Bmtdblog-Event 请求头和请求正文中的 event
+const (
+	WebhookPostCreated         = "post.created"
+	WebhookPostUpdated         = "post.updated"
+	WebhookPostPublished       = "post.published"
+	WebhookPostUnpublished     = "post.unpublished"
+	WebhookPostDeleted         = "post.deleted"
+	WebhookCommentCreated      = "comment.created"
+	WebhookSubscriberActivated = "subscriber.activated"
+	WebhookPing                = "ping" // 后台手动发送的测试请求，只发给指定的 webhook
+)
+
+// WebhookEvent 事件及其说明，用于后台页面显示
+type WebhookEvent struct {
+	Name  string
+	Label string
+}
+
+// WebhookEvents 可以订阅的全部事件
+var WebhookEvents = []WebhookEvent{
+	{WebhookPostCreated, "新建博文"},
+	{WebhookPostUpdated, "修改博文"},
+	{WebhookPostPublished, "发布博文"},
+	{WebhookPostUnpublished, "取消发布"},
+	{WebhookPostDeleted, "删除博文"},
+	{WebhookCommentCreated, "新评论"},
+	{WebhookSubscriberActivated, "订阅者激活"},
+}
+
+// IsValidWebhookEvent 判断事件是否可以订阅
+func IsValidWebhookEvent(name string) bool {
+	for _, event := range WebhookEvents {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook 后台配置的 webhook，订阅的事件发生时向 URL 发送签名的 JSON 请求
+type Webhook struct {
+	ID        uint       `gorm:"primarykey"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
+	Name      string     `gorm:"type:varchar(64)"`
+	URL       string     `gorm:"type:varchar(512)"`
+	Secret    string     `gorm:"type:varchar(128)"` // 签名密钥
+	Events    string     `gorm:"type:varchar(255)"` // 订阅的事件，逗号分隔
+	Enabled   bool
+}
+
+// EventList 订阅的事件
+func (webhook *Webhook) EventList() []string {
+	if webhook.Events == "" {
+		return nil
+	}
+	return strings.Split(webhook.Events, ",")
+}
+
+// Subscribes 是否订阅了指定事件
+func (webhook *Webhook) Subscribes(event string) bool {
+	for _, name := range webhook.EventList() {
+		if name == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (webhook *Webhook) Insert() error {
+	DB := dao.GetMysqlDB()
+	return DB.Create(webhook).Error
+}
+
+func (webhook *Webhook) Update() error {
+	DB := dao.GetMysqlDB()
+	return DB.Model(webhook).Updates(map[string]interface{}{
+		"name":    webhook.Name,
+		"url":     webhook.URL,
+		"secret":  webhook.Secret,
+		"events":  webhook.Events,
+		"enabled": webhook.Enabled,
+	}).Error
+}
+
+// Delete 删除 webhook 及其投递记录
+func (webhook *Webhook) Delete() error {
+	DB := dao.GetMysqlDB()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+}
+
+func GetWebhook(id uint) (*Webhook, error) {
+	var webhook Webhook
+	DB := dao.GetMysqlDB()
+	err := DB.First(&webhook, "id = ?", id).Error
+	return &webhook, err
+}
+
+func ListWebhooks() ([]*Webhook, error) {
+	var webhooks []*Webhook
+	DB := dao.GetMysqlDB()
+	err := DB.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// webhookPayload 请求正文
+type webhookPayload struct {
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Site  webhookSite `json:"site"`
+	Data  interface{} `json:"data"`
+}
+
+type webhookSite struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// newWebhookPayload 生成请求正文，投递和重新投递都原样发送
+func newWebhookPayload(event string, data interface{}) (string, error) {
+	cfg := config.GetConfiguration()
+	body, err := json.Marshal(&webhookPayload{
+		Event: event,
+		Time:  time.Now(),
+		Site:  webhookSite{Title: cfg.Title, URL: cfg.Domain},
+		Data:  data,
+	})
+	return string(body), err
+}
+
+// EnqueueWebhookEvent 为订阅了事件的 webhook 创建投递任务，在事务中调用时随事务一起提交，
+// 事务回滚时不会发出请求
+func EnqueueWebhookEvent(tx *gorm.DB, event string, data interface{}) error {
+	var webhooks []*Webhook
+	if err := tx.Where("enabled = ?", true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			ids = append(ids, webhook.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	payload, err := newWebhookPayload(event, data)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]*WebhookDelivery, 0, len(ids))
+	for _, id := range ids {
+		deliveries = append(deliveries, newWebhookDelivery(id, event, payload))
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// EnqueueWebhookPing 向指定 webhook 发送测试请求，不论是否启用和订阅了哪些事件
+func EnqueueWebhookPing(webhook *Webhook) (*WebhookDelivery, error) {
+	payload, err := newWebhookPayload(WebhookPing, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"events":     webhook.EventList(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := newWebhookDelivery(webhook.ID, WebhookPing, payload)
+	DB := dao.GetMysqlDB()
+	return delivery, DB.Create(delivery).Error
+}
+
+// webhookPost 博文事件的 data，不含正文，接收方需要时可以通过 REST API 获取
+type webhookPost struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	URL         string     `json:"url"`
+	IsPublished bool       `json:"is_published"`
+	PublishAt   *time.Time `json:"publish_at"`
+	AuthorID    uint       `json:"author_id"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+func newWebhookPost(post *Post) *webhookPost {
+	return &webhookPost{
+		ID:          post.ID,
+		Title:       post.Title,
+		Slug:        post.Slug,
+		URL:         config.GetConfiguration().Domain + post.URL(),
+		IsPublished: post.IsPublished,
+		PublishAt:   post.PublishAt,
+		AuthorID:    post.AuthorID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+	}
+}
+
+// enqueuePostWebhooks 记录博文的事件，wasPublished 为修改前的发布状态，据此判断是否发布或取消发布
+func enqueuePostWebhooks(tx *gorm.DB, post *Post, event string, wasPublished bool) error {
+	data := newWebhookPost(post)
+	if err := EnqueueWebhookEvent(tx, event, data); err != nil {
+		return err
+	}
+	switch {
+	case post.IsPublished && !wasPublished:
+		return EnqueueWebhookEvent(tx, WebhookPostPublished, data)
+	case !post.IsPublished && wasPublished:
+		return EnqueueWebhookEvent(tx, WebhookPostUnpublished, data)
+	}
+	return nil
+}
+
+// webhookComment 评论事件的 data，包括待审核的评论，接收方可按 status 过滤
+type webhookComment struct {
+	ID        uint         `json:"id"`
+	Content   string       `json:"content"`
+	Status    string       `json:"status"`
+	ParentID  uint         `json:"parent_id"`
+	UserID    uint         `json:"user_id"`
+	NickName  string       `json:"nickname"`
+	Post      *webhookPost `json:"post"`
+	CreatedAt *time.Time   `json:"created_at"`
+}
+
+func enqueueCommentWebhook(tx *gorm.DB, comment *Comment) error {
+	data := &webhookComment{
+		ID:        comment.ID,
+		Content:   comment.Content,
+		Status:    comment.Status,
+		ParentID:  comment.ParentID,
+		UserID:    comment.UserID,
+		CreatedAt: comment.CreatedAt,
+	}
+	var user User
+	if err := tx.Select("nick_name").Limit(1).Find(&user, comment.UserID).Error; err != nil {
+		return err
+	}
+	data.NickName = user.NickName
+	var post Post
+	if err := tx.Limit(1).Find(&post, comment.PostID).Error; err != nil {
+		return err
+	}
+	if post.ID != 0 {
+		data.Post = newWebhookPost(&post)
+	}
+	return EnqueueWebhookEvent(tx, WebhookCommentCreated, data)
+}
+
+// webhookSubscriber 订阅者事件的 data
+type webhookSubscriber struct {
+	ID          uint      `json:"id"`
+	Email       string    `json:"email"`
+	ActivatedAt time.Time `json:"activated_at"`
+}
+
+func enqueueSubscriberWebhook(tx *gorm.DB, subscriber *Subscriber) error {
+	return EnqueueWebhookEvent(tx, WebhookSubscriberActivated, &webhookSubscriber{
+		ID:          subscriber.ID,
+		Email:       subscriber.Email,
+		ActivatedAt: subscriber.OutTime,
+	})
+}
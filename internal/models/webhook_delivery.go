@@ -0,0 +1,214 @@
+package models
+
+import (
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+)
+
+// 投递状态
+const (
+	WebhookDeliveryPending   = "pending"   // 等待投递或等待重试
+	WebhookDeliverySucceeded = "succeeded" // 接收方返回 2xx
+	WebhookDeliveryFailed    = "failed"    // 多次失败后放弃，可以在后台重新投递
+)
+
+const (
+	webhookDeliveryLease       = 10 * time.Minute // 领取后的处理时限，超时未完成的任务可被重新领取
+	webhookDeliveryMaxAttempts = 10               // 超过后放弃
+	webhookDeliveryBaseBackoff = 30 * time.Second // 第一次失败后的重试间隔，之后每次翻倍
+	webhookDeliveryMaxBackoff  = 2 * time.Hour
+	webhookResponseBodySize    = 2048 // 记录的响应正文长度
+)
+
+// WebhookDelivery 一次事件的投递，同时是重试队列和投递记录。与触发事件的修改在同一事务中写入，
+// 由后台任务发送，失败后按指数退避重试
+type WebhookDelivery struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	WebhookID    uint       `gorm:"index"`
+	Event        string     `gorm:"type:varchar(32)"`
+	Payload      string     `gorm:"type:mediumtext"` // 请求正文，重试和重新投递时原样发送
+	Status       string     `gorm:"type:varchar(16);index"`
+	Attempts     int        // 已请求的次数
+	AvailableAt  time.Time  `gorm:"index"`                  // 可以投递的时间，失败后按退避时间推迟
+	ClaimToken   string     `gorm:"type:varchar(32);index"` // 领取任务的批次，多实例部署时避免重复投递
+	ResponseCode int        // 最后一次请求的响应状态码，0 表示没有收到响应
+	ResponseBody string     `gorm:"type:text"` // 最后一次请求的响应正文，只保留开头部分
+	LastError    string     `gorm:"type:text"`
+	Duration     int64      // 最后一次请求的耗时（毫秒）
+	DeliveredAt  *time.Time // 最后一次请求的时间
+	Webhook      *Webhook   `gorm:"-"`
+}
+
+func newWebhookDelivery(webhookID uint, event, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		WebhookID:   webhookID,
+		Event:       event,
+		Payload:     payload,
+		Status:      WebhookDeliveryPending,
+		AvailableAt: time.Now(),
+	}
+}
+
+// ClaimWebhookDeliveries 领取一批到期的投递任务，并加载对应的 webhook
+func ClaimWebhookDeliveries(limit int) ([]*WebhookDelivery, error) {
+	token, err := newClaimToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	DB := dao.GetMysqlDB()
+	result := DB.Model(&WebhookDelivery{}).
+		Where("status = ? AND available_at <= ?", WebhookDeliveryPending, now).
+		Order("id").
+		Limit(limit).
+		Updates(map[string]interface{}{
+			"claim_token":  token,
+			"available_at": now.Add(webhookDeliveryLease),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	var deliveries []*WebhookDelivery
+	if err := DB.Where("claim_token = ?", token).Order("id").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookID)
+	}
+	var webhooks []*Webhook
+	if err := DB.Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		byID[webhook.ID] = webhook
+	}
+	for _, delivery := range deliveries {
+		delivery.Webhook = byID[delivery.WebhookID]
+	}
+	return deliveries, nil
+}
+
+// Complete 记录一次请求的结果，cause 为空表示成功，否则按指数退避推迟重试，
+// 超过最大次数后放弃，返回是否已放弃
+func (delivery *WebhookDelivery) Complete(code int, body []byte, duration time.Duration, cause error) bool {
+	delivery.Attempts++
+	now := time.Now()
+	if len(body) > webhookResponseBodySize {
+		body = body[:webhookResponseBodySize]
+	}
+	updates := map[string]interface{}{
+		"attempts":      delivery.Attempts,
+		"claim_token":   "",
+		"response_code": code,
+		"response_body": string(body),
+		"duration":      duration.Milliseconds(),
+		"delivered_at":  now,
+		"last_error":    "",
+	}
+	failed := false
+	if cause == nil {
+		updates["status"] = WebhookDeliverySucceeded
+	} else {
+		updates["last_error"] = cause.Error()
+		if delivery.Attempts >= webhookDeliveryMaxAttempts {
+			updates["status"] = WebhookDeliveryFailed
+			failed = true
+			log.DaemonError("webhook", "delivery", "webhook 多次投递失败，已放弃", "id", delivery.ID, "webhook_id", delivery.WebhookID, "event", delivery.Event, "err", cause)
+		} else {
+			backoff := min(webhookDeliveryBaseBackoff<<(delivery.Attempts-1), webhookDeliveryMaxBackoff)
+			updates["available_at"] = now.Add(backoff)
+			log.DaemonWarn("webhook", "delivery", "webhook 投递失败，稍后重试", "id", delivery.ID, "webhook_id", delivery.WebhookID, "attempts", delivery.Attempts, "retry_in", backoff, "err", cause)
+		}
+	}
+	DB := dao.GetMysqlDB()
+	if err := DB.Model(delivery).Updates(updates).Error; err != nil {
+		log.DaemonError("webhook", "delivery", "记录 webhook 投递结果出错", "id", delivery.ID, "err", err)
+	}
+	return failed
+}
+
+// Abandon 不再投递，用于 webhook 已停用或删除的任务
+func (delivery *WebhookDelivery) Abandon(reason string) error {
+	DB := dao.GetMysqlDB()
+	return DB.Model(delivery).Updates(map[string]interface{}{
+		"status":      WebhookDeliveryFailed,
+		"claim_token": "",
+		"last_error":  reason,
+	}).Error
+}
+
+func GetWebhookDelivery(id uint) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	DB := dao.GetMysqlDB()
+	err := DB.First(&delivery, "id = ?", id).Error
+	return &delivery, err
+}
+
+// ListWebhookDeliveries webhook 最近的投递记录
+func ListWebhookDeliveries(webhookID uint, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	DB := dao.GetMysqlDB()
+	err := DB.Where("webhook_id = ?", webhookID).Order("id desc").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Redeliver 以相同的请求正文创建一次新的投递，原投递记录保留
+func (delivery *WebhookDelivery) Redeliver() (*WebhookDelivery, error) {
+	redelivery := newWebhookDelivery(delivery.WebhookID, delivery.Event, delivery.Payload)
+	DB := dao.GetMysqlDB()
+	return redelivery, DB.Create(redelivery).Error
+}
+
+// WebhookDeliveryStat webhook 各状态的投递数
+type WebhookDeliveryStat struct {
+	Pending   int64
+	Succeeded int64
+	Failed    int64
+}
+
+// CountWebhookDeliveries 按 webhook 统计各状态的投递数
+func CountWebhookDeliveries() (map[uint]*WebhookDeliveryStat, error) {
+	var rows []struct {
+		WebhookID uint
+		Status    string
+		Total     int64
+	}
+	DB := dao.GetMysqlDB()
+	err := DB.Model(&WebhookDelivery{}).
+		Select("webhook_id, status, count(*) as total").
+		Group("webhook_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[uint]*WebhookDeliveryStat)
+	for _, row := range rows {
+		stat, ok := stats[row.WebhookID]
+		if !ok {
+			stat = &WebhookDeliveryStat{}
+			stats[row.WebhookID] = stat
+		}
+		switch row.Status {
+		case WebhookDeliveryPending:
+			stat.Pending = row.Total
+		case WebhookDeliverySucceeded:
+			stat.Succeeded = row.Total
+		case WebhookDeliveryFailed:
+			stat.Failed = row.Total
+		}
+	}
+	return stats, nil
+}
+
+// DeleteWebhookDeliveriesBefore 删除指定时间之前已结束的投递记录
+func DeleteWebhookDeliveriesBefore(before time.Time) (int64, error) {
+	DB := dao.GetMysqlDB()
+	result := DB.Where("status <> ? AND created_at < ?", WebhookDeliveryPending, before).Delete(&WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
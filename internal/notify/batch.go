@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 合并通知的时间窗口
+const batchWindow = time.Minute
+
+// batch 一个时间窗口内累计的同类通知
+type batch struct {
+	count int
+	last  *Message
+}
+
+var (
+	batchMutex sync.Mutex
+	batches    = make(map[string]*batch)
+)
+
+// NotifyBatched 短时间内大量发生的同类事件合并为一条通知发送，例如搜索引擎不可用时
+// 整批同步任务同时进入死信。窗口内的第一条通知在一分钟后发送，只有一条时原样发送
+func NotifyBatched(event string, msg *Message) {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	batchMutex.Lock()
+	defer batchMutex.Unlock()
+	if pending, ok := batches[event]; ok {
+		pending.count++
+		pending.last = msg
+		return
+	}
+	batches[event] = &batch{count: 1, last: msg}
+	time.AfterFunc(batchWindow, func() {
+		batchMutex.Lock()
+		pending := batches[event]
+		delete(batches, event)
+		batchMutex.Unlock()
+
+		msg := pending.last
+		if pending.count > 1 {
+			msg = &Message{
+				Title: fmt.Sprintf("%s（%d 条）", msg.Title, pending.count),
+				Text:  fmt.Sprintf("最近一分钟内发生 %d 次，最后一次：\n%s", pending.count, msg.Text),
+				Link:  msg.Link,
+				Time:  msg.Time,
+			}
+		}
+		Notify(event, msg)
+	})
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// HeaderDelivery 投递ID，重新投递时不同，接收方可用于去重和排查
+const HeaderDelivery = "X-Bmtdblog-Delivery"
+
+const (
+	deliveryInterval  = 2 * time.Second  // 后台检查待投递任务的间隔
+	deliveryBatchSize = 50               // 每批领取的任务数
+	deliveryTimeout   = 10 * time.Second // 单次请求的超时时间，一批任务全部超时也不超过领取任务的处理时限
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// deliveryWorker 后台投递 webhook
+type deliveryWorker struct {
+	quit chan struct{}
+	done chan struct{}
+}
+
+var delivery *deliveryWorker
+
+// StartWebhookWorker 启动 webhook 的后台投递
+func StartWebhookWorker() {
+	if delivery != nil {
+		return
+	}
+	delivery = &deliveryWorker{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go delivery.loop()
+	log.Info("webhook 投递任务已启动")
+}
+
+// StopWebhookWorker 停止后台投递，等待正在投递的批次完成，未投递的任务留在数据库中下次启动继续
+func StopWebhookWorker() {
+	if delivery == nil {
+		return
+	}
+	close(delivery.quit)
+	<-delivery.done
+	delivery = nil
+	log.Info("webhook 投递任务已停止")
+}
+
+// loop 定时领取到期的任务，一批处理满时立即处理下一批
+func (w *deliveryWorker) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for {
+				count, err := ProcessWebhookDeliveries()
+				if err != nil {
+					log.DaemonError("webhook", "delivery", "处理 webhook 投递任务失败", "err", err)
+				}
+				if err != nil || count < deliveryBatchSize {
+					break
+				}
+				select {
+				case <-w.quit:
+					return
+				default:
+				}
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// ProcessWebhookDeliveries 领取一批到期的任务并发送，返回领取的任务数。
+// 同一 webhook 的任务按创建顺序依次发送，不同 webhook 之间并行
+func ProcessWebhookDeliveries() (int, error) {
+	deliveries, err := models.ClaimWebhookDeliveries(deliveryBatchSize)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+	groups := make(map[uint][]*models.WebhookDelivery)
+	for _, d := range deliveries {
+		groups[d.WebhookID] = append(groups[d.WebhookID], d)
+	}
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group []*models.WebhookDelivery) {
+			defer wg.Done()
+			for _, d := range group {
+				deliver(d)
+			}
+		}(group)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// deliver 发送一次投递并记录结果
+func deliver(d *models.WebhookDelivery) {
+	webhook := d.Webhook
+	// 测试请求不论 webhook 是否启用都发送
+	if webhook == nil || (!webhook.Enabled && d.Event != models.WebhookPing) {
+		if err := d.Abandon("webhook 已删除或停用"); err != nil {
+			log.DaemonError("webhook", "delivery", "记录 webhook 投递结果出错", "id", d.ID, "err", err)
+		}
+		return
+	}
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		HeaderEvent:     d.Event,
+		HeaderDelivery:  strconv.FormatUint(uint64(d.ID), 10),
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(webhook.Secret, timestamp, body),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	start := time.Now()
+	code, respBody, err := PostJSON(ctx, webhook.URL, body, headers)
+	if d.Complete(code, respBody, time.Since(start), err) {
+		NotifyBatched(EventWebhookFailed, &Message{
+			Title: "webhook 多次投递失败",
+			Text:  fmt.Sprintf("webhook：%s\n事件：%s\n错误：%v", webhook.Name, d.Event, err),
+			Link:  fmt.Sprintf("%s/admin/webhook/%d", config.GetConfiguration().Domain, webhook.ID),
+		})
+	}
+}
+
+// CleanWebhookDeliveries 删除 30 天前已结束的投递记录
+func CleanWebhookDeliveries() {
+	deleted, err := models.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryRetention))
+	if err != nil {
+		log.Error("清理 webhook 投递记录失败", "err", err)
+		return
+	}
+	if deleted > 0 {
+		log.Info("已清理 webhook 投递记录", "count", deleted)
+	}
+}
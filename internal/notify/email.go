@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// emailChannel 通过邮件队列发送通知
+type emailChannel struct {
+	to string // 收件人，为空时使用 notify_emails
+}
+
+func newEmailChannel(cfg config.NotifyChannel) (Channel, error) {
+	return &emailChannel{to: cfg.To}, nil
+}
+
+func (channel *emailChannel) Send(ctx context.Context, msg *Message) error {
+	cfg := config.GetConfiguration()
+	to := channel.to
+	if to == "" {
+		to = cfg.NotifyEmails
+	}
+	var recipients []string
+	for _, email := range strings.Split(to, ";") {
+		if email = strings.TrimSpace(email); email != "" {
+			recipients = append(recipients, email)
+		}
+	}
+	// 未配置收件人或未启用邮件时不发送，与原来的行为一致
+	if len(recipients) == 0 || !cfg.Smtp.Enabled {
+		return nil
+	}
+
+	to = strings.Join(recipients, ";")
+	subject := fmt.Sprintf("[%s]%s", cfg.Title, msg.Title)
+	body := emailBody(msg)
+	if err := dao.PushEmailTask(to, subject, body); err != nil {
+		// 队列失败时降级到同步发送
+		log.Warn("邮件队列发送失败，降级到同步发送", "err", err)
+		return common.SendMail(to, subject, body)
+	}
+	return nil
+}
+
+// emailBody 通知正文转为 HTML，换行保留
+func emailBody(msg *Message) string {
+	var body strings.Builder
+	body.WriteString(strings.ReplaceAll(html.EscapeString(msg.Text), "\n", "<br/>"))
+	if msg.Link != "" {
+		fmt.Fprintf(&body, "<br/><a href=\"%s\" target=\"_blank\">%s</a>", html.EscapeString(msg.Link), html.EscapeString(msg.Link))
+	}
+	return body.String()
+}
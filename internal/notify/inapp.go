@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// inAppChannel 保存为后台站内通知
+type inAppChannel struct{}
+
+func newInAppChannel(cfg config.NotifyChannel) (Channel, error) {
+	return inAppChannel{}, nil
+}
+
+func (inAppChannel) Send(ctx context.Context, msg *Message) error {
+	notification := &models.Notification{
+		Event: msg.Event,
+		Title: msg.Title,
+		Body:  msg.Text,
+		Link:  msg.Link,
+	}
+	return notification.Insert()
+}
@@ -0,0 +1,300 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+)
+
+// 通知事件，对应配置 [notify.routes] 中的键
+const (
+	EventComment          = "comment"            // 新评论，包括待审核的评论
+	EventSubscriber       = "subscriber"         // 订阅者激活订阅
+	EventBackupFailed     = "backup_failed"      // 定时备份失败
+	EventEmailDeadLetter  = "email_dead_letter"  // 邮件多次发送失败，进入失败队列
+	EventSearchSyncFailed = "search_sync_failed" // 搜索同步任务多次失败，进入死信
+	EventWebhookFailed    = "webhook_failed"     // webhook 多次投递失败，已放弃
+)
+
+// EventInfo 事件及其说明，用于后台页面显示
+type EventInfo struct {
+	Name  string
+	Label string
+}
+
+// Events 全部通知事件
+var Events = []EventInfo{
+	{EventComment, "新评论"},
+	{EventSubscriber, "新订阅者"},
+	{EventBackupFailed, "备份失败"},
+	{EventEmailDeadLetter, "邮件发送失败"},
+	{EventSearchSyncFailed, "搜索同步失败"},
+	{EventWebhookFailed, "webhook 投递失败"},
+}
+
+// 渠道类型，对应配置 [[notify.channels]] 的 type
+const (
+	TypeEmail    = "email"
+	TypeWebhook  = "webhook"
+	TypeTelegram = "telegram"
+	TypeDingTalk = "dingtalk"
+	TypeFeishu   = "feishu"
+	TypeWeCom    = "wecom"
+	TypeInApp    = "inapp"
+)
+
+// 发送超时的默认值
+const defaultTimeout = 10 * time.Second
+
+// Message 一条通知
+type Message struct {
+	Event string
+	Title string
+	Text  string // 纯文本正文
+	Link  string // 相关页面的完整地址，可以为空
+	Time  time.Time
+}
+
+// Channel 通知渠道
+type Channel interface {
+	// Send 发送通知，ctx 超时后应放弃发送
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ChannelInfo 已配置的渠道，用于后台页面显示
+type ChannelInfo struct {
+	Name   string
+	Type   string
+	Target string // 发送目标，隐藏了密钥等敏感信息
+	Error  string // 配置有误时的原因，此时渠道不可用
+}
+
+// channelFactory 按配置创建渠道
+type channelFactory func(cfg config.NotifyChannel) (Channel, error)
+
+var factories = map[string]channelFactory{
+	TypeEmail:    newEmailChannel,
+	TypeWebhook:  newWebhookChannel,
+	TypeTelegram: newTelegramChannel,
+	TypeDingTalk: newDingTalkChannel,
+	TypeFeishu:   newFeishuChannel,
+	TypeWeCom:    newWeComChannel,
+	TypeInApp:    newInAppChannel,
+}
+
+// defaultRoutes 未在配置中出现的事件发送到的渠道
+var defaultRoutes = map[string][]string{
+	EventComment: {TypeEmail, TypeInApp},
+}
+
+// Notifier 按事件路由把通知发送到各渠道
+type Notifier struct {
+	channels map[string]Channel
+	timeouts map[string]time.Duration
+	infos    []*ChannelInfo
+	routes   map[string][]string
+}
+
+// New 按配置创建 Notifier，配置有误的渠道不可用，不影响其他渠道
+func New(cfg *config.Configuration) *Notifier {
+	notifier := &Notifier{
+		channels: make(map[string]Channel),
+		timeouts: make(map[string]time.Duration),
+		routes:   cfg.Notify.Routes,
+	}
+	// 内置渠道，同名配置覆盖
+	channelConfigs := []config.NotifyChannel{
+		{Name: TypeEmail, Type: TypeEmail},
+		{Name: TypeInApp, Type: TypeInApp},
+	}
+	for _, channelConfig := range cfg.Notify.Channels {
+		replaced := false
+		for i := range channelConfigs {
+			if channelConfigs[i].Name == channelConfig.Name {
+				channelConfigs[i] = channelConfig
+				replaced = true
+			}
+		}
+		if !replaced {
+			channelConfigs = append(channelConfigs, channelConfig)
+		}
+	}
+
+	for _, channelConfig := range channelConfigs {
+		info := &ChannelInfo{Name: channelConfig.Name, Type: channelConfig.Type, Target: channelTarget(channelConfig)}
+		notifier.infos = append(notifier.infos, info)
+		channel, err := newChannel(channelConfig)
+		if err != nil {
+			info.Error = err.Error()
+			log.Error("通知渠道配置有误", "channel", channelConfig.Name, "type", channelConfig.Type, "err", err)
+			continue
+		}
+		notifier.channels[channelConfig.Name] = channel
+		notifier.timeouts[channelConfig.Name] = defaultTimeout
+		if channelConfig.Timeout > 0 {
+			notifier.timeouts[channelConfig.Name] = time.Duration(channelConfig.Timeout) * time.Second
+		}
+	}
+	return notifier
+}
+
+// newChannel 按类型创建渠道
+func newChannel(cfg config.NotifyChannel) (Channel, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("渠道名称为空")
+	}
+	factory, ok := factories[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("未知的渠道类型: %s", cfg.Type)
+	}
+	return factory(cfg)
+}
+
+// channelTarget 渠道的发送目标，URL 中可能带有令牌，只显示主机名
+func channelTarget(cfg config.NotifyChannel) string {
+	switch cfg.Type {
+	case TypeEmail:
+		if cfg.To != "" {
+			return cfg.To
+		}
+		return config.GetConfiguration().NotifyEmails
+	case TypeTelegram:
+		return "chat " + cfg.ChatID
+	case TypeInApp:
+		return "后台通知"
+	}
+	return urlHost(cfg.URL)
+}
+
+// Route 事件发送到的渠道名称
+func (notifier *Notifier) Route(event string) []string {
+	if names, ok := notifier.routes[event]; ok {
+		return names
+	}
+	if names, ok := defaultRoutes[event]; ok {
+		return names
+	}
+	return []string{TypeInApp}
+}
+
+// Channels 全部渠道，按配置顺序排列
+func (notifier *Notifier) Channels() []*ChannelInfo {
+	return notifier.infos
+}
+
+// Notify 在后台把通知发送到事件对应的各渠道，失败时只记录日志
+func (notifier *Notifier) Notify(event string, msg *Message) {
+	msg.Event = event
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	for _, name := range notifier.Route(event) {
+		// 邮件失败的通知再通过邮件发送只会继续失败
+		if event == EventEmailDeadLetter && name == TypeEmail {
+			continue
+		}
+		go func(name string) {
+			if err := notifier.Send(name, msg); err != nil {
+				log.Warn("发送通知失败", "event", event, "channel", name, "err", err)
+			}
+		}(name)
+	}
+}
+
+// Send 同步发送到指定渠道
+func (notifier *Notifier) Send(name string, msg *Message) error {
+	channel, ok := notifier.channels[name]
+	if !ok {
+		return fmt.Errorf("通知渠道不存在或配置有误: %s", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifier.timeouts[name])
+	defer cancel()
+	return channel.Send(ctx, msg)
+}
+
+// RouteInfo 事件及其发送到的渠道，用于后台页面显示
+type RouteInfo struct {
+	Event    EventInfo
+	Channels []string
+}
+
+// Routes 全部事件的路由，包括默认路由
+func (notifier *Notifier) Routes() []*RouteInfo {
+	routes := make([]*RouteInfo, 0, len(Events))
+	for _, event := range Events {
+		routes = append(routes, &RouteInfo{Event: event, Channels: notifier.Route(event.Name)})
+	}
+	// 配置中出现的未知事件也显示出来，便于发现拼写错误
+	var unknown []string
+	for event := range notifier.routes {
+		if !knownEvent(event) {
+			unknown = append(unknown, event)
+		}
+	}
+	sort.Strings(unknown)
+	for _, event := range unknown {
+		routes = append(routes, &RouteInfo{Event: EventInfo{Name: event, Label: "未知事件"}, Channels: notifier.routes[event]})
+	}
+	return routes
+}
+
+// knownEvent 是否是已定义的事件
+func knownEvent(name string) bool {
+	for _, event := range Events {
+		if event.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	defaultMutex    sync.RWMutex
+	defaultNotifier *Notifier
+)
+
+// Init 按当前配置创建全局 Notifier，并接收邮件队列和搜索同步任务的失败事件，需在邮件队列初始化之后调用
+func Init() {
+	cfg := config.GetConfiguration()
+	notifier := New(cfg)
+	defaultMutex.Lock()
+	defaultNotifier = notifier
+	defaultMutex.Unlock()
+
+	dao.SetEmailFailureHandler(func(task dao.EmailTask, err error) {
+		NotifyBatched(EventEmailDeadLetter, &Message{
+			Title: "邮件多次发送失败",
+			Text:  fmt.Sprintf("收件人：%s\n主题：%s\n错误：%v", task.To, task.Subject, err),
+			Link:  cfg.Domain + "/admin/email-queue",
+		})
+	})
+	models.SetSearchSyncFailureHandler(func(task *models.SearchOutbox, cause error) {
+		NotifyBatched(EventSearchSyncFailed, &Message{
+			Title: "搜索同步任务进入死信",
+			Text:  fmt.Sprintf("博文：#%d\n动作：%s\n错误：%v", task.PostID, task.Action, cause),
+			Link:  cfg.Domain + "/admin/search-sync",
+		})
+	})
+	log.Info("通知渠道已加载", "channels", len(notifier.channels))
+}
+
+// Default 全局 Notifier，未初始化时返回 nil
+func Default() *Notifier {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultNotifier
+}
+
+// Notify 通过全局 Notifier 发送通知，未初始化时忽略
+func Notify(event string, msg *Message) {
+	if notifier := Default(); notifier != nil {
+		notifier.Notify(event, msg)
+	}
+}
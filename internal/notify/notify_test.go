@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.LoadConfiguration("../../configs/conf.toml"); err != nil {
+		panic(err)
+	}
+	log.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// request 测试服务器收到的请求
+type request struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// newServer 按给定状态码和正文响应的测试服务器，收到的请求依次写入返回的通道
+func newServer(t *testing.T, status int, response string) (*httptest.Server, <-chan *request) {
+	t.Helper()
+	requests := make(chan *request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- &request{path: r.URL.Path, query: r.URL.Query(), header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+// received 取出测试服务器收到的请求
+func received(t *testing.T, requests <-chan *request) *request {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	default:
+		t.Fatal("server received no request")
+		return nil
+	}
+}
+
+var testMessage = &Message{
+	Event: EventComment,
+	Title: "新评论",
+	Text:  "张三：写得好",
+	Link:  "https://blog.example.com/post/1",
+	Time:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestRoute(t *testing.T) {
+	notifier := New(&config.Configuration{Notify: config.NotifyConfig{
+		Routes: map[string][]string{
+			EventComment:      {"ops"},
+			EventBackupFailed: {},
+		},
+	}})
+	tests := []struct {
+		event string
+		want  []string
+	}{
+		// 配置中的路由优先，显式配置为空表示不发送
+		{EventComment, []string{"ops"}},
+		{EventBackupFailed, []string{}},
+		{EventSubscriber, []string{TypeInApp}},
+		{"unknown", []string{TypeInApp}},
+	}
+	for _, tt := range tests {
+		if got := notifier.Route(tt.event); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Route(%q) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+
+	// 未配置的事件使用 defaultRoutes
+	notifier = New(&config.Configuration{})
+	if got, want := notifier.Route(EventComment), []string{TypeEmail, TypeInApp}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default Route(comment) = %q, want %q", got, want)
+	}
+	if got, want := notifier.Route(EventWebhookFailed), []string{TypeInApp}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default Route(webhook_failed) = %q, want %q", got, want)
+	}
+}
+
+func TestRoutesListsUnknownEvents(t *testing.T) {
+	notifier := New(&config.Configuration{Notify: config.NotifyConfig{
+		Routes: map[string][]string{"commnet": {"ops"}},
+	}})
+	routes := notifier.Routes()
+	if len(routes) != len(Events)+1 {
+		t.Fatalf("got %d routes, want %d", len(routes), len(Events)+1)
+	}
+	last := routes[len(routes)-1]
+	if last.Event.Name != "commnet" || last.Event.Label != "未知事件" || !reflect.DeepEqual(last.Channels, []string{"ops"}) {
+		t.Errorf("unknown event route = %+v", last)
+	}
+}
+
+func TestNewChannels(t *testing.T) {
+	notifier := New(&config.Configuration{Notify: config.NotifyConfig{
+		Channels: []config.NotifyChannel{
+			{Name: "hook", Type: TypeWebhook, URL: "https://hooks.example.com/path?token=secret", Timeout: 3},
+			{Name: "bad", Type: TypeWebhook, URL: "ftp://example.com"},
+			{Name: "tg", Type: TypeTelegram},
+			{Name: "what", Type: "pager"},
+			{Name: TypeEmail, Type: TypeEmail, To: "ops@example.com"},
+		},
+	}})
+	infos := make(map[string]*ChannelInfo)
+	var names []string
+	for _, info := range notifier.Channels() {
+		infos[info.Name] = info
+		names = append(names, info.Name)
+	}
+	// 同名配置覆盖内置渠道，保持内置渠道的位置
+	if want := []string{TypeEmail, TypeInApp, "hook", "bad", "tg", "what"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("channels = %q, want %q", names, want)
+	}
+	if got := infos["hook"]; got.Target != "hooks.example.com" || got.Error != "" {
+		t.Errorf("hook = %+v, want host only and no error", got)
+	}
+	if got := infos[TypeEmail].Target; got != "ops@example.com" {
+		t.Errorf("email target = %q", got)
+	}
+	for _, name := range []string{"bad", "tg", "what"} {
+		if infos[name].Error == "" {
+			t.Errorf("channel %s should report a config error", name)
+		}
+		if _, ok := notifier.channels[name]; ok {
+			t.Errorf("misconfigured channel %s should not be usable", name)
+		}
+	}
+	if got := notifier.timeouts["hook"]; got != 3*time.Second {
+		t.Errorf("hook timeout = %v, want 3s", got)
+	}
+	if got := notifier.timeouts[TypeInApp]; got != defaultTimeout {
+		t.Errorf("inapp timeout = %v, want %v", got, defaultTimeout)
+	}
+	if err := notifier.Send("bad", testMessage); err == nil {
+		t.Error("Send to misconfigured channel should fail")
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	notifier := New(&config.Configuration{})
+	notifier.channels["slow"] = &webhookChannel{url: server.URL}
+	notifier.timeouts["slow"] = 50 * time.Millisecond
+	err := notifier.Send("slow", testMessage)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Send = %v, want deadline exceeded", err)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	if got, want := plainText(testMessage), "新评论\n\n张三：写得好\n\nhttps://blog.example.com/post/1"; got != want {
+		t.Errorf("plainText = %q, want %q", got, want)
+	}
+	if got := plainText(&Message{Title: "只有标题"}); got != "只有标题" {
+		t.Errorf("plainText(title only) = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// 钉钉、飞书和企业微信群机器人，都是向 webhook 地址 POST 文本消息，
+// 区别在于消息格式、加签方式和表示失败的响应字段
+
+// dingTalkChannel 钉钉群机器人
+type dingTalkChannel struct {
+	url    string
+	secret string // 加签密钥，机器人未启用加签时为空
+}
+
+func newDingTalkChannel(cfg config.NotifyChannel) (Channel, error) {
+	if err := checkURL(cfg.URL); err != nil {
+		return nil, err
+	}
+	return &dingTalkChannel{url: cfg.URL, secret: cfg.Secret}, nil
+}
+
+func (channel *dingTalkChannel) Send(ctx context.Context, msg *Message) error {
+	target := channel.url
+	if channel.secret != "" {
+		// 钉钉加签：以密钥对「毫秒时间戳\n密钥」计算 HMAC-SHA256，放在地址参数中
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(channel.secret))
+		mac.Write([]byte(timestamp + "\n" + channel.secret))
+		sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + "timestamp=" + timestamp + "&sign=" + url.QueryEscape(sign)
+	}
+	return sendRobot(ctx, "dingtalk", target, map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": plainText(msg)},
+	})
+}
+
+// feishuChannel 飞书群机器人
+type feishuChannel struct {
+	url    string
+	secret string
+}
+
+func newFeishuChannel(cfg config.NotifyChannel) (Channel, error) {
+	if err := checkURL(cfg.URL); err != nil {
+		return nil, err
+	}
+	return &feishuChannel{url: cfg.URL, secret: cfg.Secret}, nil
+}
+
+func (channel *feishuChannel) Send(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": plainText(msg)},
+	}
+	if channel.secret != "" {
+		// 飞书加签：以「秒级时间戳\n密钥」为密钥对空内容计算 HMAC-SHA256，放在请求正文中
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+channel.secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	return sendRobot(ctx, "feishu", channel.url, payload)
+}
+
+// weComChannel 企业微信群机器人，地址中的 key 即为凭证，没有加签
+type weComChannel struct {
+	url string
+}
+
+func newWeComChannel(cfg config.NotifyChannel) (Channel, error) {
+	if err := checkURL(cfg.URL); err != nil {
+		return nil, err
+	}
+	return &weComChannel{url: cfg.URL}, nil
+}
+
+func (channel *weComChannel) Send(ctx context.Context, msg *Message) error {
+	return sendRobot(ctx, "wecom", channel.url, map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": plainText(msg)},
+	})
+}
+
+// robotResult 机器人接口的响应，钉钉和企业微信使用 errcode，飞书使用 code
+type robotResult struct {
+	ErrCode *int   `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Code    *int   `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+// sendRobot 发送消息并检查响应中的错误码，这些接口出错时 HTTP 状态码通常仍为 200
+func sendRobot(ctx context.Context, kind, target string, payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, respBody, err := PostJSON(ctx, target, body, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	var result robotResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("%s: 解析响应失败: %w", kind, err)
+	}
+	if result.ErrCode != nil && *result.ErrCode != 0 {
+		return fmt.Errorf("%s: 错误码 %d: %s", kind, *result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != nil && *result.Code != 0 {
+		return fmt.Errorf("%s: 错误码 %d: %s", kind, *result.Code, result.Msg)
+	}
+	return nil
+}
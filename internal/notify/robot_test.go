@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// checkTimestamp 检查时间戳是否在发送前后之间
+func checkTimestamp(t *testing.T, raw string, before, after time.Time, milli bool) {
+	t.Helper()
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp %q: %v", raw, err)
+	}
+	low, high := before.Unix(), after.Unix()
+	if milli {
+		low, high = before.UnixMilli(), after.UnixMilli()
+	}
+	if value < low || value > high {
+		t.Errorf("timestamp %d not in [%d, %d]", value, low, high)
+	}
+}
+
+func TestDingTalkSend(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	channel, err := newDingTalkChannel(config.NotifyChannel{URL: server.URL + "/robot/send?access_token=abc", Secret: "SECxyz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+
+	// 加签参数追加在已有的参数之后
+	if req.path != "/robot/send" || req.query.Get("access_token") != "abc" {
+		t.Errorf("url = %s?%s", req.path, req.query.Encode())
+	}
+	timestamp := req.query.Get("timestamp")
+	checkTimestamp(t, timestamp, before, time.Now(), true)
+	mac := hmac.New(sha256.New, []byte("SECxyz"))
+	mac.Write([]byte(timestamp + "\nSECxyz"))
+	if got, want := req.query.Get("sign"), base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("sign = %q, want %q", got, want)
+	}
+
+	var payload struct {
+		MsgType string `json:"msgtype"`
+		Text    struct {
+			Content string `json:"content"`
+		} `json:"text"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.MsgType != "text" || payload.Text.Content != plainText(testMessage) {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestDingTalkSendWithoutSecret(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"errcode":0}`)
+	channel, err := newDingTalkChannel(config.NotifyChannel{URL: server.URL + "/robot/send"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if query := received(t, requests).query; len(query) != 0 {
+		t.Errorf("query = %v, want no signature", query)
+	}
+}
+
+func TestFeishuSend(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"code":0,"msg":"success"}`)
+	channel, err := newFeishuChannel(config.NotifyChannel{URL: server.URL + "/open-apis/bot/v2/hook/abc", Secret: "fs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+
+	var payload struct {
+		MsgType string `json:"msg_type"`
+		Content struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		Timestamp string `json:"timestamp"`
+		Sign      string `json:"sign"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.MsgType != "text" || payload.Content.Text != plainText(testMessage) {
+		t.Errorf("payload = %+v", payload)
+	}
+	// 飞书加签：以「时间戳\n密钥」为密钥对空内容计算
+	checkTimestamp(t, payload.Timestamp, before, time.Now(), false)
+	mac := hmac.New(sha256.New, []byte(payload.Timestamp+"\nfs"))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); payload.Sign != want {
+		t.Errorf("sign = %q, want %q", payload.Sign, want)
+	}
+	if len(req.query) != 0 {
+		t.Errorf("query = %v, want signature in body only", req.query)
+	}
+}
+
+func TestFeishuSendWithoutSecret(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"code":0}`)
+	channel, err := newFeishuChannel(config.NotifyChannel{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(received(t, requests).body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	for _, key := range []string{"timestamp", "sign"} {
+		if _, ok := payload[key]; ok {
+			t.Errorf("payload has %q without secret", key)
+		}
+	}
+}
+
+func TestWeComSend(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"errcode":0,"errmsg":"ok"}`)
+	channel, err := newWeComChannel(config.NotifyChannel{URL: server.URL + "/cgi-bin/webhook/send?key=k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+	if req.query.Get("key") != "k" || len(req.query) != 1 {
+		t.Errorf("query = %v", req.query)
+	}
+	var payload struct {
+		MsgType string `json:"msgtype"`
+		Text    struct {
+			Content string `json:"content"`
+		} `json:"text"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.MsgType != "text" || payload.Text.Content != plainText(testMessage) {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestRobotSendErrors(t *testing.T) {
+	channels := map[string]func(config.NotifyChannel) (Channel, error){
+		TypeDingTalk: newDingTalkChannel,
+		TypeFeishu:   newFeishuChannel,
+		TypeWeCom:    newWeComChannel,
+	}
+	tests := []struct {
+		name     string
+		kind     string
+		status   int
+		response string
+		want     string
+	}{
+		// 这些接口出错时状态码通常仍为 200，错误在响应正文中
+		{"dingtalk errcode", TypeDingTalk, http.StatusOK, `{"errcode":310000,"errmsg":"sign not match"}`, "错误码 310000: sign not match"},
+		{"feishu code", TypeFeishu, http.StatusOK, `{"code":19021,"msg":"sign match fail"}`, "错误码 19021: sign match fail"},
+		{"wecom errcode", TypeWeCom, http.StatusOK, `{"errcode":93000,"errmsg":"invalid webhook url"}`, "错误码 93000"},
+		{"dingtalk http error", TypeDingTalk, http.StatusInternalServerError, `{"errcode":0}`, "500"},
+		{"feishu http error", TypeFeishu, http.StatusBadGateway, `bad gateway`, "502"},
+		{"wecom http error", TypeWeCom, http.StatusForbidden, ``, "403"},
+		{"invalid json", TypeFeishu, http.StatusOK, `<html>`, "解析响应失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newServer(t, tt.status, tt.response)
+			channel, err := channels[tt.kind](config.NotifyChannel{URL: server.URL, Secret: "s"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = channel.Send(context.Background(), testMessage)
+			if err == nil {
+				t.Fatal("Send should fail")
+			}
+			if !strings.HasPrefix(err.Error(), tt.kind+": ") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Send = %v, want %q error containing %q", err, tt.kind, tt.want)
+			}
+		})
+	}
+
+	for kind, factory := range channels {
+		if _, err := factory(config.NotifyChannel{}); err == nil {
+			t.Errorf("%s without url should fail", kind)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// Telegram Bot API 的默认地址
+const telegramAPI = "https://api.telegram.org"
+
+// telegramChannel 通过 Telegram Bot API 发送消息，兼容同样接口的其他机器人服务
+type telegramChannel struct {
+	endpoint string
+	chatID   string
+}
+
+func newTelegramChannel(cfg config.NotifyChannel) (Channel, error) {
+	if cfg.Token == "" || cfg.ChatID == "" {
+		return nil, fmt.Errorf("未配置 token 或 chat_id")
+	}
+	api := cfg.URL
+	if api == "" {
+		api = telegramAPI
+	}
+	if err := checkURL(api); err != nil {
+		return nil, err
+	}
+	return &telegramChannel{
+		endpoint: strings.TrimRight(api, "/") + "/bot" + cfg.Token + "/sendMessage",
+		chatID:   cfg.ChatID,
+	}, nil
+}
+
+func (channel *telegramChannel) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  channel.chatID,
+		"text":                     plainText(msg),
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	_, respBody, err := PostJSON(ctx, channel.endpoint, body, nil)
+	if err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("telegram: 解析响应失败: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
+
+// plainText 纯文本格式的通知：标题、正文和链接各占一段
+func plainText(msg *Message) string {
+	parts := []string{msg.Title}
+	if msg.Text != "" {
+		parts = append(parts, msg.Text)
+	}
+	if msg.Link != "" {
+		parts = append(parts, msg.Link)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+func TestNewTelegramChannel(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.NotifyChannel
+		endpoint string
+		wantErr  bool
+	}{
+		{"default api", config.NotifyChannel{Token: "123:abc", ChatID: "42"}, telegramAPI + "/bot123:abc/sendMessage", false},
+		{"custom api", config.NotifyChannel{URL: "https://tg.example.com/", Token: "t", ChatID: "42"}, "https://tg.example.com/bott/sendMessage", false},
+		{"no token", config.NotifyChannel{ChatID: "42"}, "", true},
+		{"no chat", config.NotifyChannel{Token: "t"}, "", true},
+		{"bad api", config.NotifyChannel{URL: "tg.example.com", Token: "t", ChatID: "42"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, err := newTelegramChannel(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && channel.(*telegramChannel).endpoint != tt.endpoint {
+				t.Errorf("endpoint = %q, want %q", channel.(*telegramChannel).endpoint, tt.endpoint)
+			}
+		})
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, `{"ok":true,"result":{}}`)
+	channel, err := newTelegramChannel(config.NotifyChannel{URL: server.URL, Token: "123:abc", ChatID: "-10042"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+	if req.path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %q", req.path)
+	}
+	var payload struct {
+		ChatID  string `json:"chat_id"`
+		Text    string `json:"text"`
+		Preview bool   `json:"disable_web_page_preview"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if payload.ChatID != "-10042" || payload.Text != plainText(testMessage) || !payload.Preview {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestTelegramSendErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     string
+	}{
+		{"api error", http.StatusOK, `{"ok":false,"description":"Bad Request: chat not found"}`, "chat not found"},
+		{"http error", http.StatusUnauthorized, `{"ok":false,"description":"Unauthorized"}`, "401"},
+		{"invalid json", http.StatusOK, `<html>`, "解析响应失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newServer(t, tt.status, tt.response)
+			channel, err := newTelegramChannel(config.NotifyChannel{URL: server.URL, Token: "t", ChatID: "42"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = channel.Send(context.Background(), testMessage)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Send = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+// webhook 请求头
+const (
+	HeaderEvent     = "X-Bmtdblog-Event"
+	HeaderTimestamp = "X-Bmtdblog-Timestamp"
+	HeaderSignature = "X-Bmtdblog-Signature"
+)
+
+// 响应正文最多读取的字节数，只用于检查结果和记录错误
+const maxResponseSize = 64 << 10
+
+// httpClient 发送通知使用的客户端，超时由每次请求的 ctx 控制
+var httpClient = &http.Client{}
+
+// webhookChannel 向任意地址 POST JSON 的通用 webhook
+type webhookChannel struct {
+	url    string
+	secret string
+}
+
+// webhookPayload 通用 webhook 的请求正文
+type webhookPayload struct {
+	Event string    `json:"event"`
+	Title string    `json:"title"`
+	Text  string    `json:"text"`
+	Link  string    `json:"link,omitempty"`
+	Site  string    `json:"site"`
+	Time  time.Time `json:"time"`
+}
+
+func newWebhookChannel(cfg config.NotifyChannel) (Channel, error) {
+	if err := checkURL(cfg.URL); err != nil {
+		return nil, err
+	}
+	return &webhookChannel{url: cfg.URL, secret: cfg.Secret}, nil
+}
+
+func (channel *webhookChannel) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(&webhookPayload{
+		Event: msg.Event,
+		Title: msg.Title,
+		Text:  msg.Text,
+		Link:  msg.Link,
+		Site:  config.GetConfiguration().Title,
+		Time:  msg.Time,
+	})
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{HeaderEvent: msg.Event}
+	if channel.secret != "" {
+		timestamp := time.Now().Unix()
+		headers[HeaderTimestamp] = strconv.FormatInt(timestamp, 10)
+		headers[HeaderSignature] = Sign(channel.secret, timestamp, body)
+	}
+	_, _, err = PostJSON(ctx, channel.url, body, headers)
+	return err
+}
+
+// Sign webhook 签名：以密钥对「时间戳.请求正文」计算 HMAC-SHA256，
+// 接收方按同样方式计算并比较，同时检查时间戳防止重放
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PostJSON 发送 JSON 请求，返回状态码和响应正文，状态码不是 2xx 时返回错误
+func PostJSON(ctx context.Context, target string, body []byte, headers map[string]string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Bmtdblog-Notifier")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		// 错误信息中不带地址，机器人地址中的令牌不会写入日志
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return res.StatusCode, nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, respBody, fmt.Errorf("返回状态 %s: %s", res.Status, truncate(respBody, 200))
+	}
+	return res.StatusCode, respBody, nil
+}
+
+// checkURL 检查地址是否是 http 或 https 地址
+func checkURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("未配置 url")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("url 有误: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url 必须是 http 或 https 地址: %s", raw)
+	}
+	return nil
+}
+
+// urlHost 地址中的主机名，解析失败时返回空
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// truncate 截断过长的响应正文，用于错误信息
+func truncate(body []byte, size int) string {
+	text := []rune(string(body))
+	if len(text) > size {
+		return string(text[:size]) + "..."
+	}
+	return string(text)
+}
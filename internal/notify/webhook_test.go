@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	got := Sign("secret", 1700000000, body)
+
+	// 接收方的验证方式：对「时间戳.请求正文」计算 HMAC-SHA256
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`1700000000.{"event":"ping"}`))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+	if Sign("other", 1700000000, body) == got {
+		t.Error("signature should depend on the secret")
+	}
+	if Sign("secret", 1700000001, body) == got {
+		t.Error("signature should depend on the timestamp")
+	}
+	if Sign("secret", 1700000000, []byte(`{"event":"pong"}`)) == got {
+		t.Error("signature should depend on the body")
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	server, requests := newServer(t, http.StatusNoContent, "")
+	channel, err := newWebhookChannel(config.NotifyChannel{URL: server.URL + "/hook", Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now().Unix()
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+
+	if req.path != "/hook" {
+		t.Errorf("path = %q", req.path)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := req.header.Get("User-Agent"); got != "Bmtdblog-Notifier" {
+		t.Errorf("User-Agent = %q", got)
+	}
+	if got := req.header.Get(HeaderEvent); got != EventComment {
+		t.Errorf("%s = %q", HeaderEvent, got)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("%s = %q, want current unix time", HeaderTimestamp, req.header.Get(HeaderTimestamp))
+	}
+	if got, want := req.header.Get(HeaderSignature), Sign("s3cret", timestamp, req.body); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := webhookPayload{
+		Event: testMessage.Event,
+		Title: testMessage.Title,
+		Text:  testMessage.Text,
+		Link:  testMessage.Link,
+		Site:  config.GetConfiguration().Title,
+		Time:  testMessage.Time,
+	}
+	if !payload.Time.Equal(want.Time) {
+		t.Errorf("time = %v, want %v", payload.Time, want.Time)
+	}
+	payload.Time = want.Time
+	if payload != want {
+		t.Errorf("payload = %+v, want %+v", payload, want)
+	}
+}
+
+func TestWebhookSendWithoutSecret(t *testing.T) {
+	server, requests := newServer(t, http.StatusOK, "ok")
+	channel, err := newWebhookChannel(config.NotifyChannel{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channel.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	req := received(t, requests)
+	if req.header.Get(HeaderEvent) != EventComment {
+		t.Errorf("%s header missing", HeaderEvent)
+	}
+	for _, header := range []string{HeaderTimestamp, HeaderSignature} {
+		if got := req.header.Get(header); got != "" {
+			t.Errorf("%s = %q, want no header without secret", header, got)
+		}
+	}
+}
+
+func TestPostJSONStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusAccepted, false},
+		{http.StatusMovedPermanently, true},
+		{http.StatusBadRequest, true},
+		{http.StatusInternalServerError, true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server, _ := newServer(t, tt.status, strings.Repeat("错", 300))
+			code, body, err := PostJSON(context.Background(), server.URL, []byte(`{}`), map[string]string{"X-Test": "1"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PostJSON error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if code != tt.status {
+				t.Errorf("status = %d, want %d", code, tt.status)
+			}
+			if len(body) == 0 {
+				t.Error("response body should be returned")
+			}
+			// 错误信息中的响应正文被截断
+			if err != nil && !strings.HasSuffix(err.Error(), "...") {
+				t.Errorf("error message not truncated: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostJSONHidesURL(t *testing.T) {
+	server, _ := newServer(t, http.StatusOK, "")
+	server.Close()
+	target := server.URL + "/robot/send?access_token=secret-token"
+	_, _, err := PostJSON(context.Background(), target, []byte(`{}`), nil)
+	if err == nil {
+		t.Fatal("PostJSON to closed server should fail")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error leaks the url: %v", err)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.com/hook", false},
+		{"http://127.0.0.1:8080", false},
+		{"", true},
+		{"ftp://example.com", true},
+		{"https://", true},
+		{"example.com/hook", true},
+		{"http://[::1", true},
+	}
+	for _, tt := range tests {
+		if err := checkURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("checkURL(%q) = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
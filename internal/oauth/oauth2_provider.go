@@ -0,0 +1,243 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// Gitee 端点
+var giteeEndpoint = oauth2.Endpoint{
+	AuthURL:  "https://gitee.com/oauth/authorize",
+	TokenURL: "https://gitee.com/oauth/token",
+}
+
+const (
+	githubUserInfoURL   = "https://api.github.com/user"
+	githubUserEmailsURL = "https://api.github.com/user/emails"
+	giteeUserInfoURL    = "https://gitee.com/api/v5/user"
+)
+
+// oauth2Provider 不支持 OIDC 的提供方，通过用户信息接口读取账号
+type oauth2Provider struct {
+	name        string
+	displayName string
+	config      *oauth2.Config
+	authParams  []oauth2.AuthCodeOption
+	userInfoURL string
+	identity    func(ctx context.Context, p *oauth2Provider, token *oauth2.Token) (*Identity, error)
+}
+
+func (p *oauth2Provider) Name() string        { return p.name }
+func (p *oauth2Provider) DisplayName() string { return p.displayName }
+
+func (p *oauth2Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	return p.config.AuthCodeURL(state, p.authParams...), nil
+}
+
+func (p *oauth2Provider) Identity(ctx context.Context, code, nonce, verifier string) (*Identity, error) {
+	token, err := p.config.Exchange(httpContext(ctx), code)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange token")
+	}
+	identity, err := p.identity(ctx, p, token)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("missing user id in user info")
+	}
+	identity.Provider = p.name
+	return identity, nil
+}
+
+func newOAuth2Config(pc config.OAuthProvider, endpoint oauth2.Endpoint, scopes ...string) *oauth2.Config {
+	if pc.AuthUrl != "" {
+		endpoint.AuthURL = pc.AuthUrl
+	}
+	if pc.TokenUrl != "" {
+		endpoint.TokenURL = pc.TokenUrl
+	}
+	return &oauth2.Config{
+		ClientID:     pc.ClientId,
+		ClientSecret: pc.ClientSecret,
+		RedirectURL:  pc.RedirectUrl,
+		Endpoint:     endpoint,
+		Scopes:       scopesOrDefault(pc.Scopes, scopes...),
+	}
+}
+
+func newGithubProvider(pc config.OAuthProvider) *oauth2Provider {
+	return &oauth2Provider{
+		name:        pc.Name,
+		displayName: displayNameOrDefault(pc.DisplayName, "GitHub"),
+		config:      newOAuth2Config(pc, github.Endpoint, "read:user", "user:email"),
+		userInfoURL: stringOrDefault(pc.UserInfoUrl, githubUserInfoURL),
+		identity:    githubIdentity,
+	}
+}
+
+// newLegacyGithubProvider 旧版 [github] 配置，authurl 是带 client_id 和 state 占位符的模板，
+// 其中额外的参数（如 prompt）继续附加到授权地址
+func newLegacyGithubProvider(gc config.Github) *oauth2Provider {
+	pc := config.OAuthProvider{
+		Name:         TypeGithub,
+		ClientId:     gc.ClientId,
+		ClientSecret: gc.ClientSecret,
+		RedirectUrl:  gc.RedirectUrl,
+		TokenUrl:     gc.TokenUrl,
+	}
+	if gc.Scope != "" {
+		pc.Scopes = strings.Split(gc.Scope, ",")
+	}
+	var params []oauth2.AuthCodeOption
+	if gc.AuthUrl != "" {
+		if u, err := url.Parse(strings.ReplaceAll(gc.AuthUrl, "%s", "")); err == nil {
+			for key, values := range u.Query() {
+				switch key {
+				case "client_id", "state", "redirect_uri", "response_type":
+				case "scope":
+					if gc.Scope == "" && len(values) > 0 && values[0] != "" {
+						pc.Scopes = strings.Split(values[0], ",")
+					}
+				default:
+					params = append(params, oauth2.SetAuthURLParam(key, values[0]))
+				}
+			}
+			u.RawQuery = ""
+			pc.AuthUrl = u.String()
+		}
+	}
+	provider := newGithubProvider(pc)
+	provider.authParams = params
+	return provider
+}
+
+func newGiteeProvider(pc config.OAuthProvider) *oauth2Provider {
+	return &oauth2Provider{
+		name:        pc.Name,
+		displayName: displayNameOrDefault(pc.DisplayName, "Gitee"),
+		config:      newOAuth2Config(pc, giteeEndpoint, "user_info"),
+		userInfoURL: stringOrDefault(pc.UserInfoUrl, giteeUserInfoURL),
+		identity:    giteeIdentity,
+	}
+}
+
+// githubIdentity 读取 GitHub 账号，邮箱取已验证的主邮箱
+func githubIdentity(ctx context.Context, p *oauth2Provider, token *oauth2.Token) (*Identity, error) {
+	var info struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+		HTMLURL   string `json:"html_url"`
+	}
+	if err := getJSON(ctx, p.userInfoURL, token.AccessToken, &info); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Login:      info.Login,
+		Name:       info.Name,
+		Email:      info.Email,
+		AvatarURL:  info.AvatarURL,
+		ProfileURL: info.HTMLURL,
+	}
+	if info.ID > 0 {
+		identity.Subject = strconv.FormatInt(info.ID, 10)
+	}
+	// 只有 api.github.com 提供邮箱列表接口
+	if p.userInfoURL == githubUserInfoURL {
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		if err := getJSON(ctx, githubUserEmailsURL, token.AccessToken, &emails); err == nil {
+			for _, email := range emails {
+				if email.Primary && email.Verified {
+					identity.Email = email.Email
+					identity.EmailVerified = true
+				}
+			}
+		}
+	}
+	return identity, nil
+}
+
+// giteeIdentity 读取 Gitee 账号，公开邮箱未经验证
+// Gitee 的 v5 接口通过 access_token 参数传递令牌
+func giteeIdentity(ctx context.Context, p *oauth2Provider, token *oauth2.Token) (*Identity, error) {
+	var info struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+		HTMLURL   string `json:"html_url"`
+	}
+	userInfoURL, err := url.Parse(p.userInfoURL)
+	if err != nil {
+		return nil, err
+	}
+	query := userInfoURL.Query()
+	query.Set("access_token", token.AccessToken)
+	userInfoURL.RawQuery = query.Encode()
+	if err := getJSON(ctx, userInfoURL.String(), "", &info); err != nil {
+		return nil, err
+	}
+	identity := &Identity{
+		Login:      info.Login,
+		Name:       info.Name,
+		Email:      info.Email,
+		AvatarURL:  info.AvatarURL,
+		ProfileURL: info.HTMLURL,
+	}
+	if info.ID > 0 {
+		identity.Subject = strconv.FormatInt(info.ID, 10)
+	}
+	return identity, nil
+}
+
+// getJSON 携带访问令牌请求 JSON 接口
+func getJSON(ctx context.Context, rawURL, accessToken string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// 不输出查询参数，避免令牌写入日志
+		return fmt.Errorf("GET %s%s: %s", req.URL.Host, req.URL.Path, resp.Status)
+	}
+	return json.Unmarshal(body, dest)
+}
+
+func stringOrDefault(value, def string) string {
+	if value != "" {
+		return value
+	}
+	return def
+}
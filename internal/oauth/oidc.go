@@ -0,0 +1,444 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"golang.org/x/oauth2"
+)
+
+const (
+	googleIssuer = "https://accounts.google.com"
+
+	// 发现文档和公钥的缓存时间
+	oidcDiscoveryTTL = time.Hour
+	// 遇到未知 kid 时重新获取公钥的最小间隔
+	oidcKeysRefreshInterval = time.Minute
+	// 校验 exp/iat 时允许的时钟偏差
+	oidcClockSkew = time.Minute
+)
+
+// oidcDiscovery /.well-known/openid-configuration 中用到的字段
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider 通过发现协议获取端点，用签发者公钥校验 ID Token
+type oidcProvider struct {
+	name        string
+	displayName string
+	issuer      string
+	config      oauth2.Config
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func newOIDCProvider(pc config.OAuthProvider) *oidcProvider {
+	return &oidcProvider{
+		name:        pc.Name,
+		displayName: displayNameOrDefault(pc.DisplayName, pc.Name),
+		issuer:      strings.TrimRight(pc.Issuer, "/"),
+		config: oauth2.Config{
+			ClientID:     pc.ClientId,
+			ClientSecret: pc.ClientSecret,
+			RedirectURL:  pc.RedirectUrl,
+			Scopes:       scopesOrDefault(pc.Scopes, "openid", "profile", "email"),
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  pc.AuthUrl,
+				TokenURL: pc.TokenUrl,
+			},
+		},
+	}
+}
+
+func (p *oidcProvider) Name() string        { return p.name }
+func (p *oidcProvider) DisplayName() string { return p.displayName }
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	cfg, _, err := p.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *oidcProvider) Identity(ctx context.Context, code, nonce, verifier string) (*Identity, error) {
+	cfg, discovery, err := p.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := cfg.Exchange(httpContext(ctx), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange token")
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("missing id_token in token response")
+	}
+	claims, err := p.verifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, errors.Wrap(err, "verify id_token")
+	}
+	identity := claims.identity()
+
+	// ID Token 中缺少资料时从用户信息接口补全，sub 必须一致
+	if discovery.UserinfoEndpoint != "" && (identity.Email == "" || identity.Name == "") {
+		var info oidcClaims
+		if err := getJSON(ctx, discovery.UserinfoEndpoint, token.AccessToken, &info); err == nil && info.Subject == claims.Subject {
+			extra := info.identity()
+			if identity.Email == "" {
+				identity.Email, identity.EmailVerified = extra.Email, extra.EmailVerified
+			}
+			identity.Login = stringOrDefault(identity.Login, extra.Login)
+			identity.Name = stringOrDefault(identity.Name, extra.Name)
+			identity.AvatarURL = stringOrDefault(identity.AvatarURL, extra.AvatarURL)
+			identity.ProfileURL = stringOrDefault(identity.ProfileURL, extra.ProfileURL)
+		}
+	}
+	identity.Provider = p.name
+	return identity, nil
+}
+
+// oauth2Config 合并发现到的端点，配置中显式指定的端点优先
+func (p *oidcProvider) oauth2Config(ctx context.Context) (*oauth2.Config, *oidcDiscovery, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := p.config
+	cfg.Endpoint.AuthURL = stringOrDefault(cfg.Endpoint.AuthURL, discovery.AuthorizationEndpoint)
+	cfg.Endpoint.TokenURL = stringOrDefault(cfg.Endpoint.TokenURL, discovery.TokenEndpoint)
+	return &cfg, discovery, nil
+}
+
+// discover 获取并缓存发现文档
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.issuer+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, errors.Wrap(err, "oidc discovery")
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != p.issuer {
+		return nil, errors.Errorf("oidc discovery: issuer %q does not match %q", discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, errors.New("oidc discovery: missing endpoints")
+	}
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// publicKey 按 kid 查找签名公钥，未知 kid 时重新获取公钥（签发者可能已轮换密钥）
+func (p *oidcProvider) publicKey(ctx context.Context, discovery *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lookup := func() crypto.PublicKey {
+		if kid == "" && len(p.keys) == 1 {
+			for _, key := range p.keys {
+				return key
+			}
+		}
+		return p.keys[kid]
+	}
+	cached := lookup()
+	if cached != nil && time.Since(p.keysFetchedAt) < oidcDiscoveryTTL {
+		return cached, nil
+	}
+	if cached == nil && time.Since(p.keysFetchedAt) < oidcKeysRefreshInterval {
+		return nil, errors.Errorf("unknown signing key %q", kid)
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, discovery.JwksURI, "", &jwks); err != nil {
+		// 获取失败时继续使用已缓存的公钥
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, errors.Wrap(err, "fetch jwks")
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	return nil, errors.Errorf("unknown signing key %q", kid)
+}
+
+// verifyIDToken 校验 ID Token 的签名、签发者、受众、有效期和 nonce
+func (p *oidcProvider) verifyIDToken(ctx context.Context, raw, nonce string) (*oidcClaims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "decode signature")
+	}
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.publicKey(ctx, discovery, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims oidcClaims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	switch {
+	case !p.issuerMatches(claims.Issuer):
+		return nil, errors.Errorf("unexpected issuer %q", claims.Issuer)
+	case !claims.Audience.contains(p.config.ClientID):
+		return nil, errors.New("token was not issued for this client")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID:
+		return nil, errors.New("unexpected authorized party")
+	case claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(oidcClockSkew)):
+		return nil, errors.New("token expired")
+	case claims.IssuedAt > 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(oidcClockSkew)):
+		return nil, errors.New("token issued in the future")
+	case claims.Nonce != nonce:
+		return nil, errors.New("nonce mismatch")
+	case claims.Subject == "":
+		return nil, errors.New("missing subject")
+	}
+	return &claims, nil
+}
+
+// issuerMatches Google 签发的令牌中 iss 可能不带 https:// 前缀
+func (p *oidcProvider) issuerMatches(iss string) bool {
+	iss = strings.TrimRight(iss, "/")
+	return iss == p.issuer || (p.issuer == googleIssuer && "https://"+iss == googleIssuer)
+}
+
+// oidcClaims ID Token 和用户信息接口中用到的声明
+type oidcClaims struct {
+	Issuer            string    `json:"iss"`
+	Subject           string    `json:"sub"`
+	Audience          audience  `json:"aud"`
+	AuthorizedParty   string    `json:"azp"`
+	Expiry            int64     `json:"exp"`
+	IssuedAt          int64     `json:"iat"`
+	Nonce             string    `json:"nonce"`
+	Email             string    `json:"email"`
+	EmailVerified     looseBool `json:"email_verified"`
+	Name              string    `json:"name"`
+	PreferredUsername string    `json:"preferred_username"`
+	Picture           string    `json:"picture"`
+	Profile           string    `json:"profile"`
+}
+
+func (claims *oidcClaims) identity() *Identity {
+	return &Identity{
+		Subject:       claims.Subject,
+		Login:         claims.PreferredUsername,
+		Name:          claims.Name,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		AvatarURL:     claims.Picture,
+		ProfileURL:    claims.Profile,
+	}
+}
+
+// audience aud 可以是字符串或字符串数组
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, item := range a {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// looseBool 部分签发者将 email_verified 输出为字符串
+type looseBool bool
+
+func (b *looseBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// jsonWebKey JWKS 中的公钥，支持 RSA 和 EC
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid ec point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+// verifySignature 校验 JWS 签名，只接受非对称算法
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("signing key type mismatch")
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	default:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("signing key type mismatch")
+		}
+		bits := ecKey.Curve.Params().BitSize
+		if expected := map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}[alg]; bits != expected {
+			return errors.New("signing key curve mismatch")
+		}
+		size := (bits + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+}
+
+func decodeSegment(segment string, dest interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.Wrap(err, "decode token")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,451 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+)
+
+const (
+	testClientID = "client-id"
+	testNonce    = "nonce-1"
+)
+
+// mockIssuer 模拟 OIDC 签发者，提供发现文档和 JWKS
+type mockIssuer struct {
+	*httptest.Server
+	mu            sync.Mutex
+	issuer        string // 发现文档中的 issuer，为空时使用服务地址
+	keys          []jsonWebKey
+	discoveryHits atomic.Int32
+	jwksHits      atomic.Int32
+}
+
+func newMockIssuer(t *testing.T, keys ...jsonWebKey) *mockIssuer {
+	m := &mockIssuer{keys: keys}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		m.discoveryHits.Add(1)
+		issuer := m.URL
+		if m.issuer != "" {
+			issuer = m.issuer
+		}
+		writeJSON(w, map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"userinfo_endpoint":      m.URL + "/userinfo",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.jwksHits.Add(1)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		writeJSON(w, map[string]interface{}{"keys": m.keys})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockIssuer) setKeys(keys ...jsonWebKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = keys
+}
+
+func (m *mockIssuer) provider() *oidcProvider {
+	return newOIDCProvider(config.OAuthProvider{
+		Name:     "test",
+		Type:     "oidc",
+		ClientId: testClientID,
+		Issuer:   m.URL + "/",
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   b64(key.N.Bytes()),
+		E:   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jsonWebKey {
+	size := (key.Curve.Params().BitSize + 7) / 8
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: key.Curve.Params().Name,
+		X:   b64(key.X.FillBytes(make([]byte, size))),
+		Y:   b64(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func mustRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signToken 生成 JWS，key 为 *rsa.PrivateKey、*ecdsa.PrivateKey 或 HS256 的密钥，alg 为 none 时不签名
+func signToken(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+	var signature []byte
+	switch alg {
+	case "RS256":
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case "none":
+	default:
+		t.Fatalf("unsupported alg %s", alg)
+	}
+	return input + "." + b64(signature)
+}
+
+func validClaims(issuer string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":   issuer,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": testNonce,
+		"email": "user@example.com",
+	}
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+	ctx := context.Background()
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if discovery.TokenEndpoint != m.URL+"/token" || discovery.JwksURI != m.URL+"/jwks" {
+		t.Errorf("unexpected discovery %+v", discovery)
+	}
+	// 发现文档在有效期内使用缓存
+	if _, err = p.discover(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if hits := m.discoveryHits.Load(); hits != 1 {
+		t.Errorf("discovery fetched %d times, want 1", hits)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", testNonce, "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if !strings.HasPrefix(authURL, m.URL+"/authorize?") || query.Get("nonce") != testNonce ||
+		query.Get("state") != "state-1" || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("unexpected auth url %s", authURL)
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	m.issuer = "https://evil.example.com"
+	if _, err := m.provider().discover(context.Background()); err == nil {
+		t.Fatal("expected issuer mismatch error")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	rsaKey := mustRSAKey(t)
+	ecKey := mustECKey(t, elliptic.P256())
+	m := newMockIssuer(t, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		claims := validClaims(m.URL)
+		for k, v := range changes {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		return claims
+	}
+	now := time.Now()
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"rs256", signToken(t, "RS256", "rsa-1", rsaKey, with(nil)), ""},
+		{"es256", signToken(t, "ES256", "ec-1", ecKey, with(nil)), ""},
+		{"aud list with azp", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{
+			"aud": []string{testClientID, "other"}, "azp": testClientID,
+		})), ""},
+		{"nonce mismatch", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"nonce": "other"})), "nonce mismatch"},
+		{"missing nonce", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"nonce": nil})), "nonce mismatch"},
+		{"wrong aud", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"aud": "other"})), "not issued for this client"},
+		{"aud list without azp", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{
+			"aud": []string{testClientID, "other"},
+		})), "unexpected authorized party"},
+		{"wrong azp", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{
+			"aud": []string{testClientID, "other"}, "azp": "other",
+		})), "unexpected authorized party"},
+		{"expired", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{
+			"exp": now.Add(-2 * oidcClockSkew).Unix(),
+		})), "token expired"},
+		{"missing exp", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"exp": nil})), "token expired"},
+		{"issued in the future", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{
+			"iat": now.Add(2 * oidcClockSkew).Unix(),
+		})), "issued in the future"},
+		{"wrong issuer", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"iss": "https://evil.example.com"})), "unexpected issuer"},
+		{"missing subject", signToken(t, "RS256", "rsa-1", rsaKey, with(map[string]interface{}{"sub": nil})), "missing subject"},
+		{"alg none", signToken(t, "none", "rsa-1", nil, with(nil)), "unsupported signing algorithm"},
+		// 用公钥作为 HMAC 密钥伪造签名
+		{"hs256 with public key", signToken(t, "HS256", "rsa-1", rsaKey.N.Bytes(), with(nil)), "unsupported signing algorithm"},
+		{"es256 header with rsa kid", signToken(t, "ES256", "rsa-1", ecKey, with(nil)), "signing key type mismatch"},
+		{"signed by other key", signToken(t, "RS256", "rsa-1", mustRSAKey(t), with(nil)), "verification error"},
+		{"malformed", "a.b", "malformed token"},
+	}
+
+	p := m.provider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := p.verifyIDToken(context.Background(), tt.token, testNonce)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims.Subject != "user-1" || claims.Email != "user@example.com" {
+					t.Errorf("unexpected claims %+v", claims)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenTamperedPayload(t *testing.T) {
+	key := mustRSAKey(t)
+	m := newMockIssuer(t, rsaJWK("rsa-1", key))
+	token := signToken(t, "RS256", "rsa-1", key, validClaims(m.URL))
+	claims := validClaims(m.URL)
+	claims["sub"] = "admin"
+	payload, _ := json.Marshal(claims)
+	parts := strings.Split(token, ".")
+	parts[1] = b64(payload)
+	if _, err := m.provider().verifyIDToken(context.Background(), strings.Join(parts, "."), testNonce); err == nil {
+		t.Fatal("expected signature error for tampered payload")
+	}
+}
+
+func TestOIDCUnknownKidRefreshesKeys(t *testing.T) {
+	oldKey, newKey := mustRSAKey(t), mustRSAKey(t)
+	m := newMockIssuer(t, rsaJWK("old", oldKey))
+	p := m.provider()
+	ctx := context.Background()
+
+	if _, err := p.verifyIDToken(ctx, signToken(t, "RS256", "old", oldKey, validClaims(m.URL)), testNonce); err != nil {
+		t.Fatalf("verify with old key: %v", err)
+	}
+	if hits := m.jwksHits.Load(); hits != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", hits)
+	}
+
+	// 签发者轮换密钥
+	m.setKeys(rsaJWK("old", oldKey), rsaJWK("new", newKey))
+	token := signToken(t, "RS256", "new", newKey, validClaims(m.URL))
+
+	// 距上次获取不足最小间隔时不重新获取
+	if _, err := p.verifyIDToken(ctx, token, testNonce); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("error = %v, want unknown signing key", err)
+	}
+	if hits := m.jwksHits.Load(); hits != 1 {
+		t.Fatalf("jwks fetched %d times within refresh interval, want 1", hits)
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-2 * oidcKeysRefreshInterval)
+	p.mu.Unlock()
+	if _, err := p.verifyIDToken(ctx, token, testNonce); err != nil {
+		t.Fatalf("verify with rotated key: %v", err)
+	}
+	if hits := m.jwksHits.Load(); hits != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", hits)
+	}
+	// 刷新后旧公钥仍在 JWKS 中，继续使用缓存
+	if _, err := p.verifyIDToken(ctx, signToken(t, "RS256", "old", oldKey, validClaims(m.URL)), testNonce); err != nil {
+		t.Fatalf("verify with old key after refresh: %v", err)
+	}
+	if hits := m.jwksHits.Load(); hits != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", hits)
+	}
+}
+
+func TestJSONWebKeyPublicKey(t *testing.T) {
+	rsaKey := mustRSAKey(t)
+	ecKey := mustECKey(t, elliptic.P256())
+	offCurve := ecJWK("ec", ecKey)
+	offCurve.Y = b64(new(big.Int).Add(ecKey.Y, big.NewInt(1)).Bytes())
+	smallExponent := rsaJWK("rsa", rsaKey)
+	smallExponent.E = b64([]byte{1})
+
+	tests := []struct {
+		name    string
+		jwk     jsonWebKey
+		wantErr bool
+	}{
+		{"rsa", rsaJWK("rsa", rsaKey), false},
+		{"ec p-256", ecJWK("ec", ecKey), false},
+		{"ec p-384", ecJWK("ec", mustECKey(t, elliptic.P384())), false},
+		{"ec point not on curve", offCurve, true},
+		{"rsa small exponent", smallExponent, true},
+		{"rsa missing modulus", jsonWebKey{Kty: "RSA", E: "AQAB"}, true},
+		{"unsupported curve", jsonWebKey{Kty: "EC", Crv: "secp256k1", X: "AQ", Y: "AQ"}, true},
+		{"symmetric key", jsonWebKey{Kty: "oct"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.jwk.publicKey()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got key %T", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	rsaJSON, ecJSON := rsaJWK("rsa", rsaKey), ecJWK("ec", ecKey)
+	key, err := rsaJSON.publicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rsaKey.PublicKey.Equal(key) {
+		t.Error("decoded rsa key does not match")
+	}
+	key, err = ecJSON.publicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecKey.PublicKey.Equal(key) {
+		t.Error("decoded ec key does not match")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	rsaKey := mustRSAKey(t)
+	ecKey := mustECKey(t, elliptic.P256())
+	p384Key := mustECKey(t, elliptic.P384())
+	input := "header.payload"
+	digest := sha256.Sum256([]byte(input))
+
+	rsaSig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecSig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	mac := hmac.New(sha256.New, rsaKey.N.Bytes())
+	mac.Write([]byte(input))
+
+	tests := []struct {
+		name      string
+		alg       string
+		key       crypto.PublicKey
+		signature []byte
+		wantErr   bool
+	}{
+		{"rs256", "RS256", &rsaKey.PublicKey, rsaSig, false},
+		{"es256", "ES256", &ecKey.PublicKey, ecSig, false},
+		{"rs256 bad signature", "RS256", &rsaKey.PublicKey, append([]byte{}, rsaSig[1:]...), true},
+		{"es256 bad signature", "ES256", &ecKey.PublicKey, append(append([]byte{}, ecSig[32:]...), ecSig[:32]...), true},
+		{"es256 short signature", "ES256", &ecKey.PublicKey, ecSig[:63], true},
+		{"es256 with rsa key", "ES256", &rsaKey.PublicKey, ecSig, true},
+		{"rs256 with ec key", "RS256", &ecKey.PublicKey, rsaSig, true},
+		{"es256 with p-384 key", "ES256", &p384Key.PublicKey, ecSig, true},
+		{"none", "none", &rsaKey.PublicKey, nil, true},
+		{"empty alg", "", &rsaKey.PublicKey, nil, true},
+		{"hs256", "HS256", &rsaKey.PublicKey, mac.Sum(nil), true},
+		{"ps256", "PS256", &rsaKey.PublicKey, rsaSig, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(tt.alg, tt.key, input, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package oauth 第三方登录提供方：GitHub、Gitee 以及支持发现协议的 OIDC 签发者（含 Google）
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// 提供方类型
+const (
+	TypeGithub = "github"
+	TypeGitee  = "gitee"
+	TypeGoogle = "google"
+	TypeOIDC   = "oidc"
+)
+
+// Identity 第三方账号信息
+type Identity struct {
+	Provider      string // 配置中的提供方名称
+	Subject       string // 提供方内唯一且不变的用户ID
+	Login         string // 用户名
+	Name          string // 昵称
+	Email         string
+	EmailVerified bool // 提供方是否确认邮箱属于该用户
+	AvatarURL     string
+	ProfileURL    string
+}
+
+// Provider 第三方登录提供方
+type Provider interface {
+	// Name 配置中的唯一标识
+	Name() string
+	// DisplayName 登录按钮上显示的名称
+	DisplayName() string
+	// AuthCodeURL 生成授权地址，nonce 和 verifier 分别用于 OIDC 的重放保护和 PKCE
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Identity 用授权码换取令牌并读取账号信息
+	Identity(ctx context.Context, code, nonce, verifier string) (*Identity, error)
+}
+
+// ProviderInfo 模板中使用的提供方信息
+type ProviderInfo struct {
+	Name        string
+	DisplayName string
+}
+
+// HTTPClient 访问第三方接口使用的客户端
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+var (
+	providers   []Provider
+	providersMu sync.RWMutex
+	loaded      bool
+)
+
+// Load 根据配置创建提供方，配置有误的提供方记录日志后跳过
+func Load(cfg *config.Configuration) {
+	list := make([]Provider, 0, len(cfg.OAuth)+1)
+	names := make(map[string]bool)
+	for _, pc := range cfg.OAuth {
+		if !pc.Enabled {
+			continue
+		}
+		provider, err := newProvider(pc, cfg.Domain)
+		if err != nil {
+			log.Error("Invalid oauth provider", "name", pc.Name, "type", pc.Type, "err", err)
+			continue
+		}
+		if names[provider.Name()] {
+			log.Error("Duplicate oauth provider", "name", provider.Name())
+			continue
+		}
+		names[provider.Name()] = true
+		list = append(list, provider)
+	}
+	// 兼容旧版 [github] 配置
+	if cfg.Github.Enabled && !names[TypeGithub] {
+		list = append(list, newLegacyGithubProvider(cfg.Github))
+	}
+
+	providersMu.Lock()
+	providers = list
+	loaded = true
+	providersMu.Unlock()
+}
+
+func allProviders() []Provider {
+	providersMu.RLock()
+	if loaded {
+		defer providersMu.RUnlock()
+		return providers
+	}
+	providersMu.RUnlock()
+	Load(config.GetConfiguration())
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return providers
+}
+
+// Get 按名称查找已启用的提供方
+func Get(name string) (Provider, bool) {
+	for _, provider := range allProviders() {
+		if provider.Name() == name {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// EnabledProviders 已启用的提供方，用于渲染登录按钮
+func EnabledProviders() []ProviderInfo {
+	list := allProviders()
+	infos := make([]ProviderInfo, 0, len(list))
+	for _, provider := range list {
+		infos = append(infos, ProviderInfo{Name: provider.Name(), DisplayName: provider.DisplayName()})
+	}
+	return infos
+}
+
+func newProvider(pc config.OAuthProvider, domain string) (Provider, error) {
+	if pc.Name == "" {
+		pc.Name = pc.Type
+	}
+	if pc.ClientId == "" {
+		return nil, errors.New("client_id is required")
+	}
+	if pc.RedirectUrl == "" {
+		pc.RedirectUrl = strings.TrimRight(domain, "/") + "/oauth2callback/" + pc.Name
+	}
+	switch pc.Type {
+	case TypeGithub:
+		return newGithubProvider(pc), nil
+	case TypeGitee:
+		return newGiteeProvider(pc), nil
+	case TypeGoogle:
+		if pc.Issuer == "" {
+			pc.Issuer = googleIssuer
+		}
+		if pc.DisplayName == "" {
+			pc.DisplayName = "Google"
+		}
+		return newOIDCProvider(pc), nil
+	case TypeOIDC:
+		if pc.Issuer == "" {
+			return nil, errors.New("issuer is required")
+		}
+		return newOIDCProvider(pc), nil
+	default:
+		return nil, fmt.Errorf("unknown provider type %q", pc.Type)
+	}
+}
+
+// httpContext 让 oauth2 库使用 HTTPClient 发起请求
+func httpContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, HTTPClient)
+}
+
+func scopesOrDefault(scopes []string, def ...string) []string {
+	if len(scopes) > 0 {
+		return scopes
+	}
+	return def
+}
+
+func displayNameOrDefault(name, def string) string {
+	if name != "" {
+		return name
+	}
+	return def
+}
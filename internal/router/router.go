@@ -12,13 +12,17 @@ import (
 	"github.com/xiuivfbc/bmtdblog/internal/api/content"
 	"github.com/xiuivfbc/bmtdblog/internal/api/email"
 	"github.com/xiuivfbc/bmtdblog/internal/api/link"
+	"github.com/xiuivfbc/bmtdblog/internal/api/notification"
 	"github.com/xiuivfbc/bmtdblog/internal/api/queue"
+	"github.com/xiuivfbc/bmtdblog/internal/api/security"
 	"github.com/xiuivfbc/bmtdblog/internal/api/subscribe"
 	"github.com/xiuivfbc/bmtdblog/internal/api/upload"
 	"github.com/xiuivfbc/bmtdblog/internal/api/user"
+	"github.com/xiuivfbc/bmtdblog/internal/api/webhook"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/middleware" // 导入新的中间件包
+	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
 func DefineRouter() *gin.Engine {
@@ -30,8 +34,10 @@ func DefineRouter() *gin.Engine {
 	// 中间件配置
 	// ------------------------------
 	router.Use(middleware.TraceMiddleware()) // 全链路追踪中间件（最先执行）
+	router.Use(middleware.SecurityHeaders()) // 安全响应头中间件
 	setSessions(router)                      // 会话配置
 	router.Use(middleware.SharedData())      // 共享数据中间件
+	router.Use(middleware.CSRF())            // CSRF 校验中间件
 
 	// ------------------------------
 	// 模板配置
@@ -68,16 +74,26 @@ func DefineRouter() *gin.Engine {
 	}
 	router.GET("/signin", user.SigninGet)
 	router.POST("/signin", user.SigninPost)
+	router.GET("/signin/2fa", user.SigninTwoFactorGet)   // 两步验证
+	router.POST("/signin/2fa", user.SigninTwoFactorPost) // 提交两步验证码
 	router.GET("/logout", user.LogoutGet)
 	router.GET("/oauth2callback", user.Oauth2Callback)
+	router.GET("/oauth2callback/:provider", user.Oauth2Callback)
 	router.GET("/auth/:authType", user.AuthGet)
-	router.GET("/captcha", user.CaptchaGet) // 验证码
+	router.GET("/captcha", user.CaptchaGet)                  // 验证码
+	router.GET("/password/forgot", user.PasswordForgotGet)   // 忘记密码
+	router.POST("/password/forgot", user.PasswordForgotPost) // 发送重置密码链接
+	router.GET("/password/reset", user.PasswordResetGet)     // 重置密码页面
+	router.POST("/password/reset", user.PasswordResetPost)   // 提交新密码
+	router.GET("/email/verify", user.EmailVerify)            // 验证邮箱
 
 	// ------------------------------
 	// 内容浏览路由
 	// ------------------------------
-	router.GET("/page/:id", content.PageGet)                 // 单页
-	router.GET("/post/:id", content.PostGet)                 // 文章详情
+	router.GET("/page/:id", content.PageGet)                 // 单页（旧地址，301 跳转）
+	router.GET("/pages/:slug", content.PageSlugGet)          // 单页
+	router.GET("/post/:id", content.PostGet)                 // 文章详情（旧地址，301 跳转）
+	router.GET("/posts/:slug", content.PostSlugGet)          // 文章详情
 	router.GET("/tag/:tag", content.TagGet)                  // 标签页
 	router.GET("/archives/:year/:month", content.ArchiveGet) // 归档页
 	router.GET("/link/:id", link.LinkGet)                    // 友情链接详情
@@ -109,76 +125,192 @@ func DefineRouter() *gin.Engine {
 	router.GET("/active", subscribe.ActiveSubscriber) // 激活订阅者（暂未使用）
 	router.GET("/unsubscribe", subscribe.UnSubscribe) // 取消订阅
 
+	// CSP 违规报告，由浏览器自动上报
+	router.POST(middleware.CSPReportPath, security.CSPReportPost)
+
 	// ------------------------------
-	// 管理后台路由（需要管理员权限）
+	// 内容管理 JSON API（需要后台权限，按角色细分）
+	// ------------------------------
+	apiV1 := router.Group("/api/v1")
+	apiV1.Use(middleware.APIAuthRequired(true), middleware.TwoFactorEnrolled(), middleware.TokenScopeRequired(models.TokenScopeRead, models.TokenScopeContentWrite))
+	// 博文：作者和投稿者只能操作自己的博文，由接口内部校验
+	apiPosts := apiV1.Group("", middleware.APIPermissionRequired(models.PermWritePost))
+	{
+		apiPosts.GET("/posts", content.PostListAPI)          // 博文列表
+		apiPosts.POST("/posts", content.PostCreateAPI)       // 创建博文
+		apiPosts.GET("/posts/:id", content.PostGetAPI)       // 博文详情
+		apiPosts.PUT("/posts/:id", content.PostUpdateAPI)    // 修改博文
+		apiPosts.DELETE("/posts/:id", content.PostDeleteAPI) // 删除博文
+
+		apiPosts.GET("/tags", content.TagListAPI)    // 标签列表
+		apiPosts.POST("/tags", content.TagCreateAPI) // 创建标签
+	}
+	apiContent := apiV1.Group("", middleware.APIPermissionRequired(models.PermManageContent))
+	{
+		apiContent.GET("/pages", content.PageListAPI)          // 页面列表
+		apiContent.POST("/pages", content.PageCreateAPI)       // 创建页面
+		apiContent.GET("/pages/:id", content.PageGetAPI)       // 页面详情
+		apiContent.PUT("/pages/:id", content.PageUpdateAPI)    // 修改页面
+		apiContent.DELETE("/pages/:id", content.PageDeleteAPI) // 删除页面
+
+		apiContent.PUT("/tags/:id", content.TagUpdateAPI)    // 修改标签
+		apiContent.DELETE("/tags/:id", content.TagDeleteAPI) // 删除标签
+
+		apiContent.GET("/links", link.LinkListAPI)          // 友情链接列表
+		apiContent.POST("/links", link.LinkCreateAPI)       // 创建友情链接
+		apiContent.GET("/links/:id", link.LinkGetAPI)       // 友情链接详情
+		apiContent.PUT("/links/:id", link.LinkUpdateAPI)    // 修改友情链接
+		apiContent.DELETE("/links/:id", link.LinkDeleteAPI) // 删除友情链接
+
+		apiContent.GET("/comments", comment.CommentListAPI)          // 评论列表
+		apiContent.POST("/comments", comment.CommentCreateAPI)       // 发表评论
+		apiContent.GET("/comments/:id", comment.CommentGetAPI)       // 评论详情
+		apiContent.PUT("/comments/:id", comment.CommentUpdateAPI)    // 审核评论
+		apiContent.DELETE("/comments/:id", comment.CommentDeleteAPI) // 删除评论
+
+		apiContent.GET("/subscribers", subscribe.SubscriberListAPI)          // 订阅者列表
+		apiContent.POST("/subscribers", subscribe.SubscriberCreateAPI)       // 添加订阅者
+		apiContent.GET("/subscribers/:id", subscribe.SubscriberGetAPI)       // 订阅者详情
+		apiContent.PUT("/subscribers/:id", subscribe.SubscriberUpdateAPI)    // 修改订阅状态
+		apiContent.DELETE("/subscribers/:id", subscribe.SubscriberDeleteAPI) // 删除订阅者
+	}
+
+	// ------------------------------
+	// 管理后台路由（需要后台权限，按角色细分）
 	// ------------------------------
 	admin := router.Group("/admin")
-	admin.Use(middleware.AuthRequired(true))
+	admin.Use(middleware.AuthRequired(true), middleware.TwoFactorEnrolled())
+	// 内容管理：访问令牌读操作需要 read 权限，写操作需要 content:write 权限
+	contentAdmin := admin.Group("", middleware.TokenScopeRequired(models.TokenScopeRead, models.TokenScopeContentWrite))
 	{
 		// 管理后台首页
-		admin.GET("/index", content.AdminIndex)
-
-		// 内容管理
-		admin.GET("/page", content.PageIndex)                // 页面列表
-		admin.GET("/new_page", content.PageNew)              // 创建页面
-		admin.POST("/new_page", content.PageCreate)          // 提交页面
-		admin.GET("/page/:id/edit", content.PageEdit)        // 编辑页面
-		admin.POST("/page/:id/edit", content.PageUpdate)     // 更新页面
-		admin.POST("/page/:id/publish", content.PagePublish) // 发布页面
-		admin.POST("/page/:id/delete", content.PageDelete)   // 删除页面
-
-		admin.GET("/post", content.PostIndex)                // 文章列表
-		admin.GET("/new_post", content.PostNew)              // 创建文章
-		admin.POST("/new_post", content.PostCreate)          // 提交文章
-		admin.GET("/post/:id/edit", content.PostEdit)        // 编辑文章
-		admin.POST("/post/:id/edit", content.PostUpdate)     // 更新文章
-		admin.POST("/post/:id/publish", content.PostPublish) // 发布文章
-		admin.POST("/post/:id/delete", content.PostDelete)   // 删除文章
-
-		admin.POST("/new_tag", content.TagCreate) // 创建标签
+		contentAdmin.GET("/index", content.AdminIndex)
+	}
+	// 博文：作者和投稿者只能操作自己的博文，由处理函数内部校验
+	postAdmin := contentAdmin.Group("", middleware.PermissionRequired(models.PermWritePost))
+	{
+		postAdmin.GET("/post", content.PostIndex)                // 文章列表
+		postAdmin.GET("/new_post", content.PostNew)              // 创建文章
+		postAdmin.POST("/new_post", content.PostCreate)          // 提交文章
+		postAdmin.GET("/post/:id/edit", content.PostEdit)        // 编辑文章
+		postAdmin.POST("/post/:id/edit", content.PostUpdate)     // 更新文章
+		postAdmin.POST("/post/:id/publish", content.PostPublish) // 发布文章
+		postAdmin.POST("/post/:id/delete", content.PostDelete)   // 删除文章
 
-		// 用户管理
-		admin.GET("/user", user.UserIndex)          // 用户列表
-		admin.POST("/user/:id/lock", user.UserLock) // 锁定/解锁用户
+		postAdmin.GET("/post/:id/revisions", content.PostRevisionIndex)                 // 文章修订历史
+		postAdmin.POST("/post/:id/revisions/:rid/restore", content.PostRevisionRestore) // 恢复到指定修订
+
+		postAdmin.POST("/new_tag", content.TagCreate) // 创建标签
 
-		// 个人资料管理
-		admin.GET("/profile", user.ProfileGet)                  // 个人资料页面
-		admin.POST("/profile", user.ProfileUpdate)              // 更新个人资料
-		admin.POST("/profile/email/bind", user.BindEmail)       // 绑定邮箱
-		admin.POST("/profile/email/unbind", user.UnbindEmail)   // 解绑邮箱
-		admin.POST("/profile/github/unbind", user.UnbindGithub) // 解绑Github
+		// 上传管理
+		postAdmin.POST("/upload", upload.Upload) // 上传文件
+	}
+	manageAdmin := contentAdmin.Group("", middleware.PermissionRequired(models.PermManageContent))
+	{
+		// 页面管理
+		manageAdmin.GET("/page", content.PageIndex)                // 页面列表
+		manageAdmin.GET("/new_page", content.PageNew)              // 创建页面
+		manageAdmin.POST("/new_page", content.PageCreate)          // 提交页面
+		manageAdmin.GET("/page/:id/edit", content.PageEdit)        // 编辑页面
+		manageAdmin.POST("/page/:id/edit", content.PageUpdate)     // 更新页面
+		manageAdmin.POST("/page/:id/publish", content.PagePublish) // 发布页面
+		manageAdmin.POST("/page/:id/delete", content.PageDelete)   // 删除页面
 
 		// 订阅者管理
-		admin.GET("/subscriber", subscribe.SubscriberIndex) // 订阅者列表
-		admin.POST("/subscriber", subscribe.SubscriberPost) // 提交订阅者
-		admin.POST("/unsubscribe", subscribe.UnSubscribe)   // 取消订阅
+		manageAdmin.GET("/subscriber", subscribe.SubscriberIndex) // 订阅者列表
+		manageAdmin.POST("/unsubscribe", subscribe.UnSubscribe)   // 取消订阅
 
 		// 友情链接管理
-		admin.GET("/link", link.LinkIndex)              // 友情链接列表
-		admin.POST("/new_link", link.LinkCreate)        // 创建友情链接
-		admin.POST("/link/:id/edit", link.LinkUpdate)   // 更新友情链接
-		admin.POST("/link/:id/delete", link.LinkDelete) // 删除友情链接
+		manageAdmin.GET("/link", link.LinkIndex)              // 友情链接列表
+		manageAdmin.POST("/new_link", link.LinkCreate)        // 创建友情链接
+		manageAdmin.POST("/link/:id/edit", link.LinkUpdate)   // 更新友情链接
+		manageAdmin.POST("/link/:id/delete", link.LinkDelete) // 删除友情链接
 
 		// 评论管理
-		admin.POST("/comment/:id", comment.CommentRead) // 标记评论为已读
-		admin.POST("/read_all", comment.CommentReadAll) // 标记所有评论为已读
+		manageAdmin.GET("/comment", comment.CommentIndex)                  // 评论审核列表
+		manageAdmin.POST("/comment/:id", comment.CommentRead)              // 标记评论为已读
+		manageAdmin.POST("/comment/:id/moderate", comment.CommentModerate) // 审核单条评论
+		manageAdmin.POST("/comment/batch", comment.CommentModerateBatch)   // 批量审核评论
+		manageAdmin.POST("/read_all", comment.CommentReadAll)              // 标记所有评论为已读
+
+		// Markdown 导入导出
+		manageAdmin.GET("/archive", backup.ArchiveIndex) // Markdown 导入导出页面
+		manageAdmin.GET("/export", backup.ExportGet)     // 导出 Markdown 归档
+		manageAdmin.POST("/import", backup.ImportPost)   // 导入 Markdown 归档
+
+		// 搜索统计
+		manageAdmin.GET("/search-stats", content.SearchStatIndex) // 热门搜索和无结果搜索
+	}
+
+	// 个人资料：所有后台用户可用，访问令牌需要 admin 权限
+	profileAdmin := admin.Group("", middleware.TokenScopeRequired(models.TokenScopeAdmin, models.TokenScopeAdmin))
+	{
+		profileAdmin.GET("/profile", user.ProfileGet)                           // 个人资料页面
+		profileAdmin.POST("/profile", user.ProfileUpdate)                       // 更新个人资料
+		profileAdmin.POST("/profile/email/bind", user.BindEmail)                // 绑定邮箱
+		profileAdmin.POST("/profile/email/unbind", user.UnbindEmail)            // 解绑邮箱
+		profileAdmin.POST("/profile/email/verify", user.EmailVerifySend)        // 重新发送验证邮件
+		profileAdmin.POST("/profile/oauth/:provider/unbind", user.OAuthUnbind)  // 解绑第三方账号
+		profileAdmin.POST("/profile/tokens", user.TokenCreate)                  // 创建访问令牌
+		profileAdmin.POST("/profile/tokens/:id/revoke", user.TokenRevoke)       // 撤销访问令牌
+		profileAdmin.POST("/profile/sessions/:id/revoke", user.SessionRevoke)   // 注销某个登录会话
+		profileAdmin.POST("/profile/sessions/revoke", user.SessionRevokeAll)    // 注销全部登录会话
+		profileAdmin.POST("/profile/2fa/setup", user.TwoFactorSetup)            // 生成两步验证二维码
+		profileAdmin.POST("/profile/2fa/enable", user.TwoFactorEnable)          // 启用两步验证
+		profileAdmin.POST("/profile/2fa/disable", user.TwoFactorDisable)        // 关闭两步验证
+		profileAdmin.POST("/profile/2fa/recovery", user.TwoFactorRecoveryCodes) // 重新生成恢复码
+	}
+
+	// 系统管理：仅管理员，访问令牌需要 admin 权限
+	systemAdmin := profileAdmin.Group("", middleware.PermissionRequired(models.PermManageSystem))
+	{
+		// 用户管理
+		systemAdmin.GET("/user", user.UserIndex)                // 用户列表
+		systemAdmin.POST("/user/:id/lock", user.UserLock)       // 锁定/解锁用户
+		systemAdmin.POST("/user/:id/role", user.UserRoleUpdate) // 修改用户角色
+		systemAdmin.POST("/user/:id/unlock", user.UserUnlock)   // 解除登录临时锁定
+
+		// 群发邮件
+		systemAdmin.POST("/subscriber", subscribe.SubscriberPost) // 向订阅者发送邮件
 
 		// 备份与恢复
-		admin.GET("/backup", backup.BackupPost)    // 备份数据
-		admin.POST("/restore", backup.RestorePost) // 恢复数据
+		systemAdmin.GET("/backup", backup.BackupPost)    // 备份数据
+		systemAdmin.POST("/restore", backup.RestorePost) // 恢复数据
 
 		// 邮件管理
-		admin.POST("/new_mail", email.SendMail)           // 发送单封邮件
-		admin.POST("/new_batchmail", email.SendBatchMail) // 发送批量邮件
+		systemAdmin.POST("/new_mail", email.SendMail)           // 发送单封邮件
+		systemAdmin.POST("/new_batchmail", email.SendBatchMail) // 发送批量邮件
 
 		// 邮件队列管理
-		admin.GET("/email-queue", queue.EmailQueueManage)         // 邮件队列管理页面
-		admin.GET("/email-queue/status", queue.EmailQueueStatus)  // 邮件队列状态
-		admin.POST("/email-queue/retry", queue.RetryFailedEmails) // 重试失败邮件
-		admin.POST("/email-queue/clear", queue.ClearFailedEmails) // 清除失败邮件
+		systemAdmin.GET("/email-queue", queue.EmailQueueManage)         // 邮件队列管理页面
+		systemAdmin.GET("/email-queue/status", queue.EmailQueueStatus)  // 邮件队列状态
+		systemAdmin.POST("/email-queue/retry", queue.RetryFailedEmails) // 重试失败邮件
+		systemAdmin.POST("/email-queue/clear", queue.ClearFailedEmails) // 清除失败邮件
 
-		// 上传管理
-		admin.POST("/upload", upload.Upload) // 上传文件
+		// 内容安全策略
+		systemAdmin.GET("/csp-reports", security.CSPReportIndex)        // CSP 违规报告
+		systemAdmin.POST("/csp-reports/clear", security.CSPReportClear) // 清空 CSP 违规报告
+
+		// 搜索同步
+		systemAdmin.GET("/search-sync", content.SearchSyncIndex)            // 搜索同步状态
+		systemAdmin.POST("/search-sync/retry", content.SearchSyncRetry)     // 重试死信任务
+		systemAdmin.POST("/search-sync/discard", content.SearchSyncDiscard) // 丢弃死信任务
+		systemAdmin.POST("/search-sync/check", content.SearchSyncCheck)     // 一致性检查
+
+		// 通知
+		systemAdmin.GET("/notification", notification.NotificationIndex)             // 通知渠道与站内通知
+		systemAdmin.POST("/notification/:id/read", notification.NotificationRead)    // 标记通知已读
+		systemAdmin.POST("/notification/read_all", notification.NotificationReadAll) // 标记全部通知已读
+		systemAdmin.POST("/notification/test", notification.NotificationTestSend)    // 发送测试通知
+
+		// webhook
+		systemAdmin.GET("/webhook", webhook.WebhookIndex)                                    // webhook 列表
+		systemAdmin.POST("/webhook", webhook.WebhookCreate)                                  // 新建 webhook
+		systemAdmin.GET("/webhook/:id", webhook.WebhookGet)                                  // webhook 详情与投递记录
+		systemAdmin.POST("/webhook/:id/edit", webhook.WebhookUpdate)                         // 修改 webhook
+		systemAdmin.POST("/webhook/:id/delete", webhook.WebhookDelete)                       // 删除 webhook
+		systemAdmin.POST("/webhook/:id/ping", webhook.WebhookPing)                           // 发送测试请求
+		systemAdmin.POST("/webhook/:id/deliveries/:did/redeliver", webhook.WebhookRedeliver) // 重新投递
 	}
 
 	return router
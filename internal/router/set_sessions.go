@@ -7,8 +7,10 @@ import (
 	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-contrib/sessions/redis"
 	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
 )
 
 func setSessions(router *gin.Engine) {
@@ -60,10 +62,10 @@ func setSessions(router *gin.Engine) {
 	// 设置Session选项
 	store.Options(sessions.Options{
 		HttpOnly: true,
-		MaxAge:   7 * 86400, // 7天有效期
+		MaxAge:   int(models.UserSessionMaxAge.Seconds()), // 与服务端会话有效期一致
 		Path:     "/",
 		Secure:   false, // 生产环境建议设为true (需要HTTPS)
 	})
 
-	router.Use(sessions.Sessions("gin-session", store))
+	router.Use(sessions.Sessions(common.SessionCookieName, store))
 }
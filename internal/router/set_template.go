@@ -5,23 +5,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/middleware"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/oauth"
 )
 
 func setTemplate(engine *gin.Engine) {
-	funcMap := template.FuncMap{
-		"dateFormat": common.DateFormat,
-		"substring":  common.Substring,
-		"isOdd":      common.IsOdd,
-		"isEven":     common.IsEven,
-		"truncate":   common.Truncate,
-		"length":     common.Len,
-		"add":        common.Add,
-		"sub":        common.Sub,
-		"minus":      common.Minus,
-		"multiply":   common.Multiply,
-		"seq":        common.Seq,
-		"listtag":    common.ListTag,
-	}
-	engine.SetFuncMap(funcMap)
+	engine.SetFuncMap(FuncMap())
 	engine.LoadHTMLGlob(common.GetCurrentDirectory() + "/front/views/**/*.html")
+	engine.HTMLRender = middleware.TemplateRender(engine.HTMLRender)
+}
+
+// FuncMap 模板函数，静态站点生成时复用
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"dateFormat":      common.DateFormat,
+		"datetimeLocal":   common.DatetimeLocal,
+		"substring":       common.Substring,
+		"isOdd":           common.IsOdd,
+		"isEven":          common.IsEven,
+		"truncate":        common.Truncate,
+		"length":          common.Len,
+		"add":             common.Add,
+		"sub":             common.Sub,
+		"minus":           common.Minus,
+		"multiply":        common.Multiply,
+		"seq":             common.Seq,
+		"listtag":         common.ListTag,
+		"oauthProviders":  oauth.EnabledProviders,
+		"notifications":   models.MustListUnreadNotification,
+		"notificationNum": models.CountUnreadNotification,
+	}
 }
@@ -0,0 +1,400 @@
+package staticsite
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiuivfbc/bmtdblog/internal/api/content"
+	"github.com/xiuivfbc/bmtdblog/internal/common"
+	"github.com/xiuivfbc/bmtdblog/internal/common/log"
+	"github.com/xiuivfbc/bmtdblog/internal/config"
+	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/router"
+)
+
+// Result 构建结果统计
+type Result struct {
+	Rendered int // 重新生成的文件数
+	Skipped  int // 来源未变化而跳过的文件数
+	Removed  int // 内容已删除或下线而清理的文件数
+}
+
+type builder struct {
+	outputDir string
+	tmpl      *template.Template
+	prev      *manifest
+	next      *manifest
+	result    Result
+}
+
+// Build 将博客渲染为纯 HTML 静态站点，输出到 outputDir
+// 默认增量构建，只重新生成来源发生变化的页面；full 为 true 时忽略上次的构建记录
+func Build(outputDir string, full bool) (*Result, error) {
+	cfg := config.GetConfiguration()
+	files, err := filepath.Glob(filepath.Join(common.GetCurrentDirectory(), "front", "views", "*", "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no templates found")
+	}
+	tmpl, err := template.New("").Funcs(router.FuncMap()).ParseFiles(files...)
+	if err != nil {
+		return nil, err
+	}
+	version, err := templateVersion(files, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		outputDir: outputDir,
+		tmpl:      tmpl,
+		prev:      loadManifest(outputDir),
+		next:      &manifest{Version: version, Files: map[string]string{}},
+	}
+	if full || b.prev.Version != version {
+		b.prev.Files = map[string]string{}
+	}
+
+	steps := []func() error{
+		b.copyStatic,
+		b.buildPosts,
+		b.buildPages,
+		b.buildIndex,
+		b.buildTags,
+		b.buildArchives,
+		b.buildFeeds,
+	}
+	for _, step := range steps {
+		if err = step(); err != nil {
+			return &b.result, err
+		}
+	}
+	b.removeStale()
+	if err = b.next.save(outputDir); err != nil {
+		return &b.result, err
+	}
+	log.Info("static site built", "output", outputDir, "rendered", b.result.Rendered, "skipped", b.result.Skipped, "removed", b.result.Removed)
+	return &b.result, nil
+}
+
+// copyStatic 复制 static 目录下的资源文件，按大小和修改时间判断是否需要复制
+func (b *builder) copyStatic() error {
+	publicDir := filepath.Join(common.GetCurrentDirectory(), config.GetConfiguration().PublicDir)
+	return filepath.Walk(publicDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(publicDir, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// 站点地图在构建时重新生成
+			if rel == "sitemap" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		sig := fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+		targets := []string{filepath.ToSlash(filepath.Join("static", rel))}
+		if rel == "favicon.ico" {
+			targets = append(targets, "favicon.ico")
+		}
+		for _, target := range targets {
+			if b.fresh(target, sig) {
+				continue
+			}
+			if err = copyFile(path, filepath.Join(b.outputDir, target)); err != nil {
+				return err
+			}
+			b.result.Rendered++
+		}
+		return nil
+	})
+}
+
+// buildPosts 生成所有已发布博文的详情页
+func (b *builder) buildPosts() error {
+	posts, err := models.ListPublishedPost("", 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		tags, _ := models.ListTagByPostId(post.ID)
+		tagIds := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			tagIds = append(tagIds, strconv.Itoa(int(tag.ID)))
+		}
+		// 相关博文修改、下线或删除后，链接到它们的页面也需要重新生成
+		relatedPosts := models.MustListRelatedPosts(post)
+		related := make([]string, 0, len(relatedPosts))
+		for _, relatedPost := range relatedPosts {
+			related = append(related, fmt.Sprintf("%d:%s", relatedPost.ID, timeSignature(relatedPost.UpdatedAt)))
+		}
+		// 博文内容、标签、已审核评论或相关博文变化时重新生成
+		sig := fmt.Sprintf("%s|%d|%s|%s", timeSignature(post.UpdatedAt), models.CountCommentByPostID(post.ID),
+			strings.Join(tagIds, ","), strings.Join(related, ","))
+		rel := urlToFile(post.URL())
+		if b.fresh(rel, sig) {
+			continue
+		}
+		full, err := models.GetPostById(post.ID)
+		if err != nil {
+			return err
+		}
+		models.LoadPostRelations(full)
+		data, err := b.execute("post/display.html", gin.H{
+			"post":         full,
+			"relatedPosts": relatedPosts,
+			"cfg":          config.GetConfiguration(),
+			"static":       true,
+		})
+		if err != nil {
+			return fmt.Errorf("render post %d: %w", post.ID, err)
+		}
+		if err = b.write(rel, sig, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildPages 生成所有已发布单页
+func (b *builder) buildPages() error {
+	pages, err := models.ListPublishedPage()
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		sig := timeSignature(page.UpdatedAt)
+		rel := urlToFile(page.URL())
+		if b.fresh(rel, sig) {
+			continue
+		}
+		data, err := b.execute("page/display.html", gin.H{
+			"page":   page,
+			"cfg":    config.GetConfiguration(),
+			"static": true,
+		})
+		if err != nil {
+			return fmt.Errorf("render page %d: %w", page.ID, err)
+		}
+		if err = b.write(rel, sig, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildIndex 生成首页及分页
+func (b *builder) buildIndex() error {
+	total, err := models.CountPostByTag("")
+	if err != nil {
+		return err
+	}
+	return b.buildList("/", "/index/", total, func(pageIndex, pageSize int) ([]*models.Post, error) {
+		return models.ListPublishedPost("", pageIndex, pageSize)
+	})
+}
+
+// buildTags 生成每个标签的博文列表
+func (b *builder) buildTags() error {
+	for _, tag := range models.MustListTag() {
+		tagId := strconv.Itoa(int(tag.ID))
+		total, err := models.CountPostByTag(tagId)
+		if err != nil {
+			return err
+		}
+		base := "/tag/" + tagId + "/"
+		err = b.buildList(base, base+"page/", total, func(pageIndex, pageSize int) ([]*models.Post, error) {
+			return models.ListPublishedPost(tagId, pageIndex, pageSize)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildArchives 生成每个月份的归档列表
+func (b *builder) buildArchives() error {
+	for _, archive := range models.MustListPostArchives() {
+		year, month := strconv.Itoa(archive.Year), strconv.Itoa(archive.Month)
+		base := "/archives/" + year + "/" + month + "/"
+		err := b.buildList(base, base+"page/", archive.Total, func(pageIndex, pageSize int) ([]*models.Post, error) {
+			return models.ListPostByArchive(year, month, pageIndex, pageSize)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildList 生成分页列表，第一页位于 base，其余位于 pagePath/N/
+// 列表页依赖侧边栏等汇总数据，总是重新渲染，但只在内容变化时写入
+func (b *builder) buildList(base, pagePath string, total int, list func(pageIndex, pageSize int) ([]*models.Post, error)) error {
+	pageSize := config.GetConfiguration().PageSize
+	totalPage := int(math.Ceil(float64(total) / float64(pageSize)))
+	if totalPage < 1 {
+		totalPage = 1
+	}
+	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
+		posts, err := list(pageIndex, pageSize)
+		if err != nil {
+			return err
+		}
+		values := content.ListPageData(posts, pageIndex, total)
+		values["path"] = base
+		values["pagePath"] = pagePath
+		values["static"] = true
+		data, err := b.execute("index/index.html", values)
+		if err != nil {
+			return fmt.Errorf("render %s: %w", base, err)
+		}
+		rel := urlToFile(base)
+		if pageIndex > 1 {
+			rel = urlToFile(pagePath + strconv.Itoa(pageIndex))
+		}
+		if err = b.write(rel, hashBytes(data), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildFeeds 生成 RSS、站点地图和 404 页面
+func (b *builder) buildFeeds() error {
+	rss, err := content.BuildRSS()
+	if err != nil {
+		return err
+	}
+	if err = b.write("rss.xml", hashBytes([]byte(rss)), []byte(rss)); err != nil {
+		return err
+	}
+
+	notFound, err := b.execute("errors/error.html", gin.H{
+		"message": "Sorry,I lost myself!",
+		"cfg":     config.GetConfiguration(),
+		"static":  true,
+	})
+	if err != nil {
+		return err
+	}
+	if err = b.write("404.html", hashBytes(notFound), notFound); err != nil {
+		return err
+	}
+	return common.WriteXMLSitemap(filepath.Join(b.outputDir, "static", "sitemap"))
+}
+
+func (b *builder) execute(name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fresh 判断输出文件是否与上次构建时来源一致，一致则保留并计入本次清单
+func (b *builder) fresh(rel, sig string) bool {
+	if b.prev.Files[rel] != sig {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(b.outputDir, rel)); err != nil {
+		return false
+	}
+	b.next.Files[rel] = sig
+	b.result.Skipped++
+	return true
+}
+
+func (b *builder) write(rel, sig string, data []byte) error {
+	if b.fresh(rel, sig) {
+		return nil
+	}
+	target := filepath.Join(b.outputDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return err
+	}
+	b.next.Files[rel] = sig
+	b.result.Rendered++
+	return nil
+}
+
+// removeStale 删除上次构建生成但本次已不存在的文件，例如已删除或撤回发布的博文
+func (b *builder) removeStale() {
+	for rel := range loadManifest(b.outputDir).Files {
+		if _, ok := b.next.Files[rel]; ok {
+			continue
+		}
+		target := filepath.Join(b.outputDir, rel)
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			log.Warn("remove stale file failed", "file", target, "err", err)
+			continue
+		}
+		b.result.Removed++
+		// 清理随之变空的目录
+		for dir := filepath.Dir(target); dir != filepath.Clean(b.outputDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+}
+
+// urlToFile 将站内地址转换为输出目录下的 index.html 路径
+func urlToFile(path string) string {
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "index.html"
+	}
+	return path + "/index.html"
+}
+
+func timeSignature(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,64 @@
+package staticsite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// manifestName 构建清单文件名，保存在输出目录下
+const manifestName = ".bmtdblog-build.json"
+
+// manifest 记录上一次构建的模板版本和每个输出文件的来源签名，用于增量构建
+type manifest struct {
+	Version string            `json:"version"` // 模板与配置的摘要，变化时需要全量重建
+	Files   map[string]string `json:"files"`   // 相对输出路径 -> 来源签名
+}
+
+func loadManifest(outputDir string) *manifest {
+	m := &manifest{Files: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(outputDir, manifestName))
+	if err != nil {
+		return m
+	}
+	if err = json.Unmarshal(data, m); err != nil || m.Files == nil {
+		return &manifest{Files: map[string]string{}}
+	}
+	return m
+}
+
+func (m *manifest) save(outputDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, manifestName), data, 0644)
+}
+
+// templateVersion 计算模板文件和站点配置的摘要，任一变化都会让所有页面失效
+func templateVersion(files []string, cfg interface{}) (string, error) {
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(file))
+		h.Write(data)
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
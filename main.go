@@ -16,6 +16,7 @@
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @description 个人访问令牌，在后台个人资料页创建，格式为 "Bearer bmt_..."
 package main
 
 import (
@@ -31,13 +32,16 @@ import (
 	"github.com/claudiu/gocron"
 	"github.com/gin-gonic/gin"
 	"github.com/xiuivfbc/bmtdblog/internal/api/backup"
+	"github.com/xiuivfbc/bmtdblog/internal/api/content"
 	"github.com/xiuivfbc/bmtdblog/internal/api/dao"
 	"github.com/xiuivfbc/bmtdblog/internal/common"
 	"github.com/xiuivfbc/bmtdblog/internal/common/log"
 	"github.com/xiuivfbc/bmtdblog/internal/config"
 	"github.com/xiuivfbc/bmtdblog/internal/models"
+	"github.com/xiuivfbc/bmtdblog/internal/notify"
 	r "github.com/xiuivfbc/bmtdblog/internal/router"
 	"github.com/xiuivfbc/bmtdblog/internal/server"
+	"github.com/xiuivfbc/bmtdblog/internal/staticsite"
 	"gorm.io/gorm"
 )
 
@@ -51,8 +55,19 @@ var (
 )
 
 func main() {
+	// bmtdblog build -o ./dist：生成静态站点
+	buildSite := len(os.Args) > 1 && os.Args[1] == "build"
+	if buildSite {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	//configuration
 	configFilePath := flag.String("C", "configs/conf_mine.toml", "config file path")
+	exportPath := flag.String("export", "", "export all posts and pages as a markdown zip to the given file, then exit")
+	importPath := flag.String("import", "", "import posts and pages from a markdown zip or a Hexo/Hugo/Jekyll posts directory, then exit")
+	outputDir := flag.String("o", "./dist", "output directory of the build command")
+	fullBuild := flag.Bool("full", false, "build command re-renders every page instead of only changed ones")
+	reindex := flag.Bool("reindex", false, "rebuild the search index of the configured engine, then exit")
 	flag.Parse()
 	if err := config.LoadConfiguration(*configFilePath); err != nil {
 		fmt.Printf("err parsing config log file: %v\n", err)
@@ -83,6 +98,9 @@ func main() {
 		}
 		os.Exit(1)
 	}
+	if err = models.BackfillSlugs(dao.GetMysqlDB()); err != nil {
+		log.Error("err backfill slugs", "err", err)
+	}
 
 	// Redis缓存初始化
 	if err := dao.InitRedis(config.GetConfiguration().Redis); err != nil {
@@ -90,17 +108,21 @@ func main() {
 		// Redis失败不退出程序，允许降级运行
 	}
 
-	// ElasticSearch搜索初始化
-	if config.GetConfiguration().Elasticsearch.Enabled {
-		if err := dao.InitElasticsearch(); err != nil {
-			log.Error("ElasticSearch initialization failed", "err", err)
-			// ES失败不退出程序，允许降级运行
-		} else {
-			// 启动ES批量任务队列
-			models.InitESTaskQueue()
-		}
-	} else {
-		log.Info("ElasticSearch功能已禁用")
+	// 搜索引擎初始化，失败时降级到数据库搜索
+	models.InitSearchEngine(*reindex)
+
+	// 命令行导入导出，执行完成后直接退出
+	if *exportPath != "" || *importPath != "" {
+		runArchiveCommand(*exportPath, *importPath)
+		return
+	}
+	if buildSite {
+		runBuildCommand(*outputDir, *fullBuild)
+		return
+	}
+	if *reindex {
+		runReindexCommand()
+		return
 	}
 
 	// 邮件队列初始化
@@ -113,6 +135,10 @@ func main() {
 		dao.SetEmailSender(common.SendMail)
 	}
 
+	// 通知渠道初始化，需在邮件队列之后，以便接收邮件发送失败事件
+	notify.Init()
+	notify.StartWebhookWorker()
+
 	router = r.DefineRouter()
 
 	// 启动定时任务
@@ -139,10 +165,58 @@ func main() {
 	defer cleanupResources()
 }
 
+// runArchiveCommand 执行 Markdown 归档的导入导出
+func runArchiveCommand(exportPath, importPath string) {
+	defer models.StopSearchSyncWorker()
+	if exportPath != "" {
+		if err := backup.ExportMarkdownFile(exportPath); err != nil {
+			fmt.Println("export failed:", err)
+			os.Exit(1)
+		}
+		fmt.Println("exported to", exportPath)
+	}
+	if importPath != "" {
+		result, err := backup.ImportMarkdownPath(importPath)
+		if err != nil {
+			fmt.Println("import failed:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("imported %d posts, %d pages, skipped %d, %d errors\n", result.Posts, result.Pages, result.Skipped, len(result.Errors))
+		for _, message := range result.Errors {
+			fmt.Println("  ", message)
+		}
+	}
+}
+
+// runBuildCommand 生成静态站点
+func runBuildCommand(outputDir string, full bool) {
+	defer models.StopSearchSyncWorker()
+	result, err := staticsite.Build(outputDir, full)
+	if err != nil {
+		fmt.Println("build failed:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("built %s: %d rendered, %d unchanged, %d removed\n", outputDir, result.Rendered, result.Skipped, result.Removed)
+}
+
+// runReindexCommand 重建当前搜索引擎的索引，ES索引重建完成后才切换别名
+func runReindexCommand() {
+	defer models.StopSearchSyncWorker()
+	if err := models.ForceFullReindex(); err != nil {
+		fmt.Println("reindex failed:", err)
+		os.Exit(1)
+	}
+	fmt.Println("reindex finished")
+}
+
 // setupPeriodicTasks 设置定时任务
 func setupPeriodicTasks() {
 	gocron.Every(1).Day().Do(common.CreateXMLSitemap)
-	gocron.Every(7).Days().Do(backup.Backup)
+	gocron.Every(7).Days().Do(backup.ScheduledBackup)
+	gocron.Every(1).Minute().Do(content.PublishScheduled)
+	gocron.Every(1).Hour().Do(content.AggregateSearchStats)
+	gocron.Every(6).Hours().Do(content.CheckSearchConsistency)
+	gocron.Every(1).Day().Do(notify.CleanWebhookDeliveries)
 	gocron.Start()
 }
 
@@ -153,8 +227,11 @@ func setupGracefulShutdown() {
 		<-c
 		fmt.Println("Cleaning...")
 
-		// 停止ES任务队列
-		models.StopESTaskQueue()
+		// 停止搜索同步任务
+		models.StopSearchSyncWorker()
+
+		// 停止 webhook 投递
+		notify.StopWebhookWorker()
 
 		// 停止邮件队列
 		dao.StopEmailQueue()